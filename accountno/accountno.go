@@ -0,0 +1,302 @@
+package accountno
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"task-golang-db/model"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidFormat     = errors.New("invalid account number or account id")
+	ErrInvalidCheckDigit = errors.New("invalid account number check digit")
+	ErrExhausted         = errors.New("could not generate a unique account number")
+)
+
+// Skema check digit nomor rekening
+const (
+	SchemeLuhn  = "luhn"
+	SchemeMod97 = "mod97"
+)
+
+// bodyDigits adalah jumlah digit acak di antara prefix dan check digit. Digit acak
+// (bukan urutan account_id) supaya nomor rekening tidak membocorkan jumlah nasabah.
+const bodyDigits = 10
+
+// Config mengatur format nomor rekening. Mengganti Scheme setelah nomor diterbitkan
+// membuat nomor lama gagal validasi, jadi tentukan sekali di awal.
+type Config struct {
+	Prefix string
+	Scheme string
+}
+
+// ConfigFromEnv membaca ACCOUNT_NUMBER_PREFIX (default "88") dan ACCOUNT_NUMBER_CHECK
+// (luhn atau mod97, default luhn)
+func ConfigFromEnv() Config {
+	config := Config{
+		Prefix: os.Getenv("ACCOUNT_NUMBER_PREFIX"),
+		Scheme: os.Getenv("ACCOUNT_NUMBER_CHECK"),
+	}
+	if config.Prefix == "" {
+		config.Prefix = "88"
+	}
+	if config.Scheme != SchemeMod97 {
+		config.Scheme = SchemeLuhn
+	}
+	return config
+}
+
+// Length adalah panjang nomor rekening lengkap untuk config
+func (c Config) Length() int {
+	if c.Scheme == SchemeMod97 {
+		return len(c.Prefix) + bodyDigits + 2
+	}
+	return len(c.Prefix) + bodyDigits + 1
+}
+
+// CheckDigits menghitung check digit untuk payload (prefix + body)
+func CheckDigits(scheme, payload string) string {
+	if scheme == SchemeMod97 {
+		// ISO 7064 MOD 97-10: payload*100 + check ≡ 1 (mod 97)
+		return leftPad(strconv.Itoa(98-mod97(payload+"00")), 2)
+	}
+	return strconv.Itoa(luhn(payload))
+}
+
+// luhn menghitung check digit Luhn untuk payload
+func luhn(payload string) int {
+	sum := 0
+	double := true
+	for i := len(payload) - 1; i >= 0; i-- {
+		digit := int(payload[i] - '0')
+		if double {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+	}
+	return (10 - sum%10) % 10
+}
+
+// mod97 menghitung angka desimal panjang modulo 97 tanpa big.Int
+func mod97(digits string) int {
+	remainder := 0
+	for i := 0; i < len(digits); i++ {
+		remainder = (remainder*10 + int(digits[i]-'0')) % 97
+	}
+	return remainder
+}
+
+func leftPad(value string, width int) string {
+	return strings.Repeat("0", width-len(value)) + value
+}
+
+// Normalize membuang spasi dan tanda hubung yang biasa dipakai saat nomor diketik
+func Normalize(number string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(number))
+}
+
+// shaped bernilai true jika value punya panjang dan prefix nomor rekening, tanpa
+// memeriksa check digit
+func shaped(value string) bool {
+	config := ConfigFromEnv()
+	return digitsOnly(value) && len(value) == config.Length() && strings.HasPrefix(value, config.Prefix)
+}
+
+// Validate memeriksa format, prefix, dan check digit tanpa menyentuh database, lalu
+// mengembalikan nomor yang sudah dinormalisasi
+func Validate(number string) (string, error) {
+	config := ConfigFromEnv()
+	number = Normalize(number)
+	if !shaped(number) {
+		return "", ErrInvalidFormat
+	}
+	if config.Scheme == SchemeMod97 {
+		if mod97(number) != 1 {
+			return "", ErrInvalidCheckDigit
+		}
+		return number, nil
+	}
+	payload, check := number[:len(number)-1], number[len(number)-1:]
+	if CheckDigits(SchemeLuhn, payload) != check {
+		return "", ErrInvalidCheckDigit
+	}
+	return number, nil
+}
+
+func digitsOnly(value string) bool {
+	if value == "" {
+		return false
+	}
+	for i := 0; i < len(value); i++ {
+		if value[i] < '0' || value[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Generate membuat nomor rekening acak yang belum dipakai akun lain
+func Generate(db *gorm.DB) (string, error) {
+	config := ConfigFromEnv()
+	limit := new(big.Int).Exp(big.NewInt(10), big.NewInt(bodyDigits), nil)
+	for attempt := 0; attempt < 5; attempt++ {
+		n, err := rand.Int(rand.Reader, limit)
+		if err != nil {
+			return "", err
+		}
+		payload := config.Prefix + leftPad(n.String(), bodyDigits)
+		number := payload + CheckDigits(config.Scheme, payload)
+
+		var used int64
+		if err := db.Unscoped().Model(&model.Account{}).Where("account_number = ?", number).Count(&used).Error; err != nil {
+			return "", err
+		}
+		if used == 0 {
+			return number, nil
+		}
+	}
+	return "", ErrExhausted
+}
+
+// Ref adalah referensi akun dari request: account_id (angka) atau nomor rekening
+// (string). Di JSON boleh berupa number maupun string. Nilai yang berbentuk nomor
+// rekening harus punya check digit valid; selain itu dibaca sebagai account_id.
+type Ref string
+
+func (r *Ref) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*r = ""
+		return nil
+	}
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*r = Ref(strings.TrimSpace(text))
+		return nil
+	}
+	var id json.Number
+	if err := json.Unmarshal(data, &id); err != nil {
+		return ErrInvalidFormat
+	}
+	*r = Ref(id.String())
+	return nil
+}
+
+// Number mengembalikan nomor rekening yang sudah dinormalisasi jika check digit valid
+func (r Ref) Number() (string, bool) {
+	number, err := Validate(string(r))
+	return number, err == nil
+}
+
+// ID membaca referensi sebagai account_id
+func (r Ref) ID() (int64, bool) {
+	id, err := strconv.ParseInt(Normalize(string(r)), 10, 64)
+	return id, err == nil && id > 0
+}
+
+// Check memvalidasi bentuk referensi tanpa lookup. Nilai berbentuk nomor rekening
+// (panjang dan prefix) dengan check digit salah dianggap salah ketik dan ditolak
+// ErrInvalidCheckDigit, tidak dibaca sebagai account_id.
+func (r Ref) Check() error {
+	if number := Normalize(string(r)); shaped(number) {
+		_, err := Validate(number)
+		return err
+	}
+	if _, ok := r.ID(); ok {
+		return nil
+	}
+	return ErrInvalidFormat
+}
+
+// Resolve mengubah referensi menjadi account_id. Referensi divalidasi dengan Check
+// sebelum query apa pun. Nomor rekening valid dicari lebih dulu; jika tidak terdaftar
+// nilai dibaca sebagai account_id dan keberadaan akunnya dicek pemanggil.
+func (r Ref) Resolve(db *gorm.DB) (int64, error) {
+	if err := r.Check(); err != nil {
+		return 0, err
+	}
+	if number, ok := r.Number(); ok {
+		var account model.Account
+		result := db.Select("account_id").Where("account_number = ?", number).Limit(1).Find(&account)
+		if result.Error != nil {
+			return 0, result.Error
+		}
+		if result.RowsAffected > 0 {
+			return account.AccountID, nil
+		}
+	}
+
+	id, ok := r.ID()
+	if !ok {
+		return 0, ErrInvalidFormat
+	}
+	return id, nil
+}
+
+// ResolveNumbers mencari account_id untuk banyak nomor rekening (sudah divalidasi)
+// sekaligus; nomor yang tidak terdaftar tidak muncul di map
+func ResolveNumbers(db *gorm.DB, numbers []string) (map[string]int64, error) {
+	ids := map[string]int64{}
+	if len(numbers) == 0 {
+		return ids, nil
+	}
+	var accounts []model.Account
+	if err := db.Select("account_id", "account_number").Where("account_number IN ?", numbers).Find(&accounts).Error; err != nil {
+		return nil, err
+	}
+	for _, account := range accounts {
+		ids[account.AccountNumber] = account.AccountID
+	}
+	return ids, nil
+}
+
+// Fill mengisi nomor rekening pada model yang menyembunyikan account_id dalam satu
+// query. Akun yang sudah dihapus (soft delete) tetap ditampilkan nomornya.
+func Fill(db *gorm.DB, values ...model.AccountNumbers) error {
+	var refs []model.AccountNumberRef
+	var ids []int64
+	for _, value := range values {
+		for _, ref := range value.AccountNumberRefs() {
+			if ref.AccountID > 0 {
+				refs = append(refs, ref)
+				ids = append(ids, ref.AccountID)
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	var accounts []model.Account
+	if err := db.Session(&gorm.Session{NewDB: true}).Unscoped().Select("account_id", "account_number").Find(&accounts, ids).Error; err != nil {
+		return err
+	}
+	numbers := map[int64]string{}
+	for _, account := range accounts {
+		numbers[account.AccountID] = account.AccountNumber
+	}
+	for _, ref := range refs {
+		*ref.Number = numbers[ref.AccountID]
+	}
+	return nil
+}
+
+// FillAll menjalankan Fill untuk setiap elemen slice
+func FillAll[T any, P interface {
+	*T
+	model.AccountNumbers
+}](db *gorm.DB, items []T) error {
+	values := make([]model.AccountNumbers, len(items))
+	for i := range items {
+		values[i] = P(&items[i])
+	}
+	return Fill(db, values...)
+}
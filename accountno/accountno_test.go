@@ -0,0 +1,85 @@
+package accountno
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckDigits(t *testing.T) {
+	for _, tc := range []struct {
+		scheme  string
+		payload string
+		want    string
+	}{
+		// Contoh Luhn yang umum dipakai (79927398713, nomor kartu uji 4539148803436467)
+		{SchemeLuhn, "7992739871", "3"},
+		{SchemeLuhn, "453914880343646", "7"},
+		{SchemeLuhn, "881234567890", "8"},
+		// ISO 7064 MOD 97-10 seperti check digit IBAN: GB82 WEST 1234 5698 7654 32 dan
+		// DE89 3704 0044 0532 0130 00 (huruf diganti angka, kode negara dipindah ke belakang)
+		{SchemeMod97, "32142829123456987654321611", "82"},
+		{SchemeMod97, "3704004405320130001314", "89"},
+		{SchemeMod97, "881234567890", "57"},
+	} {
+		if got := CheckDigits(tc.scheme, tc.payload); got != tc.want {
+			t.Errorf("CheckDigits(%s, %s) = %s, want %s", tc.scheme, tc.payload, got, tc.want)
+		}
+	}
+
+	if got := mod97("3214282912345698765432161182"); got != 1 {
+		t.Errorf("mod97(IBAN GB82) = %d, want 1", got)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, tc := range []struct {
+		scheme string
+		number string
+		want   error
+	}{
+		{SchemeLuhn, "8812345678908", nil},
+		{SchemeLuhn, "88 1234-5678 908", nil},
+		{SchemeLuhn, "8812345678903", ErrInvalidCheckDigit}, // check digit salah
+		{SchemeLuhn, "8812345768908", ErrInvalidCheckDigit}, // dua digit tertukar
+		{SchemeLuhn, "8812345678918", ErrInvalidCheckDigit}, // satu digit salah ketik
+		{SchemeLuhn, "7712345678908", ErrInvalidFormat},     // prefix lain
+		{SchemeLuhn, "881234567890", ErrInvalidFormat},      // kurang satu digit
+		{SchemeLuhn, "88123456789O8", ErrInvalidFormat},
+		{SchemeMod97, "88123456789057", nil},
+		{SchemeMod97, "88123456789075", ErrInvalidCheckDigit},
+		{SchemeMod97, "88123456788057", ErrInvalidCheckDigit},
+		{SchemeMod97, "8812345678908", ErrInvalidFormat},
+	} {
+		t.Setenv("ACCOUNT_NUMBER_PREFIX", "88")
+		t.Setenv("ACCOUNT_NUMBER_CHECK", tc.scheme)
+		if _, err := Validate(tc.number); !errors.Is(err, tc.want) {
+			t.Errorf("Validate(%s) with %s = %v, want %v", tc.number, tc.scheme, err, tc.want)
+		}
+	}
+}
+
+func TestRefCheck(t *testing.T) {
+	t.Setenv("ACCOUNT_NUMBER_PREFIX", "88")
+	t.Setenv("ACCOUNT_NUMBER_CHECK", SchemeLuhn)
+
+	for _, tc := range []struct {
+		ref  Ref
+		want error
+	}{
+		{"8812345678908", nil},
+		{"42", nil}, // account_id
+		{"8812345678903", ErrInvalidCheckDigit},
+		{"0", ErrInvalidFormat},
+		{"abc", ErrInvalidFormat},
+		{"", ErrInvalidFormat},
+	} {
+		if err := tc.ref.Check(); !errors.Is(err, tc.want) {
+			t.Errorf("Ref(%q).Check() = %v, want %v", tc.ref, err, tc.want)
+		}
+	}
+
+	// Salah ketik ditolak sebelum lookup apa pun; db nil akan panic jika di-query
+	if _, err := Ref("8812345678903").Resolve(nil); !errors.Is(err, ErrInvalidCheckDigit) {
+		t.Errorf("Resolve(typo) = %v, want ErrInvalidCheckDigit", err)
+	}
+}
@@ -0,0 +1,211 @@
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"task-golang-db/model"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Kunci advisory lock supaya penambahan entry ke rantai hash berjalan berurutan
+const chainLockKey = 7290291
+
+// Aksi yang dicatat
+const (
+	ActionCreate   = "create"
+	ActionUpdate   = "update"
+	ActionDelete   = "delete"
+	ActionTopUp    = "topup"
+	ActionTransfer = "transfer"
+)
+
+// Actor adalah pelaku perubahan, diambil dari claims JWT dan request
+type Actor struct {
+	AuthID    int64
+	Username  string
+	RequestID string
+	ClientIP  string
+}
+
+// ActorFromContext membaca pelaku dari context yang sudah melewati AuthMiddleware.
+// Request tanpa token tercatat dengan AuthID 0 dan username kosong.
+func ActorFromContext(c *gin.Context) Actor {
+	return Actor{
+		AuthID:    c.GetInt64("auth_id"),
+		Username:  c.GetString("username"),
+		RequestID: c.GetString("request_id"),
+		ClientIP:  c.ClientIP(),
+	}
+}
+
+// Entry menjelaskan perubahan pada satu entity
+type Entry struct {
+	Action   string
+	Entity   string
+	EntityID interface{}
+	Before   interface{}
+	After    interface{}
+}
+
+// Record menambahkan entry ke audit log dalam tx yang sama dengan perubahannya
+func Record(tx *gorm.DB, actor Actor, entry Entry) error {
+	before, err := snapshot(entry.Before)
+	if err != nil {
+		return err
+	}
+	after, err := snapshot(entry.After)
+	if err != nil {
+		return err
+	}
+
+	// Serialisasi penulisan rantai; lock dilepas otomatis saat tx selesai
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", chainLockKey).Error; err != nil {
+		return err
+	}
+
+	var last model.AuditLog
+	prevHash := ""
+	if err := tx.Order("audit_log_id DESC").Limit(1).Find(&last).Error; err != nil {
+		return err
+	}
+	if last.AuditLogID != 0 {
+		prevHash = last.Hash
+	}
+
+	log := model.AuditLog{
+		ActorAuthID:   actor.AuthID,
+		ActorUsername: actor.Username,
+		Action:        entry.Action,
+		Entity:        entry.Entity,
+		EntityID:      toString(entry.EntityID),
+		Before:        before,
+		After:         after,
+		RequestID:     actor.RequestID,
+		ClientIP:      actor.ClientIP,
+		// Postgres menyimpan timestamp dengan presisi mikrodetik
+		CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+		PrevHash:  prevHash,
+	}
+	log.Hash = Hash(log)
+
+	return tx.Create(&log).Error
+}
+
+// Hash menghitung SHA-256 dari isi entry beserta hash entry sebelumnya
+func Hash(log model.AuditLog) string {
+	fields := []string{
+		log.PrevHash,
+		strconv.FormatInt(log.ActorAuthID, 10),
+		log.ActorUsername,
+		log.Action,
+		log.Entity,
+		log.EntityID,
+		string(log.Before),
+		string(log.After),
+		log.RequestID,
+		log.ClientIP,
+		log.CreatedAt.UTC().Format(time.RFC3339Nano),
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join(fields, "\x1f")))
+	return hex.EncodeToString(sum[:])
+}
+
+// VerifyResult adalah hasil pemeriksaan rantai hash
+type VerifyResult struct {
+	Checked  int64  `json:"checked"`
+	Valid    bool   `json:"valid"`
+	BrokenAt int64  `json:"broken_at,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// Verify menghitung ulang seluruh rantai dan berhenti di entry pertama yang tidak cocok
+func Verify(db *gorm.DB) (VerifyResult, error) {
+	result := VerifyResult{Valid: true}
+	prevHash := ""
+	var lastID int64
+
+	for {
+		var logs []model.AuditLog
+		if err := db.Where("audit_log_id > ?", lastID).
+			Order("audit_log_id").
+			Limit(1000).
+			Find(&logs).Error; err != nil {
+			return result, err
+		}
+		if len(logs) == 0 {
+			return result, nil
+		}
+
+		for _, log := range logs {
+			result.Checked++
+			lastID = log.AuditLogID
+
+			if log.PrevHash != prevHash {
+				result.Valid = false
+				result.BrokenAt = log.AuditLogID
+				result.Reason = "prev_hash does not match previous entry"
+				return result, nil
+			}
+			if Hash(log) != log.Hash {
+				result.Valid = false
+				result.BrokenAt = log.AuditLogID
+				result.Reason = "hash does not match entry content"
+				return result, nil
+			}
+			prevHash = log.Hash
+		}
+	}
+}
+
+// schemas menyimpan hasil parse struct model untuk snapshot
+var schemas sync.Map
+
+// snapshot menyimpan struct model per kolom database, bukan per tag JSON, supaya
+// account_id yang disembunyikan dari response tetap tercatat. Field bertag audit:"-"
+// (rahasia) tidak dicatat.
+func snapshot(v interface{}) (json.RawMessage, error) {
+	if v == nil {
+		return json.RawMessage("null"), nil
+	}
+	value := reflect.Indirect(reflect.ValueOf(v))
+	if value.Kind() != reflect.Struct {
+		return json.Marshal(v)
+	}
+	s, err := schema.Parse(v, &schemas, schema.NamingStrategy{})
+	if err != nil {
+		return nil, err
+	}
+	columns := map[string]interface{}{}
+	for _, field := range s.Fields {
+		if field.DBName == "" || field.Tag.Get("audit") == "-" {
+			continue
+		}
+		columns[field.DBName] = field.ReflectValueOf(context.Background(), value).Interface()
+	}
+	return json.Marshal(columns)
+}
+
+func toString(v interface{}) string {
+	switch id := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return id
+	case int64:
+		return strconv.FormatInt(id, 10)
+	default:
+		b, _ := json.Marshal(id)
+		return strings.Trim(string(b), `"`)
+	}
+}
@@ -0,0 +1,41 @@
+package audit
+
+import (
+	"encoding/json"
+	"task-golang-db/model"
+	"testing"
+)
+
+func TestSnapshotUsesColumns(t *testing.T) {
+	raw, err := snapshot(model.Transaction{TransactionID: 7, FromAccountId: 1, ToAccountId: 2, ToAccountNumber: "8812345678903"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var columns map[string]interface{}
+	if err := json.Unmarshal(raw, &columns); err != nil {
+		t.Fatal(err)
+	}
+	// account_id tersembunyi di JSON tetapi tetap tercatat di audit
+	if columns["from_account_id"] != float64(1) || columns["to_account_id"] != float64(2) {
+		t.Errorf("snapshot = %s, want from/to account_id", raw)
+	}
+	if _, ok := columns["to_account_number"]; ok {
+		t.Errorf("snapshot = %s, want no non-column fields", raw)
+	}
+
+	raw, err = snapshot(&model.WebhookSubscription{Partner: "acme", Secret: "s3cret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	columns = nil
+	if err := json.Unmarshal(raw, &columns); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := columns["secret"]; ok || columns["partner"] != "acme" {
+		t.Errorf("snapshot = %s, want partner without secret", raw)
+	}
+
+	if raw, err := snapshot(map[string]interface{}{"account_id": 1}); err != nil || string(raw) != `{"account_id":1}` {
+		t.Errorf("snapshot(map) = %s, %v", raw, err)
+	}
+}
@@ -0,0 +1,315 @@
+package batch
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"task-golang-db/accountno"
+	"task-golang-db/audit"
+	"task-golang-db/fee"
+	"task-golang-db/ledger"
+	"task-golang-db/limit"
+	"task-golang-db/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MaxItems adalah jumlah baris maksimal dalam satu batch
+const MaxItems = 1000
+
+var (
+	ErrEmpty          = errors.New("batch has no rows")
+	ErrTooManyItems   = fmt.Errorf("batch can contain at most %d rows", MaxItems)
+	ErrInvalidMode    = errors.New("mode must be partial or atomic")
+	ErrMissingColumns = errors.New("csv header must contain to_account_id and amount")
+)
+
+// Item adalah satu baris input batch. ToAccount boleh berisi account_id atau nomor
+// rekening; ToAccountID diisi oleh Validate.
+type Item struct {
+	ToAccount   accountno.Ref `json:"to_account_id"`
+	ToAccountID int64         `json:"-"`
+	Amount      int64         `json:"amount"`
+	Description string        `json:"description"`
+}
+
+// RowError adalah kesalahan validasi pada satu baris (RowNumber mulai dari 1)
+type RowError struct {
+	RowNumber int    `json:"row_number"`
+	Error     string `json:"error"`
+}
+
+// ValidationError berisi semua kesalahan yang ditemukan saat validasi awal
+type ValidationError struct {
+	Rows  []RowError `json:"rows,omitempty"`
+	Batch string     `json:"batch,omitempty"`
+}
+
+func (e ValidationError) Error() string {
+	if e.Batch != "" {
+		return e.Batch
+	}
+	return fmt.Sprintf("batch has %d invalid rows", len(e.Rows))
+}
+
+// ParseCSV membaca CSV dengan header to_account_id, amount, dan description (opsional)
+func ParseCSV(r io.Reader) ([]Item, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, ErrEmpty
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	toIdx, okTo := columns["to_account_id"]
+	amountIdx, okAmount := columns["amount"]
+	descIdx, okDesc := columns["description"]
+	if !okTo || !okAmount {
+		return nil, ErrMissingColumns
+	}
+
+	var items []Item
+	var rowErrors []RowError
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		field := func(i int) string {
+			if i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		var item Item
+		item.ToAccount = accountno.Ref(field(toIdx))
+		if err := item.ToAccount.Check(); err != nil {
+			rowErrors = append(rowErrors, RowError{RowNumber: row, Error: "invalid to_account_id: " + err.Error()})
+		}
+		item.Amount, err = strconv.ParseInt(field(amountIdx), 10, 64)
+		if err != nil {
+			rowErrors = append(rowErrors, RowError{RowNumber: row, Error: "invalid amount"})
+		}
+		if okDesc {
+			item.Description = field(descIdx)
+		}
+		items = append(items, item)
+	}
+
+	if len(rowErrors) > 0 {
+		return nil, ValidationError{Rows: rowErrors}
+	}
+	return items, nil
+}
+
+// Validate memeriksa semua baris, total terhadap saldo available, dan limit transfer pengirim
+func Validate(db *gorm.DB, sender model.Account, items []Item) error {
+	if len(items) == 0 {
+		return ErrEmpty
+	}
+	if len(items) > MaxItems {
+		return ErrTooManyItems
+	}
+	if err := ledger.CheckMoneyAllowed(sender); err != nil {
+		return err
+	}
+
+	rowErrors, err := resolve(db, items)
+	if err != nil {
+		return err
+	}
+	ids := make([]int64, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ToAccountID)
+	}
+	var receivers []model.Account
+	if err := db.Find(&receivers, ids).Error; err != nil {
+		return err
+	}
+	byID := map[int64]model.Account{}
+	for _, receiver := range receivers {
+		byID[receiver.AccountID] = receiver
+	}
+
+	var total int64
+	amounts := make([]int64, 0, len(items))
+	for i, item := range items {
+		row := i + 1
+		receiver, ok := byID[item.ToAccountID]
+		switch {
+		case item.ToAccountID == 0:
+			// sudah dilaporkan oleh resolve
+		case item.Amount <= 0:
+			rowErrors = append(rowErrors, RowError{RowNumber: row, Error: ledger.ErrInvalidAmount.Error()})
+		case item.ToAccountID == sender.AccountID:
+			rowErrors = append(rowErrors, RowError{RowNumber: row, Error: ledger.ErrSameAccount.Error()})
+		case !ok:
+			rowErrors = append(rowErrors, RowError{RowNumber: row, Error: ledger.ErrReceiverNotFound.Error()})
+		case receiver.Currency != sender.Currency:
+			rowErrors = append(rowErrors, RowError{RowNumber: row, Error: ledger.ErrCurrencyMismatch.Error()})
+		case !receiver.CanMoveMoney():
+			rowErrors = append(rowErrors, RowError{RowNumber: row, Error: ledger.AccountBlockedError{AccountID: receiver.AccountID, Status: receiver.Status}.Error()})
+		}
+		total += item.Amount
+		amounts = append(amounts, item.Amount)
+	}
+	if len(rowErrors) > 0 {
+		sort.SliceStable(rowErrors, func(i, j int) bool { return rowErrors[i].RowNumber < rowErrors[j].RowNumber })
+		return ValidationError{Rows: rowErrors}
+	}
+
+	// Biaya tiap baris ikut dihitung supaya batch tidak lolos validasi lalu gagal di tengah
+	quotes, err := fee.ComputeMany(db, sender, model.OperationTransfer, amounts)
+	if err != nil {
+		return err
+	}
+	for _, quote := range quotes {
+		total += quote.Fee
+	}
+
+	if sender.Available() < float64(total) {
+		return ValidationError{Batch: fmt.Sprintf("total %d (including fees) exceeds available balance", total)}
+	}
+
+	limits, err := limit.Effective(db, sender, model.OperationTransfer)
+	if err != nil {
+		return err
+	}
+	usage, err := limit.CurrentUsage(db, sender.AccountID, model.OperationTransfer, time.Now())
+	if err != nil {
+		return err
+	}
+	return limit.CheckMany(limits, usage, model.OperationTransfer, amounts)
+}
+
+// resolve mengisi ToAccountID setiap baris dari nomor rekening atau account_id.
+// Nomor rekening (check digit valid) dicari sekaligus dalam satu query; yang tidak
+// terdaftar dibaca sebagai account_id seperti Ref.Resolve. Baris yang bukan keduanya
+// dikembalikan sebagai RowError.
+func resolve(db *gorm.DB, items []Item) ([]RowError, error) {
+	var numbers []string
+	for _, item := range items {
+		if number, ok := item.ToAccount.Number(); ok {
+			numbers = append(numbers, number)
+		}
+	}
+	ids, err := accountno.ResolveNumbers(db, numbers)
+	if err != nil {
+		return nil, err
+	}
+
+	var rowErrors []RowError
+	for i := range items {
+		if number, ok := items[i].ToAccount.Number(); ok {
+			if id, found := ids[number]; found {
+				items[i].ToAccountID = id
+				continue
+			}
+		}
+		if err := items[i].ToAccount.Check(); err != nil {
+			items[i].ToAccountID = 0
+			rowErrors = append(rowErrors, RowError{RowNumber: i + 1, Error: err.Error()})
+			continue
+		}
+		items[i].ToAccountID, _ = items[i].ToAccount.ID()
+	}
+	return rowErrors, nil
+}
+
+// Create menyimpan batch dan barisnya dengan status pending untuk dieksekusi worker
+func Create(tx *gorm.DB, sender model.Account, mode string, items []Item, actor audit.Actor) (model.TransferBatch, error) {
+	if mode != model.BatchModePartial && mode != model.BatchModeAtomic {
+		return model.TransferBatch{}, ErrInvalidMode
+	}
+
+	batch := model.TransferBatch{
+		AccountID:       sender.AccountID,
+		Mode:            mode,
+		Status:          model.BatchPending,
+		Currency:        sender.Currency,
+		ItemCount:       len(items),
+		CreatedByAuthID: actor.AuthID,
+		CreatedBy:       actor.Username,
+		RequestID:       actor.RequestID,
+	}
+	for _, item := range items {
+		batch.TotalAmount += item.Amount
+	}
+	if err := tx.Create(&batch).Error; err != nil {
+		return batch, err
+	}
+
+	rows := make([]model.TransferBatchItem, 0, len(items))
+	for i, item := range items {
+		rows = append(rows, model.TransferBatchItem{
+			TransferBatchID: batch.TransferBatchID,
+			RowNumber:       i + 1,
+			ToAccountID:     item.ToAccountID,
+			Amount:          item.Amount,
+			Description:     item.Description,
+			Status:          model.BatchItemPending,
+		})
+	}
+	if err := tx.CreateInBatches(rows, 200).Error; err != nil {
+		return batch, err
+	}
+
+	return batch, audit.Record(tx, actor, audit.Entry{
+		Action:   audit.ActionCreate,
+		Entity:   "transfer_batch",
+		EntityID: batch.TransferBatchID,
+		After:    batch,
+	})
+}
+
+// FillAccountNumbers mengisi ToAccountNumber setiap baris; account_id penerima tidak
+// ditampilkan ke nasabah
+func FillAccountNumbers(db *gorm.DB, items []model.TransferBatchItem) error {
+	return accountno.FillAll(db, items)
+}
+
+// WriteResultCSV menulis hasil per baris batch (isi ToAccountNumber dulu dengan FillAccountNumbers)
+func WriteResultCSV(w io.Writer, items []model.TransferBatchItem) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"row_number", "to_account_number", "amount", "description", "status", "transaction_id", "error"}); err != nil {
+		return err
+	}
+	for _, item := range items {
+		transactionID := ""
+		if item.TransactionID != nil {
+			transactionID = strconv.FormatInt(*item.TransactionID, 10)
+		}
+		if err := cw.Write([]string{
+			strconv.Itoa(item.RowNumber),
+			item.ToAccountNumber,
+			strconv.FormatInt(item.Amount, 10),
+			item.Description,
+			item.Status,
+			transactionID,
+			item.Error,
+		}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
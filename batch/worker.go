@@ -0,0 +1,375 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"task-golang-db/audit"
+	"task-golang-db/ledger"
+	"task-golang-db/model"
+	"task-golang-db/webhook"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Config struct {
+	PollInterval time.Duration
+	// Lease adalah lama klaim batch; batch processing yang lease-nya habis (instance mati) diambil ulang
+	Lease time.Duration
+}
+
+// DefaultConfig berisi interval polling dan lease bawaan; field Config yang nol
+// diganti nilai ini di NewWorker.
+var DefaultConfig = Config{
+	PollInterval: 5 * time.Second,
+	Lease:        5 * time.Minute,
+}
+
+// errRolledBack menandai baris yang ikut dibatalkan pada batch atomic
+var errRolledBack = errors.New("not executed, batch rolled back")
+
+// Worker mengeksekusi batch transfer secara asinkron. Aman dijalankan di beberapa
+// instance karena batch diklaim dengan FOR UPDATE SKIP LOCKED dan lease.
+type Worker struct {
+	db     *gorm.DB
+	config Config
+}
+
+// Constructor untuk Worker
+func NewWorker(db *gorm.DB, config Config) *Worker {
+	if config.PollInterval <= 0 {
+		config.PollInterval = DefaultConfig.PollInterval
+	}
+	if config.Lease <= 0 {
+		config.Lease = DefaultConfig.Lease
+	}
+	return &Worker{
+		db:     db,
+		config: config,
+	}
+}
+
+// Run mengeksekusi batch sampai ctx dibatalkan
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for ctx.Err() == nil {
+			batch, ok, err := w.claim()
+			if err != nil {
+				log.Println("batch: claim failed:", err)
+				break
+			}
+			if !ok {
+				break
+			}
+			if err := w.process(ctx, batch); err != nil {
+				log.Printf("batch: process %d failed: %v", batch.TransferBatchID, err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// claim mengambil satu batch pending (atau processing yang lease-nya habis)
+func (w *Worker) claim() (model.TransferBatch, bool, error) {
+	var batch model.TransferBatch
+	claimed := false
+
+	err := w.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? OR (status = ? AND locked_until < ?)", model.BatchPending, model.BatchProcessing, now).
+			Order("transfer_batch_id").
+			First(&batch).Error
+		if err == gorm.ErrRecordNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		claimed = true
+
+		lockedUntil := now.Add(w.config.Lease)
+		batch.LockedUntil = &lockedUntil
+		batch.Status = model.BatchProcessing
+		if batch.StartedAt == nil {
+			batch.StartedAt = &now
+		}
+		return tx.Model(&batch).Updates(map[string]interface{}{
+			"status":       batch.Status,
+			"locked_until": batch.LockedUntil,
+			"started_at":   batch.StartedAt,
+		}).Error
+	})
+	return batch, claimed, err
+}
+
+func (w *Worker) process(ctx context.Context, batch model.TransferBatch) error {
+	actor := audit.Actor{
+		AuthID:    batch.CreatedByAuthID,
+		Username:  batch.CreatedBy,
+		RequestID: batch.RequestID,
+	}
+
+	if batch.Mode == model.BatchModeAtomic {
+		return w.processAtomic(batch, actor)
+	}
+	if err := w.processPartial(ctx, batch, actor); err != nil {
+		return err
+	}
+	return w.db.Transaction(func(tx *gorm.DB) error {
+		return finish(tx, batch.TransferBatchID)
+	})
+}
+
+// processPartial mengeksekusi setiap baris dalam transaksinya sendiri; baris yang gagal tidak membatalkan yang lain
+func (w *Worker) processPartial(ctx context.Context, batch model.TransferBatch, actor audit.Actor) error {
+	var itemIDs []int64
+	if err := w.db.Model(&model.TransferBatchItem{}).
+		Where("transfer_batch_id = ? AND status = ?", batch.TransferBatchID, model.BatchItemPending).
+		Order("row_number").
+		Pluck("transfer_batch_item_id", &itemIDs).Error; err != nil {
+		return err
+	}
+
+	for _, itemID := range itemIDs {
+		if ctx.Err() != nil {
+			// Sisa baris dilanjutkan instance lain setelah lease habis
+			return ctx.Err()
+		}
+		err := w.db.Transaction(func(tx *gorm.DB) error {
+			var item model.TransferBatchItem
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&item, itemID).Error; err != nil {
+				return err
+			}
+			if item.Status != model.BatchItemPending {
+				return nil
+			}
+
+			// Transfer dijalankan dalam savepoint supaya kegagalan tetap bisa dicatat
+			if err := tx.SavePoint("batch_item").Error; err != nil {
+				return err
+			}
+			result, transferErr := ledger.Transfer(tx, ledger.TransferRequest{
+				FromAccountID: batch.AccountID,
+				ToAccountID:   item.ToAccountID,
+				Amount:        item.Amount,
+				Actor:         actor,
+			})
+			if transferErr != nil {
+				// Tanpa rollback yang berhasil, sisa tulisan transfer gagal ikut ter-commit
+				if err := tx.RollbackTo("batch_item").Error; err != nil {
+					return err
+				}
+				return tx.Model(&item).Updates(map[string]interface{}{
+					"status": model.BatchItemFailed,
+					"error":  transferErr.Error(),
+				}).Error
+			}
+			return tx.Model(&item).Updates(map[string]interface{}{
+				"status":         model.BatchItemSuccess,
+				"transaction_id": result.Transaction.TransactionID,
+			}).Error
+		})
+		if err != nil {
+			return err
+		}
+
+		// Perpanjang lease selama batch masih berjalan
+		if err := w.db.Model(&model.TransferBatch{}).
+			Where("transfer_batch_id = ?", batch.TransferBatchID).
+			Update("locked_until", time.Now().Add(w.config.Lease)).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// processAtomic mengeksekusi semua baris dalam satu transaksi: satu gagal, semua dibatalkan.
+// Batch ditutup di transaksi yang sama, jadi batch yang transfernya sudah ter-commit
+// tidak pernah diklaim dan dibayar ulang.
+func (w *Worker) processAtomic(batch model.TransferBatch, actor audit.Actor) error {
+	var failed model.TransferBatchItem
+	var failure error
+
+	// Transaksi panjang tidak bisa memperpanjang lease per baris seperti processPartial
+	stopHeartbeat := w.heartbeat(batch.TransferBatchID)
+	defer stopHeartbeat()
+
+	err := w.db.Transaction(func(tx *gorm.DB) error {
+		var items []model.TransferBatchItem
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("transfer_batch_id = ?", batch.TransferBatchID).
+			Order("row_number").
+			Find(&items).Error; err != nil {
+			return err
+		}
+
+		// Baris yang sudah tidak pending berarti hasil batch ini sudah tercatat
+		// (instance sebelumnya mati sebelum menutup batch); cukup tutup batch-nya
+		for _, item := range items {
+			if item.Status != model.BatchItemPending {
+				stopHeartbeat()
+				return finish(tx, batch.TransferBatchID)
+			}
+		}
+
+		for _, item := range items {
+			result, err := ledger.Transfer(tx, ledger.TransferRequest{
+				FromAccountID: batch.AccountID,
+				ToAccountID:   item.ToAccountID,
+				Amount:        item.Amount,
+				Actor:         actor,
+			})
+			if err != nil {
+				failed, failure = item, err
+				return err
+			}
+			if err := tx.Model(&item).Updates(map[string]interface{}{
+				"status":         model.BatchItemSuccess,
+				"transaction_id": result.Transaction.TransactionID,
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		// Heartbeat dihentikan dulu supaya UPDATE lease tidak menunggu lock baris batch dari tx ini
+		stopHeartbeat()
+		return finish(tx, batch.TransferBatchID)
+	})
+	if err == nil {
+		return nil
+	}
+	if failure == nil {
+		// Error database, batch dicoba ulang setelah lease habis
+		return err
+	}
+
+	// Semua transfer sudah di-rollback; catat baris penyebab, tandai sisanya, lalu tutup batch
+	stopHeartbeat()
+	return w.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.TransferBatchItem{}).
+			Where("transfer_batch_id = ?", batch.TransferBatchID).
+			Updates(map[string]interface{}{"status": model.BatchItemFailed, "error": errRolledBack.Error()}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&failed).Update("error", failure.Error()).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.TransferBatch{}).
+			Where("transfer_batch_id = ?", batch.TransferBatchID).
+			Update("error", fmt.Sprintf("row %d: %v", failed.RowNumber, failure)).Error; err != nil {
+			return err
+		}
+		return finish(tx, batch.TransferBatchID)
+	})
+}
+
+// heartbeat memperpanjang lease batch dari koneksi terpisah setiap sepertiga Lease.
+// Fungsi yang dikembalikan menghentikan heartbeat dan menunggu UPDATE terakhir selesai;
+// aman dipanggil lebih dari sekali.
+func (w *Worker) heartbeat(batchID int64) func() {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(w.config.Lease / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := w.db.Model(&model.TransferBatch{}).
+					Where("transfer_batch_id = ? AND status = ?", batchID, model.BatchProcessing).
+					Update("locked_until", time.Now().Add(w.config.Lease)).Error; err != nil {
+					log.Printf("batch: extend lease %d failed: %v", batchID, err)
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			<-stopped
+		})
+	}
+}
+
+// finish menghitung hasil baris dan menutup batch di dalam tx
+func finish(tx *gorm.DB, batchID int64) error {
+	var batch model.TransferBatch
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&batch, batchID).Error; err != nil {
+		return err
+	}
+
+	var counts []struct {
+		Status string
+		Total  int
+	}
+	if err := tx.Model(&model.TransferBatchItem{}).
+		Select("status, COUNT(*) AS total").
+		Where("transfer_batch_id = ?", batchID).
+		Group("status").
+		Scan(&counts).Error; err != nil {
+		return err
+	}
+
+	batch.SuccessCount, batch.FailedCount = 0, 0
+	for _, count := range counts {
+		switch count.Status {
+		case model.BatchItemSuccess:
+			batch.SuccessCount = count.Total
+		case model.BatchItemFailed:
+			batch.FailedCount = count.Total
+		case model.BatchItemPending:
+			// Masih ada baris yang belum dieksekusi, biarkan lease yang mengulang
+			return nil
+		}
+	}
+
+	switch {
+	case batch.FailedCount == 0:
+		batch.Status = model.BatchCompleted
+	case batch.SuccessCount == 0:
+		batch.Status = model.BatchFailed
+	default:
+		batch.Status = model.BatchPartiallyFailed
+	}
+	now := time.Now()
+	batch.CompletedAt = &now
+	batch.LockedUntil = nil
+
+	if err := tx.Model(&batch).Updates(map[string]interface{}{
+		"status":        batch.Status,
+		"success_count": batch.SuccessCount,
+		"failed_count":  batch.FailedCount,
+		"completed_at":  batch.CompletedAt,
+		"locked_until":  nil,
+	}).Error; err != nil {
+		return err
+	}
+
+	return webhook.Enqueue(tx, webhook.EventBatchCompleted, map[string]interface{}{
+		"transfer_batch_id": batch.TransferBatchID,
+		"account_id":        batch.AccountID,
+		"mode":              batch.Mode,
+		"status":            batch.Status,
+		"total_amount":      batch.TotalAmount,
+		"success_count":     batch.SuccessCount,
+		"failed_count":      batch.FailedCount,
+	})
+}
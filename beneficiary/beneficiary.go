@@ -0,0 +1,75 @@
+package beneficiary
+
+import (
+	"errors"
+	"task-golang-db/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrNotFound = errors.New("beneficiary not found")
+
+// Resolve mengembalikan akun tujuan dari beneficiary milik accountID
+func Resolve(db *gorm.DB, accountID, beneficiaryID int64) (int64, error) {
+	var beneficiary model.Beneficiary
+	err := db.First(&beneficiary, "beneficiary_id = ? AND account_id = ?", beneficiaryID, accountID).Error
+	if err == gorm.ErrRecordNotFound {
+		return 0, ErrNotFound
+	}
+	return beneficiary.BeneficiaryAccountID, err
+}
+
+// Touch mencatat pemakaian penerima setelah transfer berhasil. Penerima yang belum
+// disimpan hanya ditambahkan jika save bernilai true, dengan nama akun sebagai nickname.
+func Touch(tx *gorm.DB, accountID int64, receiver model.Account, save bool) error {
+	now := time.Now()
+	result := tx.Model(&model.Beneficiary{}).
+		Where("account_id = ? AND beneficiary_account_id = ?", accountID, receiver.AccountID).
+		Updates(map[string]interface{}{
+			"transfer_count": gorm.Expr("transfer_count + 1"),
+			"last_used_at":   now,
+		})
+	if result.Error != nil || result.RowsAffected > 0 || !save {
+		return result.Error
+	}
+
+	return tx.Create(&model.Beneficiary{
+		AccountID:            accountID,
+		BeneficiaryAccountID: receiver.AccountID,
+		Nickname:             receiver.Name,
+		TransferCount:        1,
+		LastUsedAt:           &now,
+	}).Error
+}
+
+// Recipient adalah penerima yang paling sering menerima transfer dari sebuah akun
+type Recipient struct {
+	AccountID     int64     `json:"-"`
+	AccountNumber string    `json:"account_number"`
+	Name          string    `json:"name"`
+	TransferCount int64     `json:"transfer_count"`
+	TotalAmount   int64     `json:"total_amount"`
+	LastTransfer  time.Time `json:"last_transfer"`
+	BeneficiaryID *int64    `json:"beneficiary_id"`
+}
+
+// Frequent menghitung penerima transfer terbanyak sejak since dari tabel transaction,
+// termasuk penerima yang belum disimpan sebagai beneficiary. Baris berkategori (biaya,
+// pajak, pocket) dan refund/reversal tidak dihitung sebagai transfer nasabah.
+func Frequent(db *gorm.DB, accountID int64, since time.Time, limit int) ([]Recipient, error) {
+	recipients := []Recipient{}
+	err := db.Table(`"transaction" t`).
+		Select(`t.to_account_id AS account_id, a.account_number, a.name, COUNT(*) AS transfer_count,
+			SUM(t.amount) AS total_amount, MAX(t.transaction_date) AS last_transfer, b.beneficiary_id`).
+		Joins("JOIN accounts a ON a.account_id = t.to_account_id").
+		Joins("LEFT JOIN beneficiaries b ON b.account_id = t.from_account_id AND b.beneficiary_account_id = t.to_account_id").
+		Where(`t.from_account_id = ? AND t.to_account_id <> t.from_account_id AND t.transaction_date >= ?
+			AND t.transaction_category_id IS NULL AND t.reversal_of_id IS NULL`,
+			accountID, since.Format("2006-01-02 15:04:05")).
+		Group("t.to_account_id, a.account_number, a.name, b.beneficiary_id").
+		Order("transfer_count DESC, last_transfer DESC").
+		Limit(limit).
+		Scan(&recipients).Error
+	return recipients, err
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"task-golang-db/accountno"
+	"task-golang-db/audit"
+	"task-golang-db/currency"
+	"task-golang-db/model"
+	"task-golang-db/reconcile"
+
+	"gorm.io/gorm"
+)
+
+// runCommand menjalankan perintah CLI, contoh: go run . audit-verify
+func runCommand(db *gorm.DB, args []string) {
+	switch args[0] {
+	case "audit-verify":
+		result, err := audit.Verify(db)
+		if err != nil {
+			log.Fatal("audit-verify failed: ", err)
+		}
+		printJSON(result)
+		if !result.Valid {
+			os.Exit(1)
+		}
+	case "fx-load":
+		// go run . fx-load rates.csv
+		if len(args) < 2 {
+			log.Fatal("usage: fx-load <rates.csv>")
+		}
+		f, err := os.Open(args[1])
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+
+		rates, err := currency.ParseRatesCSV(f, "file:"+filepath.Base(args[1]))
+		if err != nil {
+			log.Fatal("fx-load failed: ", err)
+		}
+		if len(rates) > 0 {
+			if err := db.Create(&rates).Error; err != nil {
+				log.Fatal("fx-load failed: ", err)
+			}
+		}
+		log.Printf("fx-load: %d exchange rates loaded", len(rates))
+	case "reconcile":
+		// go run . reconcile [--tickets]
+		run, err := reconcile.Run(db, reconcile.Options{
+			Trigger:     reconcile.TriggerCommand,
+			OpenTickets: len(args) > 1 && args[1] == "--tickets",
+			Actor:       audit.Actor{Username: "command"},
+		})
+		if err != nil && run.ReconciliationRunID == 0 {
+			log.Fatal("reconcile failed: ", err)
+		}
+		var discrepancies []model.ReconciliationDiscrepancy
+		if err := db.Where("reconciliation_run_id = ?", run.ReconciliationRunID).Order("account_id, field").Find(&discrepancies).Error; err != nil {
+			log.Fatal("reconcile failed: ", err)
+		}
+		printJSON(map[string]interface{}{"run": run, "discrepancies": discrepancies})
+		if err != nil || run.DiscrepancyCount > 0 {
+			os.Exit(1)
+		}
+	case "account-numbers":
+		// go run . account-numbers: memberi nomor rekening ke akun lama yang belum punya
+		var accounts []model.Account
+		if err := db.Unscoped().Where("account_number IS NULL OR account_number = ''").Order("account_id").Find(&accounts).Error; err != nil {
+			log.Fatal("account-numbers failed: ", err)
+		}
+		for _, account := range accounts {
+			number, err := accountno.Generate(db)
+			if err != nil {
+				log.Fatal("account-numbers failed: ", err)
+			}
+			if err := db.Unscoped().Model(&account).Update("account_number", number).Error; err != nil {
+				log.Fatal("account-numbers failed: ", err)
+			}
+		}
+		log.Printf("account-numbers: %d accounts numbered", len(accounts))
+	default:
+		log.Fatalf("unknown command %q", args[0])
+	}
+}
+
+func printJSON(v interface{}) {
+	out, _ := json.MarshalIndent(v, "", "  ")
+	fmt.Println(string(out))
+}
@@ -0,0 +1,107 @@
+package currency
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"task-golang-db/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Default adalah mata uang akun lama dan akun yang dibuat tanpa currency
+const Default = "IDR"
+
+// minorUnits adalah jumlah digit desimal per mata uang (ISO 4217).
+// Saldo dan nominal disimpan dalam satuan terkecil; rupiah tidak memakai sen sehingga 0.
+var minorUnits = map[string]int{
+	"IDR": 0,
+	"USD": 2,
+	"SGD": 2,
+}
+
+// numericCodes adalah kode numerik ISO 4217, dipakai di payload QR (tag 53)
+var numericCodes = map[string]string{
+	"IDR": "360",
+	"USD": "840",
+	"SGD": "702",
+}
+
+var ErrRateNotFound = errors.New("exchange rate not found")
+
+// Normalize mengubah kode mata uang ke huruf besar, kosong menjadi Default
+func Normalize(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return Default
+	}
+	return code
+}
+
+// Supported mengecek apakah mata uang didukung
+func Supported(code string) bool {
+	_, ok := minorUnits[code]
+	return ok
+}
+
+// MinorUnits mengembalikan jumlah digit desimal mata uang
+func MinorUnits(code string) int {
+	return minorUnits[code]
+}
+
+// Numeric mengembalikan kode numerik ISO 4217, contoh IDR -> 360
+func Numeric(code string) string {
+	return numericCodes[code]
+}
+
+// FromNumeric mengembalikan kode huruf untuk kode numerik ISO 4217, kosong jika tidak didukung
+func FromNumeric(numeric string) string {
+	for code, n := range numericCodes {
+		if n == numeric {
+			return code
+		}
+	}
+	return ""
+}
+
+// ToMajor mengubah nominal satuan terkecil ke satuan utama, contoh 1050 USD -> 10.50
+func ToMajor(code string, amount int64) float64 {
+	return float64(amount) / math.Pow10(minorUnits[code])
+}
+
+// Convert mengonversi nominal satuan terkecil from ke satuan terkecil to dengan rate (1 from = rate to).
+// Hasil dibulatkan ke bawah supaya sistem tidak pernah memberi lebih dari kurs.
+func Convert(amount int64, from, to string, rate float64) int64 {
+	major := ToMajor(from, amount) * rate
+	return int64(math.Floor(major*math.Pow10(minorUnits[to]) + 1e-9))
+}
+
+// LatestRate mengambil kurs yang berlaku pada waktu at. Jika hanya pasangan
+// kebalikannya yang tersedia, rate dihitung sebagai 1/rate.
+func LatestRate(db *gorm.DB, base, quote string, at time.Time) (model.ExchangeRate, error) {
+	var rate model.ExchangeRate
+	err := db.Where("base_currency = ? AND quote_currency = ? AND effective_at <= ?", base, quote, at).
+		Order("effective_at DESC, exchange_rate_id DESC").
+		First(&rate).Error
+	if err == nil {
+		return rate, nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return rate, err
+	}
+
+	err = db.Where("base_currency = ? AND quote_currency = ? AND effective_at <= ?", quote, base, at).
+		Order("effective_at DESC, exchange_rate_id DESC").
+		First(&rate).Error
+	if err == gorm.ErrRecordNotFound || (err == nil && rate.Rate <= 0) {
+		return rate, ErrRateNotFound
+	}
+	if err != nil {
+		return rate, err
+	}
+
+	rate.BaseCurrency, rate.QuoteCurrency = base, quote
+	rate.Rate = 1 / rate.Rate
+	return rate, nil
+}
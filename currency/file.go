@@ -0,0 +1,70 @@
+package currency
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"task-golang-db/model"
+	"time"
+)
+
+// ParseRatesCSV membaca kurs dari CSV dengan header:
+// base_currency,quote_currency,rate,effective_at (RFC3339)
+func ParseRatesCSV(r io.Reader, source string) ([]model.ExchangeRate, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	var rates []model.ExchangeRate
+	for i, record := range records[1:] {
+		line := i + 2
+		if len(record) != 4 {
+			return nil, fmt.Errorf("line %d: expected 4 columns, got %d", line, len(record))
+		}
+
+		rate, err := strconv.ParseFloat(strings.TrimSpace(record[2]), 64)
+		if err != nil || rate <= 0 {
+			return nil, fmt.Errorf("line %d: invalid rate %q", line, record[2])
+		}
+		effectiveAt, err := time.Parse(time.RFC3339, strings.TrimSpace(record[3]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid effective_at %q", line, record[3])
+		}
+
+		exchangeRate := model.ExchangeRate{
+			BaseCurrency:  Normalize(record[0]),
+			QuoteCurrency: Normalize(record[1]),
+			Rate:          rate,
+			EffectiveAt:   effectiveAt,
+			Source:        source,
+		}
+		if err := ValidateRate(exchangeRate); err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		rates = append(rates, exchangeRate)
+	}
+	return rates, nil
+}
+
+// ValidateRate memastikan pasangan mata uang didukung dan rate positif
+func ValidateRate(rate model.ExchangeRate) error {
+	if !Supported(rate.BaseCurrency) || !Supported(rate.QuoteCurrency) {
+		return fmt.Errorf("unsupported currency pair %s/%s", rate.BaseCurrency, rate.QuoteCurrency)
+	}
+	if rate.BaseCurrency == rate.QuoteCurrency {
+		return fmt.Errorf("base and quote currency must differ")
+	}
+	if rate.Rate <= 0 {
+		return fmt.Errorf("rate must be greater than 0")
+	}
+	return nil
+}
@@ -147,3 +147,1104 @@ CREATE TABLE public."transaction" (
 
 ALTER TABLE public."transaction" ADD CONSTRAINT transaction_transaction_category_id_fkey FOREIGN KEY (transaction_category_id) REFERENCES public.transaction_categories(transaction_category_id);
 
+
+
+
+
+-- public.webhook_subscriptions definition
+
+-- Drop table
+
+-- DROP TABLE public.webhook_subscriptions;
+
+CREATE TABLE public.webhook_subscriptions (
+	webhook_subscription_id int8 GENERATED ALWAYS AS IDENTITY NOT NULL,
+	partner varchar NOT NULL,
+	url varchar NOT NULL,
+	secret varchar NOT NULL,
+	event_types jsonb DEFAULT '[]'::jsonb NOT NULL,
+	active bool DEFAULT true NOT NULL,
+	created_at timestamptz DEFAULT now() NOT NULL,
+	CONSTRAINT webhook_subscriptions_pk PRIMARY KEY (webhook_subscription_id)
+);
+
+
+
+
+-- public.outbox_events definition
+
+-- Drop table
+
+-- DROP TABLE public.outbox_events;
+
+CREATE TABLE public.outbox_events (
+	outbox_event_id int8 GENERATED ALWAYS AS IDENTITY NOT NULL,
+	event_type varchar NOT NULL,
+	payload jsonb NOT NULL,
+	created_at timestamptz DEFAULT now() NOT NULL,
+	processed_at timestamptz NULL,
+	CONSTRAINT outbox_events_pk PRIMARY KEY (outbox_event_id)
+);
+CREATE INDEX outbox_events_unprocessed_idx ON public.outbox_events (outbox_event_id) WHERE processed_at IS NULL;
+
+
+
+
+-- public.webhook_deliveries definition
+
+-- Drop table
+
+-- DROP TABLE public.webhook_deliveries;
+
+CREATE TABLE public.webhook_deliveries (
+	webhook_delivery_id int8 GENERATED ALWAYS AS IDENTITY NOT NULL,
+	webhook_subscription_id int8 NOT NULL,
+	outbox_event_id int8 NOT NULL,
+	status varchar(20) DEFAULT 'pending' NOT NULL,
+	attempts int4 DEFAULT 0 NOT NULL,
+	next_attempt_at timestamptz DEFAULT now() NOT NULL,
+	last_status_code int4 DEFAULT 0 NOT NULL,
+	last_error varchar DEFAULT '' NOT NULL,
+	delivered_at timestamptz NULL,
+	created_at timestamptz DEFAULT now() NOT NULL,
+	CONSTRAINT webhook_deliveries_pk PRIMARY KEY (webhook_delivery_id)
+);
+CREATE INDEX webhook_deliveries_due_idx ON public.webhook_deliveries (status, next_attempt_at);
+
+
+-- public.webhook_deliveries foreign keys
+
+ALTER TABLE public.webhook_deliveries ADD CONSTRAINT webhook_deliveries_subscription_fkey FOREIGN KEY (webhook_subscription_id) REFERENCES public.webhook_subscriptions(webhook_subscription_id);
+ALTER TABLE public.webhook_deliveries ADD CONSTRAINT webhook_deliveries_outbox_event_fkey FOREIGN KEY (outbox_event_id) REFERENCES public.outbox_events(outbox_event_id);
+
+
+
+
+-- public.account_events definition
+
+-- Drop table
+
+-- DROP TABLE public.account_events;
+
+CREATE TABLE public.account_events (
+	account_event_id int8 GENERATED ALWAYS AS IDENTITY NOT NULL,
+	account_id int8 NOT NULL,
+	event_type varchar NOT NULL,
+	payload jsonb NOT NULL,
+	created_at timestamptz DEFAULT now() NOT NULL,
+	CONSTRAINT account_events_pk PRIMARY KEY (account_event_id)
+);
+CREATE INDEX account_events_account_idx ON public.account_events (account_id, account_event_id);
+
+
+-- public.account_events foreign keys
+
+ALTER TABLE public.account_events ADD CONSTRAINT account_events_account_id_fkey FOREIGN KEY (account_id) REFERENCES public.accounts(account_id);
+
+
+
+
+-- public.notification_preferences definition
+
+-- Drop table
+
+-- DROP TABLE public.notification_preferences;
+
+CREATE TABLE public.notification_preferences (
+	account_id int8 NOT NULL,
+	"language" varchar(5) DEFAULT 'id' NOT NULL,
+	email varchar DEFAULT '' NOT NULL,
+	phone varchar DEFAULT '' NOT NULL,
+	push_token varchar DEFAULT '' NOT NULL,
+	email_enabled bool DEFAULT false NOT NULL,
+	sms_enabled bool DEFAULT false NOT NULL,
+	push_enabled bool DEFAULT false NOT NULL,
+	updated_at timestamptz DEFAULT now() NOT NULL,
+	CONSTRAINT notification_preferences_pk PRIMARY KEY (account_id)
+);
+
+
+-- public.notification_preferences foreign keys
+
+ALTER TABLE public.notification_preferences ADD CONSTRAINT notification_preferences_account_id_fkey FOREIGN KEY (account_id) REFERENCES public.accounts(account_id);
+
+
+
+
+-- public.notifications definition
+
+-- Drop table
+
+-- DROP TABLE public.notifications;
+
+CREATE TABLE public.notifications (
+	notification_id int8 GENERATED ALWAYS AS IDENTITY NOT NULL,
+	account_id int8 NOT NULL,
+	"event" varchar NOT NULL,
+	channel varchar(10) NOT NULL,
+	recipient varchar NOT NULL,
+	subject varchar NOT NULL,
+	body text NOT NULL,
+	status varchar(10) DEFAULT 'pending' NOT NULL,
+	attempts int4 DEFAULT 0 NOT NULL,
+	next_attempt_at timestamptz DEFAULT now() NOT NULL,
+	last_error varchar DEFAULT '' NOT NULL,
+	sent_at timestamptz NULL,
+	created_at timestamptz DEFAULT now() NOT NULL,
+	CONSTRAINT notifications_pk PRIMARY KEY (notification_id)
+);
+CREATE INDEX notifications_due_idx ON public.notifications (status, next_attempt_at);
+
+
+
+
+-- public.known_devices definition
+
+-- Drop table
+
+-- DROP TABLE public.known_devices;
+
+CREATE TABLE public.known_devices (
+	known_device_id int8 GENERATED ALWAYS AS IDENTITY NOT NULL,
+	account_id int8 NOT NULL,
+	fingerprint varchar(64) NOT NULL,
+	user_agent varchar DEFAULT '' NOT NULL,
+	ip_address varchar DEFAULT '' NOT NULL,
+	first_seen_at timestamptz DEFAULT now() NOT NULL,
+	last_seen_at timestamptz DEFAULT now() NOT NULL,
+	CONSTRAINT known_devices_pk PRIMARY KEY (known_device_id),
+	CONSTRAINT known_devices_unique UNIQUE (account_id, fingerprint)
+);
+
+
+
+
+-- public.auths role
+
+ALTER TABLE public.auths ADD COLUMN "role" varchar(20) DEFAULT 'user' NOT NULL;
+
+
+
+
+-- public.audit_logs definition
+
+-- Drop table
+
+-- DROP TABLE public.audit_logs;
+
+CREATE TABLE public.audit_logs (
+	audit_log_id int8 GENERATED ALWAYS AS IDENTITY NOT NULL,
+	actor_auth_id int8 DEFAULT 0 NOT NULL,
+	actor_username varchar DEFAULT '' NOT NULL,
+	"action" varchar(30) NOT NULL,
+	entity varchar(50) NOT NULL,
+	entity_id varchar DEFAULT '' NOT NULL,
+	"before" json NULL,
+	"after" json NULL,
+	request_id varchar(64) DEFAULT '' NOT NULL,
+	client_ip varchar DEFAULT '' NOT NULL,
+	created_at timestamptz NOT NULL,
+	prev_hash varchar(64) DEFAULT '' NOT NULL,
+	hash varchar(64) NOT NULL,
+	CONSTRAINT audit_logs_pk PRIMARY KEY (audit_log_id)
+);
+CREATE INDEX audit_logs_entity_idx ON public.audit_logs (entity, entity_id, created_at);
+
+-- audit_logs append-only: tolak UPDATE dan DELETE
+
+CREATE OR REPLACE FUNCTION public.audit_logs_append_only() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'audit_logs is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_logs_no_update BEFORE UPDATE OR DELETE ON public.audit_logs
+	FOR EACH ROW EXECUTE FUNCTION public.audit_logs_append_only();
+
+
+
+
+-- public.accounts lifecycle (status & soft delete)
+
+ALTER TABLE public.accounts ADD COLUMN status varchar(10) DEFAULT 'pending' NOT NULL;
+ALTER TABLE public.accounts ADD COLUMN status_reason varchar DEFAULT '' NOT NULL;
+ALTER TABLE public.accounts ADD COLUMN deleted_at timestamptz NULL;
+ALTER TABLE public.accounts ADD CONSTRAINT accounts_status_check CHECK (status IN ('pending', 'active', 'frozen', 'dormant', 'closed'));
+-- akun lama yang sudah punya login dianggap aktif
+UPDATE public.accounts SET status = 'active' WHERE account_id IN (SELECT account_id FROM public.auths);
+
+
+
+
+-- public.account_status_histories definition
+
+-- Drop table
+
+-- DROP TABLE public.account_status_histories;
+
+CREATE TABLE public.account_status_histories (
+	account_status_history_id int8 GENERATED ALWAYS AS IDENTITY NOT NULL,
+	account_id int8 NOT NULL,
+	from_status varchar(10) NOT NULL,
+	to_status varchar(10) NOT NULL,
+	reason varchar DEFAULT '' NOT NULL,
+	actor_auth_id int8 DEFAULT 0 NOT NULL,
+	created_at timestamptz DEFAULT now() NOT NULL,
+	CONSTRAINT account_status_histories_pk PRIMARY KEY (account_status_history_id)
+);
+
+
+-- public.account_status_histories foreign keys
+
+ALTER TABLE public.account_status_histories ADD CONSTRAINT account_status_histories_account_id_fkey FOREIGN KEY (account_id) REFERENCES public.accounts(account_id);
+
+
+
+
+-- multi-currency: saldo dan nominal dalam satuan terkecil mata uang (IDR tanpa sen)
+
+ALTER TABLE public.accounts ADD COLUMN currency char(3) DEFAULT 'IDR' NOT NULL;
+ALTER TABLE public."transaction" ADD COLUMN currency char(3) DEFAULT 'IDR' NOT NULL;
+ALTER TABLE public."transaction" ADD COLUMN fx_quote_id varchar(32) NULL;
+
+
+
+
+-- public.exchange_rates definition
+
+-- Drop table
+
+-- DROP TABLE public.exchange_rates;
+
+CREATE TABLE public.exchange_rates (
+	exchange_rate_id int8 GENERATED ALWAYS AS IDENTITY NOT NULL,
+	base_currency char(3) NOT NULL,
+	quote_currency char(3) NOT NULL,
+	rate numeric(24, 10) NOT NULL,
+	effective_at timestamptz NOT NULL,
+	"source" varchar DEFAULT '' NOT NULL,
+	created_at timestamptz DEFAULT now() NOT NULL,
+	CONSTRAINT exchange_rates_pk PRIMARY KEY (exchange_rate_id),
+	CONSTRAINT exchange_rates_rate_check CHECK (rate > 0)
+);
+CREATE INDEX exchange_rates_pair_idx ON public.exchange_rates (base_currency, quote_currency, effective_at DESC);
+
+
+
+
+-- public.fx_quotes definition
+
+-- Drop table
+
+-- DROP TABLE public.fx_quotes;
+
+CREATE TABLE public.fx_quotes (
+	fx_quote_id varchar(32) NOT NULL,
+	from_account_id int8 NOT NULL,
+	to_account_id int8 NOT NULL,
+	from_currency char(3) NOT NULL,
+	to_currency char(3) NOT NULL,
+	mid_rate numeric(24, 10) NOT NULL,
+	spread_bps int8 NOT NULL,
+	applied_rate numeric(24, 10) NOT NULL,
+	source_amount int8 NOT NULL,
+	target_amount int8 NOT NULL,
+	expires_at timestamptz NOT NULL,
+	used_at timestamptz NULL,
+	created_at timestamptz DEFAULT now() NOT NULL,
+	CONSTRAINT fx_quotes_pk PRIMARY KEY (fx_quote_id)
+);
+
+
+-- public."transaction" foreign keys
+
+ALTER TABLE public."transaction" ADD CONSTRAINT transaction_fx_quote_id_fkey FOREIGN KEY (fx_quote_id) REFERENCES public.fx_quotes(fx_quote_id);
+
+
+
+
+-- public.standing_orders definition
+
+-- Drop table
+
+-- DROP TABLE public.standing_orders;
+
+CREATE TABLE public.standing_orders (
+	standing_order_id int8 GENERATED ALWAYS AS IDENTITY NOT NULL,
+	account_id int8 NOT NULL,
+	to_account_id int8 NOT NULL,
+	amount int8 NOT NULL,
+	description varchar DEFAULT '' NOT NULL,
+	schedule_type varchar(10) NOT NULL,
+	cron_expr varchar DEFAULT '' NOT NULL,
+	start_at timestamptz NOT NULL,
+	end_at timestamptz NULL,
+	max_count int4 NULL,
+	executed_count int4 DEFAULT 0 NOT NULL,
+	next_run_at timestamptz NULL,
+	scheduled_for timestamptz NULL,
+	retry_count int4 DEFAULT 0 NOT NULL,
+	status varchar(10) DEFAULT 'active' NOT NULL,
+	last_error varchar DEFAULT '' NOT NULL,
+	created_at timestamptz DEFAULT now() NOT NULL,
+	updated_at timestamptz DEFAULT now() NOT NULL,
+	CONSTRAINT standing_orders_pk PRIMARY KEY (standing_order_id),
+	CONSTRAINT standing_orders_amount_check CHECK (amount > 0)
+);
+CREATE INDEX standing_orders_due_idx ON public.standing_orders (next_run_at) WHERE status = 'active';
+
+
+-- public.standing_orders foreign keys
+
+ALTER TABLE public.standing_orders ADD CONSTRAINT standing_orders_account_id_fkey FOREIGN KEY (account_id) REFERENCES public.accounts(account_id);
+ALTER TABLE public.standing_orders ADD CONSTRAINT standing_orders_to_account_id_fkey FOREIGN KEY (to_account_id) REFERENCES public.accounts(account_id);
+
+
+
+
+-- public.standing_order_executions definition
+
+-- Drop table
+
+-- DROP TABLE public.standing_order_executions;
+
+CREATE TABLE public.standing_order_executions (
+	standing_order_execution_id int8 GENERATED ALWAYS AS IDENTITY NOT NULL,
+	standing_order_id int8 NOT NULL,
+	scheduled_for timestamptz NOT NULL,
+	attempt int4 NOT NULL,
+	success bool NOT NULL,
+	transaction_id int8 NULL,
+	error varchar DEFAULT '' NOT NULL,
+	created_at timestamptz DEFAULT now() NOT NULL,
+	CONSTRAINT standing_order_executions_pk PRIMARY KEY (standing_order_execution_id)
+);
+
+
+-- public.standing_order_executions foreign keys
+
+ALTER TABLE public.standing_order_executions ADD CONSTRAINT standing_order_executions_order_fkey FOREIGN KEY (standing_order_id) REFERENCES public.standing_orders(standing_order_id);
+
+
+
+
+-- public.accounts: saldo yang ditahan hold (available = balance - held_balance)
+
+ALTER TABLE public.accounts ADD COLUMN held_balance int8 DEFAULT 0 NOT NULL;
+
+
+
+
+-- public.holds definition
+
+-- Drop table
+
+-- DROP TABLE public.holds;
+
+CREATE TABLE public.holds (
+	hold_id int8 GENERATED ALWAYS AS IDENTITY NOT NULL,
+	account_id int8 NOT NULL,
+	merchant_account_id int8 NOT NULL,
+	amount int8 NOT NULL,
+	captured_amount int8 DEFAULT 0 NOT NULL,
+	currency char(3) NOT NULL,
+	description varchar DEFAULT '' NOT NULL,
+	status varchar(10) DEFAULT 'active' NOT NULL,
+	expires_at timestamptz NOT NULL,
+	transaction_id int8 NULL,
+	created_at timestamptz DEFAULT now() NOT NULL,
+	updated_at timestamptz DEFAULT now() NOT NULL,
+	closed_at timestamptz NULL,
+	CONSTRAINT holds_pk PRIMARY KEY (hold_id),
+	CONSTRAINT holds_amount_check CHECK (amount > 0)
+);
+CREATE INDEX holds_expiry_idx ON public.holds (expires_at) WHERE status = 'active';
+
+
+-- public.holds foreign keys
+
+ALTER TABLE public.holds ADD CONSTRAINT holds_account_id_fkey FOREIGN KEY (account_id) REFERENCES public.accounts(account_id);
+ALTER TABLE public.holds ADD CONSTRAINT holds_merchant_account_id_fkey FOREIGN KEY (merchant_account_id) REFERENCES public.accounts(account_id);
+
+
+
+
+-- public.accounts: tier untuk limit transaksi
+
+ALTER TABLE public.accounts ADD COLUMN tier varchar(20) DEFAULT 'basic' NOT NULL;
+
+
+
+
+-- public.transaction_limits definition
+
+-- Drop table
+
+-- DROP TABLE public.transaction_limits;
+
+CREATE TABLE public.transaction_limits (
+	transaction_limit_id int8 GENERATED ALWAYS AS IDENTITY NOT NULL,
+	tier varchar(20) DEFAULT '' NOT NULL,
+	account_id int8 NULL,
+	operation varchar(20) NOT NULL,
+	max_single int8 NULL,
+	max_daily int8 NULL,
+	max_monthly int8 NULL,
+	max_daily_count int8 NULL,
+	created_at timestamptz DEFAULT now() NOT NULL,
+	updated_at timestamptz DEFAULT now() NOT NULL,
+	CONSTRAINT transaction_limits_pk PRIMARY KEY (transaction_limit_id)
+);
+CREATE UNIQUE INDEX transaction_limits_tier_uq ON public.transaction_limits (tier, operation) WHERE account_id IS NULL;
+CREATE UNIQUE INDEX transaction_limits_account_uq ON public.transaction_limits (account_id, operation) WHERE account_id IS NOT NULL;
+
+
+-- public.transaction_limits foreign keys
+
+ALTER TABLE public.transaction_limits ADD CONSTRAINT transaction_limits_account_id_fkey FOREIGN KEY (account_id) REFERENCES public.accounts(account_id);
+
+
+-- Limit default per tier
+
+INSERT INTO public.transaction_limits (tier, operation, max_single, max_daily, max_monthly, max_daily_count) VALUES
+('basic', 'transfer', 10000000, 25000000, 100000000, 20),
+('basic', 'topup', 10000000, 20000000, 50000000, 10),
+('premium', 'transfer', 100000000, 250000000, 1000000000, 100),
+('premium', 'topup', 100000000, 200000000, 500000000, 50);
+
+
+
+
+-- public.limit_usages definition
+
+-- Drop table
+
+-- DROP TABLE public.limit_usages;
+
+CREATE TABLE public.limit_usages (
+	account_id int8 NOT NULL,
+	operation varchar(20) NOT NULL,
+	"day" date NOT NULL,
+	amount int8 DEFAULT 0 NOT NULL,
+	count int8 DEFAULT 0 NOT NULL,
+	CONSTRAINT limit_usages_pk PRIMARY KEY (account_id, operation, day)
+);
+
+
+-- public.limit_usages foreign keys
+
+ALTER TABLE public.limit_usages ADD CONSTRAINT limit_usages_account_id_fkey FOREIGN KEY (account_id) REFERENCES public.accounts(account_id);
+
+
+
+
+-- public.accounts: kode referral milik akun (referral_account_id sudah ada sejak awal)
+
+ALTER TABLE public.accounts ADD COLUMN referral_code varchar(8) NULL;
+UPDATE public.accounts SET referral_code = upper(substr(md5(account_id::text || random()::text), 1, 8)) WHERE referral_code IS NULL;
+ALTER TABLE public.accounts ALTER COLUMN referral_code SET NOT NULL;
+ALTER TABLE public.accounts ADD CONSTRAINT accounts_referral_code_unique UNIQUE (referral_code);
+
+INSERT INTO public.transaction_categories ("name") VALUES ('Referral Bonus');
+
+
+
+
+-- public.referrals definition
+
+-- Drop table
+
+-- DROP TABLE public.referrals;
+
+CREATE TABLE public.referrals (
+	referral_id int8 GENERATED ALWAYS AS IDENTITY NOT NULL,
+	referrer_account_id int8 NOT NULL,
+	referee_account_id int8 NOT NULL,
+	status varchar(10) DEFAULT 'pending' NOT NULL,
+	reject_reason varchar DEFAULT '' NOT NULL,
+	referrer_bonus int8 DEFAULT 0 NOT NULL,
+	referee_bonus int8 DEFAULT 0 NOT NULL,
+	qualified_at timestamptz NULL,
+	created_at timestamptz DEFAULT now() NOT NULL,
+	CONSTRAINT referrals_pk PRIMARY KEY (referral_id),
+	CONSTRAINT referrals_referee_unique UNIQUE (referee_account_id),
+	CONSTRAINT referrals_self_check CHECK (referrer_account_id <> referee_account_id)
+);
+CREATE INDEX referrals_referrer_idx ON public.referrals (referrer_account_id);
+
+
+-- public.referrals foreign keys
+
+ALTER TABLE public.referrals ADD CONSTRAINT referrals_referrer_account_id_fkey FOREIGN KEY (referrer_account_id) REFERENCES public.accounts(account_id);
+ALTER TABLE public.referrals ADD CONSTRAINT referrals_referee_account_id_fkey FOREIGN KEY (referee_account_id) REFERENCES public.accounts(account_id);
+
+
+
+
+-- public."transaction": refund/reversal
+
+ALTER TABLE public."transaction" ADD COLUMN reversal_of_id int8 NULL;
+ALTER TABLE public."transaction" ADD COLUMN reversed_amount int8 DEFAULT 0 NOT NULL;
+ALTER TABLE public."transaction" ADD CONSTRAINT transaction_reversal_of_id_fkey FOREIGN KEY (reversal_of_id) REFERENCES public."transaction"(transaction_id);
+ALTER TABLE public."transaction" ADD CONSTRAINT transaction_reversed_amount_check CHECK (reversed_amount >= 0);
+
+
+
+
+-- public.transaction_reversals definition
+
+-- Drop table
+
+-- DROP TABLE public.transaction_reversals;
+
+CREATE TABLE public.transaction_reversals (
+	transaction_reversal_id int8 GENERATED ALWAYS AS IDENTITY NOT NULL,
+	transaction_id int8 NOT NULL,
+	reversal_transaction_id int8 NOT NULL,
+	kind varchar(10) NOT NULL,
+	amount int8 NOT NULL,
+	reason varchar DEFAULT '' NOT NULL,
+	created_by varchar(60) DEFAULT '' NOT NULL,
+	created_at timestamptz DEFAULT now() NOT NULL,
+	CONSTRAINT transaction_reversals_pk PRIMARY KEY (transaction_reversal_id)
+);
+CREATE INDEX transaction_reversals_transaction_idx ON public.transaction_reversals (transaction_id);
+
+
+-- public.transaction_reversals foreign keys
+
+ALTER TABLE public.transaction_reversals ADD CONSTRAINT transaction_reversals_transaction_id_fkey FOREIGN KEY (transaction_id) REFERENCES public."transaction"(transaction_id);
+ALTER TABLE public.transaction_reversals ADD CONSTRAINT transaction_reversals_reversal_transaction_id_fkey FOREIGN KEY (reversal_transaction_id) REFERENCES public."transaction"(transaction_id);
+
+
+
+
+-- public.transfer_batches definition
+
+-- Drop table
+
+-- DROP TABLE public.transfer_batches;
+
+CREATE TABLE public.transfer_batches (
+	transfer_batch_id int8 GENERATED ALWAYS AS IDENTITY NOT NULL,
+	account_id int8 NOT NULL,
+	mode varchar(10) DEFAULT 'partial' NOT NULL,
+	status varchar(20) DEFAULT 'pending' NOT NULL,
+	currency char(3) NOT NULL,
+	total_amount int8 NOT NULL,
+	item_count int4 NOT NULL,
+	success_count int4 DEFAULT 0 NOT NULL,
+	failed_count int4 DEFAULT 0 NOT NULL,
+	error varchar DEFAULT '' NOT NULL,
+	created_by_auth_id int8 DEFAULT 0 NOT NULL,
+	created_by varchar(60) DEFAULT '' NOT NULL,
+	request_id varchar DEFAULT '' NOT NULL,
+	locked_until timestamptz NULL,
+	created_at timestamptz DEFAULT now() NOT NULL,
+	started_at timestamptz NULL,
+	completed_at timestamptz NULL,
+	CONSTRAINT transfer_batches_pk PRIMARY KEY (transfer_batch_id)
+);
+CREATE INDEX transfer_batches_queue_idx ON public.transfer_batches (status) WHERE status IN ('pending', 'processing');
+
+
+-- public.transfer_batches foreign keys
+
+ALTER TABLE public.transfer_batches ADD CONSTRAINT transfer_batches_account_id_fkey FOREIGN KEY (account_id) REFERENCES public.accounts(account_id);
+
+
+
+
+-- public.transfer_batch_items definition
+
+-- Drop table
+
+-- DROP TABLE public.transfer_batch_items;
+
+CREATE TABLE public.transfer_batch_items (
+	transfer_batch_item_id int8 GENERATED ALWAYS AS IDENTITY NOT NULL,
+	transfer_batch_id int8 NOT NULL,
+	row_number int4 NOT NULL,
+	to_account_id int8 NOT NULL,
+	amount int8 NOT NULL,
+	description varchar DEFAULT '' NOT NULL,
+	status varchar(10) DEFAULT 'pending' NOT NULL,
+	transaction_id int8 NULL,
+	error varchar DEFAULT '' NOT NULL,
+	CONSTRAINT transfer_batch_items_pk PRIMARY KEY (transfer_batch_item_id),
+	CONSTRAINT transfer_batch_items_row_unique UNIQUE (transfer_batch_id, row_number)
+);
+
+
+-- public.transfer_batch_items foreign keys
+
+ALTER TABLE public.transfer_batch_items ADD CONSTRAINT transfer_batch_items_batch_id_fkey FOREIGN KEY (transfer_batch_id) REFERENCES public.transfer_batches(transfer_batch_id);
+ALTER TABLE public.transfer_batch_items ADD CONSTRAINT transfer_batch_items_transaction_id_fkey FOREIGN KEY (transaction_id) REFERENCES public."transaction"(transaction_id);
+
+
+
+
+-- public.payment_requests definition
+
+-- Drop table
+
+-- DROP TABLE public.payment_requests;
+
+CREATE TABLE public.payment_requests (
+	payment_request_id int8 GENERATED ALWAYS AS IDENTITY NOT NULL,
+	requester_account_id int8 NOT NULL,
+	payer_account_id int8 NOT NULL,
+	amount int8 NOT NULL,
+	currency char(3) NOT NULL,
+	note varchar DEFAULT '' NOT NULL,
+	status varchar(10) DEFAULT 'pending' NOT NULL,
+	expires_at timestamptz NOT NULL,
+	transaction_id int8 NULL,
+	created_at timestamptz DEFAULT now() NOT NULL,
+	responded_at timestamptz NULL,
+	CONSTRAINT payment_requests_pk PRIMARY KEY (payment_request_id),
+	CONSTRAINT payment_requests_amount_check CHECK (amount > 0),
+	CONSTRAINT payment_requests_self_check CHECK (requester_account_id <> payer_account_id)
+);
+CREATE INDEX payment_requests_payer_idx ON public.payment_requests (payer_account_id);
+CREATE INDEX payment_requests_requester_idx ON public.payment_requests (requester_account_id);
+CREATE INDEX payment_requests_expiry_idx ON public.payment_requests (expires_at) WHERE status = 'pending';
+
+
+-- public.payment_requests foreign keys
+
+ALTER TABLE public.payment_requests ADD CONSTRAINT payment_requests_requester_account_id_fkey FOREIGN KEY (requester_account_id) REFERENCES public.accounts(account_id);
+ALTER TABLE public.payment_requests ADD CONSTRAINT payment_requests_payer_account_id_fkey FOREIGN KEY (payer_account_id) REFERENCES public.accounts(account_id);
+ALTER TABLE public.payment_requests ADD CONSTRAINT payment_requests_transaction_id_fkey FOREIGN KEY (transaction_id) REFERENCES public."transaction"(transaction_id);
+
+
+
+
+-- public.accounts: dana yang disisihkan di pocket (tetap bagian dari balance)
+
+ALTER TABLE public.accounts ADD COLUMN pocket_balance int8 DEFAULT 0 NOT NULL;
+
+
+
+
+-- public.pockets definition
+
+-- Drop table
+
+-- DROP TABLE public.pockets;
+
+CREATE TABLE public.pockets (
+	pocket_id int8 GENERATED ALWAYS AS IDENTITY NOT NULL,
+	account_id int8 NOT NULL,
+	"name" varchar(60) NOT NULL,
+	balance int8 DEFAULT 0 NOT NULL,
+	target_amount int8 DEFAULT 0 NOT NULL,
+	deadline timestamptz NULL,
+	status varchar(10) DEFAULT 'active' NOT NULL,
+	created_at timestamptz DEFAULT now() NOT NULL,
+	updated_at timestamptz DEFAULT now() NOT NULL,
+	closed_at timestamptz NULL,
+	CONSTRAINT pockets_pk PRIMARY KEY (pocket_id),
+	CONSTRAINT pockets_balance_check CHECK (balance >= 0)
+);
+CREATE INDEX pockets_account_idx ON public.pockets (account_id);
+
+
+-- public.pockets foreign keys
+
+ALTER TABLE public.pockets ADD CONSTRAINT pockets_account_id_fkey FOREIGN KEY (account_id) REFERENCES public.accounts(account_id);
+
+
+-- public."transaction": perpindahan internal pocket
+
+ALTER TABLE public."transaction" ADD COLUMN pocket_id int8 NULL;
+ALTER TABLE public."transaction" ADD CONSTRAINT transaction_pocket_id_fkey FOREIGN KEY (pocket_id) REFERENCES public.pockets(pocket_id);
+
+
+
+-- public.interest_products definition
+
+-- Drop table
+
+-- DROP TABLE public.interest_products;
+
+CREATE TABLE public.interest_products (
+	interest_product_id int8 GENERATED ALWAYS AS IDENTITY NOT NULL,
+	"name" varchar(60) NOT NULL,
+	annual_rate numeric(7,4) DEFAULT 0 NOT NULL,
+	bands jsonb NULL,
+	day_count varchar(7) DEFAULT 'ACT/365' NOT NULL,
+	compounding varchar(10) DEFAULT 'monthly' NOT NULL,
+	tax_bps int8 DEFAULT 0 NOT NULL,
+	active bool DEFAULT true NOT NULL,
+	created_at timestamptz DEFAULT now() NOT NULL,
+	updated_at timestamptz DEFAULT now() NOT NULL,
+	CONSTRAINT interest_products_pk PRIMARY KEY (interest_product_id),
+	CONSTRAINT interest_products_tax_check CHECK (tax_bps >= 0 AND tax_bps <= 10000)
+);
+
+
+-- public.accounts: produk bunga tabungan
+
+ALTER TABLE public.accounts ADD COLUMN interest_product_id int8 NULL;
+ALTER TABLE public.accounts ADD CONSTRAINT accounts_interest_product_id_fkey FOREIGN KEY (interest_product_id) REFERENCES public.interest_products(interest_product_id);
+
+
+-- public.interest_postings definition
+
+-- Drop table
+
+-- DROP TABLE public.interest_postings;
+
+CREATE TABLE public.interest_postings (
+	interest_posting_id int8 GENERATED ALWAYS AS IDENTITY NOT NULL,
+	account_id int8 NOT NULL,
+	period_start date NOT NULL,
+	period_end date NOT NULL,
+	accrued numeric(20,6) NOT NULL,
+	gross int8 NOT NULL,
+	tax int8 DEFAULT 0 NOT NULL,
+	net int8 NOT NULL,
+	remainder numeric(20,6) DEFAULT 0 NOT NULL,
+	transaction_id int8 NULL,
+	tax_transaction_id int8 NULL,
+	created_at timestamptz DEFAULT now() NOT NULL,
+	CONSTRAINT interest_postings_pk PRIMARY KEY (interest_posting_id),
+	CONSTRAINT interest_postings_period_key UNIQUE (account_id, period_end)
+);
+
+
+-- public.interest_postings foreign keys
+
+ALTER TABLE public.interest_postings ADD CONSTRAINT interest_postings_account_id_fkey FOREIGN KEY (account_id) REFERENCES public.accounts(account_id);
+ALTER TABLE public.interest_postings ADD CONSTRAINT interest_postings_transaction_id_fkey FOREIGN KEY (transaction_id) REFERENCES public."transaction"(transaction_id);
+ALTER TABLE public.interest_postings ADD CONSTRAINT interest_postings_tax_transaction_id_fkey FOREIGN KEY (tax_transaction_id) REFERENCES public."transaction"(transaction_id);
+
+
+-- public.interest_accruals definition
+
+-- Drop table
+
+-- DROP TABLE public.interest_accruals;
+
+CREATE TABLE public.interest_accruals (
+	account_id int8 NOT NULL,
+	accrual_date date NOT NULL,
+	interest_product_id int8 NOT NULL,
+	balance int8 NOT NULL,
+	annual_rate numeric(7,4) NOT NULL,
+	bands jsonb NULL,
+	day_count varchar(7) NOT NULL,
+	amount numeric(20,6) NOT NULL,
+	interest_posting_id int8 NULL,
+	created_at timestamptz DEFAULT now() NOT NULL,
+	CONSTRAINT interest_accruals_pk PRIMARY KEY (account_id, accrual_date)
+);
+CREATE INDEX interest_accruals_unposted_idx ON public.interest_accruals (account_id) WHERE interest_posting_id IS NULL;
+
+
+-- public.interest_accruals foreign keys
+
+ALTER TABLE public.interest_accruals ADD CONSTRAINT interest_accruals_account_id_fkey FOREIGN KEY (account_id) REFERENCES public.accounts(account_id);
+ALTER TABLE public.interest_accruals ADD CONSTRAINT interest_accruals_interest_product_id_fkey FOREIGN KEY (interest_product_id) REFERENCES public.interest_products(interest_product_id);
+ALTER TABLE public.interest_accruals ADD CONSTRAINT interest_accruals_interest_posting_id_fkey FOREIGN KEY (interest_posting_id) REFERENCES public.interest_postings(interest_posting_id);
+
+
+
+-- public.fee_rules definition
+
+-- Drop table
+
+-- DROP TABLE public.fee_rules;
+
+CREATE TABLE public.fee_rules (
+	fee_rule_id int8 GENERATED ALWAYS AS IDENTITY NOT NULL,
+	"name" varchar(60) NOT NULL,
+	operation varchar(20) NOT NULL,
+	tier varchar(20) DEFAULT '' NOT NULL,
+	currency char(3) DEFAULT 'IDR' NOT NULL,
+	flat_amount int8 DEFAULT 0 NOT NULL,
+	percent_bps int8 DEFAULT 0 NOT NULL,
+	min_fee int8 DEFAULT 0 NOT NULL,
+	max_fee int8 NULL,
+	free_per_month int8 DEFAULT 0 NOT NULL,
+	active bool DEFAULT true NOT NULL,
+	created_at timestamptz DEFAULT now() NOT NULL,
+	updated_at timestamptz DEFAULT now() NOT NULL,
+	CONSTRAINT fee_rules_pk PRIMARY KEY (fee_rule_id),
+	CONSTRAINT fee_rules_amount_check CHECK (flat_amount >= 0 AND percent_bps BETWEEN 0 AND 10000 AND min_fee >= 0 AND free_per_month >= 0)
+);
+CREATE INDEX fee_rules_lookup_idx ON public.fee_rules (operation, currency, tier) WHERE active;
+
+
+-- public."transaction": biaya yang terhubung ke transaksi asal
+
+ALTER TABLE public."transaction" ADD COLUMN fee_of_id int8 NULL;
+ALTER TABLE public."transaction" ADD CONSTRAINT transaction_fee_of_id_fkey FOREIGN KEY (fee_of_id) REFERENCES public."transaction"(transaction_id);
+
+
+
+-- public.transfer_inquiries definition
+
+-- Drop table
+
+-- DROP TABLE public.transfer_inquiries;
+
+CREATE TABLE public.transfer_inquiries (
+	transfer_inquiry_id varchar(32) NOT NULL,
+	from_account_id int8 NOT NULL,
+	to_account_id int8 NOT NULL,
+	amount int8 NOT NULL,
+	fee int8 DEFAULT 0 NOT NULL,
+	currency char(3) NOT NULL,
+	credit int8 NOT NULL,
+	credit_currency char(3) NOT NULL,
+	fx_quote_id varchar(32) NULL,
+	expires_at timestamptz NOT NULL,
+	used_at timestamptz NULL,
+	transaction_id int8 NULL,
+	created_at timestamptz DEFAULT now() NOT NULL,
+	CONSTRAINT transfer_inquiries_pk PRIMARY KEY (transfer_inquiry_id),
+	CONSTRAINT transfer_inquiries_amount_check CHECK (amount > 0)
+);
+
+
+-- public.transfer_inquiries foreign keys
+
+ALTER TABLE public.transfer_inquiries ADD CONSTRAINT transfer_inquiries_from_account_id_fkey FOREIGN KEY (from_account_id) REFERENCES public.accounts(account_id);
+ALTER TABLE public.transfer_inquiries ADD CONSTRAINT transfer_inquiries_to_account_id_fkey FOREIGN KEY (to_account_id) REFERENCES public.accounts(account_id);
+ALTER TABLE public.transfer_inquiries ADD CONSTRAINT transfer_inquiries_fx_quote_id_fkey FOREIGN KEY (fx_quote_id) REFERENCES public.fx_quotes(fx_quote_id);
+ALTER TABLE public.transfer_inquiries ADD CONSTRAINT transfer_inquiries_transaction_id_fkey FOREIGN KEY (transaction_id) REFERENCES public."transaction"(transaction_id);
+
+
+
+-- public.beneficiaries definition
+
+-- Drop table
+
+-- DROP TABLE public.beneficiaries;
+
+CREATE TABLE public.beneficiaries (
+	beneficiary_id int8 GENERATED ALWAYS AS IDENTITY NOT NULL,
+	account_id int8 NOT NULL,
+	beneficiary_account_id int8 NOT NULL,
+	nickname varchar(60) NOT NULL,
+	favorite bool DEFAULT false NOT NULL,
+	transfer_count int8 DEFAULT 0 NOT NULL,
+	last_used_at timestamptz NULL,
+	created_at timestamptz DEFAULT now() NOT NULL,
+	updated_at timestamptz DEFAULT now() NOT NULL,
+	CONSTRAINT beneficiaries_pk PRIMARY KEY (beneficiary_id),
+	CONSTRAINT beneficiaries_account_target_key UNIQUE (account_id, beneficiary_account_id)
+);
+
+
+-- public.beneficiaries foreign keys
+
+ALTER TABLE public.beneficiaries ADD CONSTRAINT beneficiaries_account_id_fkey FOREIGN KEY (account_id) REFERENCES public.accounts(account_id);
+ALTER TABLE public.beneficiaries ADD CONSTRAINT beneficiaries_beneficiary_account_id_fkey FOREIGN KEY (beneficiary_account_id) REFERENCES public.accounts(account_id);
+
+
+-- public.account_members definition
+-- auths.account_id tetap menjadi akun utama (home) login; akses ke akun lain lewat tabel ini
+
+-- Drop table
+
+-- DROP TABLE public.account_members;
+
+CREATE TABLE public.account_members (
+	account_member_id int8 GENERATED ALWAYS AS IDENTITY NOT NULL,
+	account_id int8 NOT NULL,
+	auth_id int8 NOT NULL,
+	permission varchar(20) NOT NULL,
+	transact_limit int8 NULL,
+	created_at timestamptz DEFAULT now() NOT NULL,
+	updated_at timestamptz DEFAULT now() NOT NULL,
+	CONSTRAINT account_members_pk PRIMARY KEY (account_member_id),
+	CONSTRAINT account_members_account_auth_key UNIQUE (account_id, auth_id),
+	CONSTRAINT account_members_permission_check CHECK (permission IN ('view', 'transact_limited', 'transact', 'manage')),
+	CONSTRAINT account_members_limit_check CHECK (permission <> 'transact_limited' OR transact_limit > 0)
+);
+CREATE INDEX account_members_auth_id_idx ON public.account_members USING btree (auth_id);
+
+
+-- public.account_members foreign keys
+
+ALTER TABLE public.account_members ADD CONSTRAINT account_members_account_id_fkey FOREIGN KEY (account_id) REFERENCES public.accounts(account_id);
+ALTER TABLE public.account_members ADD CONSTRAINT account_members_auth_id_fkey FOREIGN KEY (auth_id) REFERENCES public.auths(auth_id);
+
+-- Pemilik akun yang sudah ada menjadi member manage
+INSERT INTO public.account_members (account_id, auth_id, permission)
+SELECT account_id, auth_id, 'manage' FROM public.auths
+ON CONFLICT (account_id, auth_id) DO NOTHING;
+
+
+-- public.account_invites definition
+
+-- Drop table
+
+-- DROP TABLE public.account_invites;
+
+CREATE TABLE public.account_invites (
+	account_invite_id int8 GENERATED ALWAYS AS IDENTITY NOT NULL,
+	account_id int8 NOT NULL,
+	auth_id int8 NOT NULL,
+	invited_by_auth_id int8 NOT NULL,
+	permission varchar(20) NOT NULL,
+	transact_limit int8 NULL,
+	status varchar(20) DEFAULT 'pending'::character varying NOT NULL,
+	expires_at timestamptz NOT NULL,
+	created_at timestamptz DEFAULT now() NOT NULL,
+	responded_at timestamptz NULL,
+	CONSTRAINT account_invites_pk PRIMARY KEY (account_invite_id)
+);
+CREATE INDEX account_invites_auth_id_status_idx ON public.account_invites USING btree (auth_id, status);
+
+
+-- public.account_invites foreign keys
+
+ALTER TABLE public.account_invites ADD CONSTRAINT account_invites_account_id_fkey FOREIGN KEY (account_id) REFERENCES public.accounts(account_id);
+ALTER TABLE public.account_invites ADD CONSTRAINT account_invites_auth_id_fkey FOREIGN KEY (auth_id) REFERENCES public.auths(auth_id);
+ALTER TABLE public.account_invites ADD CONSTRAINT account_invites_invited_by_auth_id_fkey FOREIGN KEY (invited_by_auth_id) REFERENCES public.auths(auth_id);
+
+
+-- public.balance_snapshots definition
+
+-- Drop table
+
+-- DROP TABLE public.balance_snapshots;
+
+CREATE TABLE public.balance_snapshots (
+	account_id int8 NOT NULL,
+	snapshot_date date NOT NULL,
+	closing_balance int8 NOT NULL,
+	created_at timestamptz DEFAULT now() NOT NULL,
+	CONSTRAINT balance_snapshots_pk PRIMARY KEY (account_id, snapshot_date)
+);
+
+
+-- public.balance_snapshots foreign keys
+
+ALTER TABLE public.balance_snapshots ADD CONSTRAINT balance_snapshots_account_id_fkey FOREIGN KEY (account_id) REFERENCES public.accounts(account_id);
+
+
+-- public.reconciliation_runs definition
+
+-- Drop table
+
+-- DROP TABLE public.reconciliation_runs;
+
+CREATE TABLE public.reconciliation_runs (
+	reconciliation_run_id int8 GENERATED ALWAYS AS IDENTITY NOT NULL,
+	"trigger" varchar(20) NOT NULL,
+	status varchar(20) NOT NULL,
+	accounts_checked int4 DEFAULT 0 NOT NULL,
+	discrepancy_count int4 DEFAULT 0 NOT NULL,
+	total_drift float8 DEFAULT 0 NOT NULL,
+	tickets_opened int4 DEFAULT 0 NOT NULL,
+	error text DEFAULT ''::text NOT NULL,
+	started_at timestamptz DEFAULT now() NOT NULL,
+	finished_at timestamptz NULL,
+	CONSTRAINT reconciliation_runs_pk PRIMARY KEY (reconciliation_run_id)
+);
+
+
+-- public.correction_tickets definition
+
+-- Drop table
+
+-- DROP TABLE public.correction_tickets;
+
+CREATE TABLE public.correction_tickets (
+	correction_ticket_id int8 GENERATED ALWAYS AS IDENTITY NOT NULL,
+	account_id int8 NOT NULL,
+	field varchar(20) NOT NULL,
+	stored float8 NOT NULL,
+	expected int8 NOT NULL,
+	difference float8 NOT NULL,
+	reconciliation_run_id int8 NOT NULL,
+	status varchar(20) DEFAULT 'open'::character varying NOT NULL,
+	note text DEFAULT ''::text NOT NULL,
+	resolved_by_auth_id int8 NULL,
+	created_at timestamptz DEFAULT now() NOT NULL,
+	updated_at timestamptz DEFAULT now() NOT NULL,
+	resolved_at timestamptz NULL,
+	CONSTRAINT correction_tickets_pk PRIMARY KEY (correction_ticket_id)
+);
+-- Satu tiket open per akun dan kolom saldo
+CREATE UNIQUE INDEX correction_tickets_open_key ON public.correction_tickets USING btree (account_id, field) WHERE status = 'open';
+
+
+-- public.correction_tickets foreign keys
+
+ALTER TABLE public.correction_tickets ADD CONSTRAINT correction_tickets_account_id_fkey FOREIGN KEY (account_id) REFERENCES public.accounts(account_id);
+ALTER TABLE public.correction_tickets ADD CONSTRAINT correction_tickets_reconciliation_run_id_fkey FOREIGN KEY (reconciliation_run_id) REFERENCES public.reconciliation_runs(reconciliation_run_id);
+ALTER TABLE public.correction_tickets ADD CONSTRAINT correction_tickets_resolved_by_auth_id_fkey FOREIGN KEY (resolved_by_auth_id) REFERENCES public.auths(auth_id);
+
+
+-- public.reconciliation_discrepancies definition
+
+-- Drop table
+
+-- DROP TABLE public.reconciliation_discrepancies;
+
+CREATE TABLE public.reconciliation_discrepancies (
+	reconciliation_discrepancy_id int8 GENERATED ALWAYS AS IDENTITY NOT NULL,
+	reconciliation_run_id int8 NOT NULL,
+	account_id int8 NOT NULL,
+	field varchar(20) NOT NULL,
+	stored float8 NOT NULL,
+	expected int8 NOT NULL,
+	difference float8 NOT NULL,
+	movement_count int4 DEFAULT 0 NOT NULL,
+	correction_ticket_id int8 NULL,
+	created_at timestamptz DEFAULT now() NOT NULL,
+	CONSTRAINT reconciliation_discrepancies_pk PRIMARY KEY (reconciliation_discrepancy_id)
+);
+CREATE INDEX reconciliation_discrepancies_run_idx ON public.reconciliation_discrepancies USING btree (reconciliation_run_id);
+
+
+-- public.reconciliation_discrepancies foreign keys
+
+ALTER TABLE public.reconciliation_discrepancies ADD CONSTRAINT reconciliation_discrepancies_run_id_fkey FOREIGN KEY (reconciliation_run_id) REFERENCES public.reconciliation_runs(reconciliation_run_id);
+ALTER TABLE public.reconciliation_discrepancies ADD CONSTRAINT reconciliation_discrepancies_account_id_fkey FOREIGN KEY (account_id) REFERENCES public.accounts(account_id);
+ALTER TABLE public.reconciliation_discrepancies ADD CONSTRAINT reconciliation_discrepancies_ticket_id_fkey FOREIGN KEY (correction_ticket_id) REFERENCES public.correction_tickets(correction_ticket_id);
+
+
+-- Optimistic concurrency: version naik otomatis pada setiap UPDATE dan dipakai sebagai ETag
+
+ALTER TABLE public.accounts ADD version int8 DEFAULT 1 NOT NULL;
+ALTER TABLE public.transaction_categories ADD version int8 DEFAULT 1 NOT NULL;
+
+CREATE OR REPLACE FUNCTION public.bump_version() RETURNS trigger AS $$
+BEGIN
+	NEW.version := OLD.version + 1;
+	RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER accounts_bump_version BEFORE UPDATE ON public.accounts
+	FOR EACH ROW EXECUTE FUNCTION public.bump_version();
+CREATE TRIGGER transaction_categories_bump_version BEFORE UPDATE ON public.transaction_categories
+	FOR EACH ROW EXECUTE FUNCTION public.bump_version();
+
+
+-- Nomor rekening publik dengan check digit; akun lama diisi lewat `go run . account-numbers`
+
+ALTER TABLE public.accounts ADD account_number varchar(20) NULL;
+CREATE UNIQUE INDEX accounts_account_number_key ON public.accounts USING btree (account_number);
+
+
+-- Tiket sekali pakai untuk membuka /stream dari browser tanpa JWT di URL
+
+CREATE TABLE public.stream_tickets (
+	stream_ticket_id varchar(32) NOT NULL,
+	auth_id int8 NOT NULL,
+	account_id int8 NOT NULL,
+	expires_at timestamptz NOT NULL,
+	created_at timestamptz DEFAULT now() NOT NULL,
+	CONSTRAINT stream_tickets_pk PRIMARY KEY (stream_ticket_id)
+);
+
+ALTER TABLE public.stream_tickets ADD CONSTRAINT stream_tickets_auth_id_fkey FOREIGN KEY (auth_id) REFERENCES public.auths(auth_id);
+ALTER TABLE public.stream_tickets ADD CONSTRAINT stream_tickets_account_id_fkey FOREIGN KEY (account_id) REFERENCES public.accounts(account_id);
@@ -0,0 +1,122 @@
+package fee
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"task-golang-db/limit"
+	"task-golang-db/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrInvalidRule = errors.New("fee rule amounts must be >= 0, percent_bps <= 10000 and max_fee >= min_fee")
+
+// Quote adalah biaya sebuah operasi sebelum dikonfirmasi. Untuk transfer Total adalah
+// nominal yang didebit (amount + fee); untuk top-up Total adalah nominal bersih yang masuk.
+type Quote struct {
+	Operation     string `json:"operation"`
+	Amount        int64  `json:"amount"`
+	Fee           int64  `json:"fee"`
+	Total         int64  `json:"total"`
+	Currency      string `json:"currency"`
+	FeeRuleID     *int64 `json:"fee_rule_id"`
+	FreeRemaining *int64 `json:"free_remaining"`
+}
+
+// RevenueAccountID adalah akun sistem penampung biaya (FEE_REVENUE_ACCOUNT_ID)
+func RevenueAccountID() int64 {
+	id, _ := strconv.ParseInt(os.Getenv("FEE_REVENUE_ACCOUNT_ID"), 10, 64)
+	return id
+}
+
+// Validate mengecek nominal aturan biaya sebelum disimpan
+func Validate(rule model.FeeRule) error {
+	if rule.FlatAmount < 0 || rule.PercentBps < 0 || rule.PercentBps > 10000 || rule.MinFee < 0 || rule.FreePerMonth < 0 {
+		return ErrInvalidRule
+	}
+	if rule.MaxFee != nil && *rule.MaxFee < rule.MinFee {
+		return ErrInvalidRule
+	}
+	return nil
+}
+
+// Match mencari aturan aktif untuk operasi, mata uang, dan tier akun. Aturan untuk
+// tier akun didahulukan dari aturan semua tier; nil berarti operasi gratis.
+func Match(db *gorm.DB, account model.Account, operation string) (*model.FeeRule, error) {
+	var rule model.FeeRule
+	result := db.Where("active AND operation = ? AND currency = ? AND (tier = ? OR tier = '')", operation, account.Currency, account.Tier).
+		Order("tier = '', fee_rule_id").
+		Limit(1).
+		Find(&rule)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, result.Error
+	}
+	return &rule, nil
+}
+
+// Amount menghitung biaya aturan untuk nominal amount (persentase dibulatkan ke atas)
+func Amount(rule model.FeeRule, amount int64) int64 {
+	fee := rule.FlatAmount + (amount*rule.PercentBps+9999)/10000
+	if fee < rule.MinFee {
+		fee = rule.MinFee
+	}
+	if rule.MaxFee != nil && fee > *rule.MaxFee {
+		fee = *rule.MaxFee
+	}
+	return fee
+}
+
+// Compute menghitung biaya operasi akun. Jatah gratis bulanan dihitung dari pemakaian
+// limit bulan berjalan, sehingga harus dipanggil sebelum limit.Consume.
+func Compute(db *gorm.DB, account model.Account, operation string, amount int64) (Quote, error) {
+	quotes, err := ComputeMany(db, account, operation, []int64{amount})
+	if err != nil {
+		return Quote{}, err
+	}
+	return quotes[0], nil
+}
+
+// ComputeMany menghitung biaya beberapa operasi seolah dieksekusi berurutan (untuk batch)
+func ComputeMany(db *gorm.DB, account model.Account, operation string, amounts []int64) ([]Quote, error) {
+	rule, err := Match(db, account, operation)
+	if err != nil {
+		return nil, err
+	}
+
+	var used int64
+	if rule != nil && rule.FreePerMonth > 0 {
+		usage, err := limit.CurrentUsage(db, account.AccountID, operation, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		used = usage.MonthlyCount
+	}
+
+	quotes := make([]Quote, 0, len(amounts))
+	for _, amount := range amounts {
+		quote := Quote{Operation: operation, Amount: amount, Currency: account.Currency}
+		if rule != nil {
+			quote.FeeRuleID = &rule.FeeRuleID
+			if rule.FreePerMonth > 0 {
+				free := rule.FreePerMonth - used
+				if free < 0 {
+					free = 0
+				}
+				quote.FreeRemaining = &free
+			}
+			if used >= rule.FreePerMonth {
+				quote.Fee = Amount(*rule, amount)
+			}
+		}
+
+		quote.Total = amount + quote.Fee
+		if operation == model.OperationTopUp {
+			quote.Total = amount - quote.Fee
+		}
+		quotes = append(quotes, quote)
+		used++
+	}
+	return quotes, nil
+}
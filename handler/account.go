@@ -1,14 +1,28 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
+	"task-golang-db/accountno"
+	"task-golang-db/audit"
+	"task-golang-db/beneficiary"
+	"task-golang-db/currency"
+	"task-golang-db/fee"
+	"task-golang-db/ledger"
+	"task-golang-db/limit"
 	"task-golang-db/model"
+	"task-golang-db/realtime"
+	"task-golang-db/referral"
+	"task-golang-db/webhook"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+var errForbidden = errors.New("forbidden")
+
 type AccountInterface interface {
 	Create(*gin.Context)
 	Read(*gin.Context)
@@ -18,8 +32,15 @@ type AccountInterface interface {
 	My(*gin.Context)
 	TopUp(*gin.Context)
 	Balance(*gin.Context)
+	BalanceHistory(*gin.Context)
+	BalanceAsOf(*gin.Context)
 	Transfer(*gin.Context)
+	TransferInquiry(*gin.Context)
+	TransferConfirm(*gin.Context)
 	Mutation(*gin.Context)
+	UpdateStatus(*gin.Context)
+	StatusHistory(*gin.Context)
+	Close(*gin.Context)
 }
 
 type accountImplement struct {
@@ -35,17 +56,73 @@ func NewAccount(db *gorm.DB) AccountInterface {
 
 // Implementasi metode Create (contoh implementasi)
 func (a *accountImplement) Create(c *gin.Context) {
-	var request model.Account
-	if err := c.BindJSON(&request); err != nil {
+	var payload struct {
+		model.Account
+		// ReferrerCode adalah kode referral akun yang mengajak (opsional)
+		ReferrerCode string `json:"referrer_code"`
+	}
+	if err := c.BindJSON(&payload); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	request := payload.Account
+
+	request.Currency = currency.Normalize(request.Currency)
+	if !currency.Supported(request.Currency) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported currency"})
+		return
+	}
+
+	// Akun baru berstatus pending sampai login (auth) didaftarkan
+	request.Status = model.AccountStatusPending
+	request.StatusReason = ""
+	request.HeldBalance = 0
+	request.PocketBalance = 0
+	request.Version = 0
+	request.Tier = model.AccountTierBasic
+	request.ReferralAccountID = nil
+	request.InterestProductID = nil
+
+	code, err := referral.GenerateCode()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	request.ReferralCode = code
 
-	if err := a.db.Create(&request).Error; err != nil {
+	// Nomor rekening publik; account_id tetap dipakai internal
+	request.AccountNumber, err = accountno.Generate(a.db)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	err = a.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&request).Error; err != nil {
+			return err
+		}
+		if payload.ReferrerCode != "" {
+			if _, err := referral.Register(tx, &request, payload.ReferrerCode); err != nil {
+				return err
+			}
+		}
+		return audit.Record(tx, audit.ActorFromContext(c), audit.Entry{
+			Action:   audit.ActionCreate,
+			Entity:   "account",
+			EntityID: request.AccountID,
+			After:    request,
+		})
+	})
+	if err != nil {
+		switch err {
+		case referral.ErrCodeInvalid, referral.ErrReferrerInactive, referral.ErrReferrerCapped:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Account created successfully",
 		"account": request,
@@ -54,47 +131,141 @@ func (a *accountImplement) Create(c *gin.Context) {
 
 // Implementasi metode Read
 func (a *accountImplement) Read(c *gin.Context) {
-	accountID := c.Param("id")
+	accountID, ok := accountParam(c, a.db)
+	if !ok {
+		return
+	}
 	var account model.Account
 	if err := a.db.First(&account, accountID).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
 		return
 	}
+	if notModified(c, account.Version) {
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"account": account,
 	})
 }
 
-// Implementasi metode Update
+// forbidOtherAccount menolak (403) jika :id bukan akun terpilih request dan login bukan
+// admin. PermissionMiddleware hanya memeriksa akun terpilih (X-Account-ID).
+func forbidOtherAccount(c *gin.Context, accountID int64) bool {
+	if accountID != c.GetInt64("account_id") && c.GetString("role") != model.RoleAdmin {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		return true
+	}
+	return false
+}
+
+// Implementasi metode Update: nasabah hanya bisa mengganti nama; balance hanya
+// bisa dikoreksi admin (perpindahan saldo nasabah lewat topup/transfer)
 func (a *accountImplement) Update(c *gin.Context) {
-	accountID := c.Param("id")
+	accountID, ok := accountParam(c, a.db)
+	if !ok {
+		return
+	}
+	if forbidOtherAccount(c, accountID) {
+		return
+	}
 	var account model.Account
 	if err := a.db.First(&account, accountID).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
 		return
 	}
 
-	var request model.Account
+	if preconditionFailed(c, account.Version) {
+		return
+	}
+
+	var request struct {
+		Name    string   `json:"name"`
+		Balance *float64 `json:"balance"`
+	}
 	if err := c.BindJSON(&request); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if request.Balance != nil && *request.Balance != account.Balance && c.GetString("role") != model.RoleAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admin can change balance"})
+		return
+	}
 
+	before := account
 	account.Name = request.Name
-	account.Balance = request.Balance
-	if err := a.db.Save(&account).Error; err != nil {
+	if request.Balance != nil {
+		account.Balance = *request.Balance
+	}
+	err := a.db.Transaction(func(tx *gorm.DB) error {
+		// Update hanya berhasil jika version belum diubah request lain sejak dibaca
+		result := tx.Model(&account).Where("version = ?", before.Version).Updates(map[string]interface{}{
+			"name":    account.Name,
+			"balance": account.Balance,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errVersionConflict
+		}
+		account.Version = before.Version + 1
+		return audit.Record(tx, audit.ActorFromContext(c), audit.Entry{
+			Action:   audit.ActionUpdate,
+			Entity:   "account",
+			EntityID: account.AccountID,
+			Before:   before,
+			After:    account,
+		})
+	})
+	if err == errVersionConflict {
+		var current model.Account
+		a.db.Select("version").First(&current, account.AccountID)
+		abortVersionConflict(c, current.Version)
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	c.Header("ETag", etag(account.Version))
 	c.JSON(http.StatusOK, gin.H{"message": "Account updated successfully"})
 }
 
 // Implementasi metode Delete
 func (a *accountImplement) Delete(c *gin.Context) {
-	accountID := c.Param("id")
-	if err := a.db.Delete(&model.Account{}, accountID).Error; err != nil {
+	accountID, ok := accountParam(c, a.db)
+	if !ok {
+		return
+	}
+	if forbidOtherAccount(c, accountID) {
+		return
+	}
+	var account model.Account
+	if err := a.db.First(&account, accountID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+		return
+	}
+
+	// Soft delete: riwayat transaksi dan auth tetap tersimpan
+	if account.Status != model.AccountStatusClosed {
+		c.JSON(http.StatusConflict, gin.H{"error": errAccountNotClosed.Error()})
+		return
+	}
+
+	err := a.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&model.Account{}, account.AccountID).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, audit.ActorFromContext(c), audit.Entry{
+			Action:   audit.ActionDelete,
+			Entity:   "account",
+			EntityID: account.AccountID,
+			Before:   account,
+		})
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -126,6 +297,9 @@ func (a *accountImplement) My(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
 		return
 	}
+	if notModified(c, account.Version) {
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{"account": account})
 }
@@ -133,33 +307,119 @@ func (a *accountImplement) My(c *gin.Context) {
 // Implementasi metode TopUp
 func (a *accountImplement) TopUp(c *gin.Context) {
 	var request struct {
-		AccountID int64   `json:"account_id" binding:"required"`
-		Amount    float64 `json:"amount" binding:"required,gt=0"`
+		Account accountno.Ref `json:"account_id" binding:"required"`
+		Amount  int64         `json:"amount" binding:"required,gt=0"`
 	}
 
 	if err := c.BindJSON(&request); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	accountID, ok := resolveAccount(c, a.db, request.Account)
+	if !ok {
+		return
+	}
 
 	var account model.Account
-	if err := a.db.First(&account, request.AccountID).Error; err != nil {
+	var quote fee.Quote
+	err := a.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&account, accountID).Error; err != nil {
+			return err
+		}
+		if err := ledger.CheckMoneyAllowed(account); err != nil {
+			return err
+		}
+
+		// Biaya top-up dipotong dari nominal yang masuk, dihitung sebelum limit.Consume
+		var err error
+		quote, err = fee.Compute(tx, account, model.OperationTopUp, request.Amount)
+		if err != nil {
+			return err
+		}
+		if quote.Total <= 0 {
+			return ledger.ErrFeeExceedsAmount
+		}
+		if err := limit.Consume(tx, account, model.OperationTopUp, request.Amount); err != nil {
+			return err
+		}
+
+		before := account
+		account.Balance += float64(request.Amount)
+		if err := tx.Save(&account).Error; err != nil {
+			return err
+		}
+
+		// Top-up dicatat di tabel transaction supaya mutasi dan rekening koran lengkap
+		categoryID, err := ledger.CategoryID(tx, ledger.CategoryTopUp)
+		if err != nil {
+			return err
+		}
+		transaction := model.Transaction{
+			TransactionCategoryID: &categoryID,
+			AccountID:             account.AccountID,
+			ToAccountId:           account.AccountID,
+			ToAccountNumber:       account.AccountNumber,
+			Amount:                request.Amount,
+			Currency:              account.Currency,
+			TransactionDate:       time.Now().Format("2006-01-02 15:04:05"),
+		}
+		if err := tx.Create(&transaction).Error; err != nil {
+			return err
+		}
+		if err := realtime.Publish(tx, account.AccountID, model.AccountEventMutationCreated, transaction); err != nil {
+			return err
+		}
+		if _, err := ledger.ChargeFee(tx, &account, quote, transaction); err != nil {
+			return err
+		}
+
+		if err := audit.Record(tx, audit.ActorFromContext(c), audit.Entry{
+			Action:   audit.ActionTopUp,
+			Entity:   "account",
+			EntityID: account.AccountID,
+			Before:   before,
+			After:    account,
+		}); err != nil {
+			return err
+		}
+
+		// Top-up pertama yang memenuhi syarat membayar bonus referral
+		if err := referral.OnTopUp(tx, &account, request.Amount, audit.ActorFromContext(c)); err != nil {
+			return err
+		}
+
+		if err := ledger.PublishBalance(tx, account); err != nil {
+			return err
+		}
+
+		// Event ditulis ke outbox dalam transaksi yang sama
+		return webhook.Enqueue(tx, webhook.EventTopUpCompleted, gin.H{
+			"account_id": account.AccountID,
+			"amount":     request.Amount,
+			"fee":        quote.Fee,
+			"balance":    account.Balance,
+		})
+	})
+	if err != nil {
+		var blocked ledger.AccountBlockedError
+		var exceeded limit.Error
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+		} else if errors.As(err, &blocked) {
+			c.JSON(http.StatusForbidden, gin.H{"error": blocked.Error()})
+		} else if errors.As(err, &exceeded) {
+			abortLimitError(c, exceeded)
+		} else if err == ledger.ErrFeeExceedsAmount {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
 		} else {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		}
 		return
 	}
 
-	account.Balance += request.Amount
-	if err := a.db.Save(&account).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Top-up successful",
+		"fee":     quote.Fee,
 		"balance": account.Balance,
 	})
 }
@@ -182,69 +442,131 @@ func (a *accountImplement) Balance(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"balance": account.Balance})
+	var pockets []model.Pocket
+	if err := a.db.Where("account_id = ? AND status = ?", account.AccountID, model.PocketActive).Order("pocket_id").Find(&pockets).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	pocketBalances := make([]gin.H, 0, len(pockets))
+	for _, pocket := range pockets {
+		pocketBalances = append(pocketBalances, gin.H{
+			"pocket_id":     pocket.PocketID,
+			"name":          pocket.Name,
+			"balance":       pocket.Balance,
+			"target_amount": pocket.TargetAmount,
+			"progress":      pocket.Progress(),
+		})
+	}
+
+	// balance adalah total termasuk pocket; main_balance adalah saldo di luar pocket
+	c.JSON(http.StatusOK, gin.H{
+		"balance":      account.Balance,
+		"main_balance": account.Balance - account.PocketBalance,
+		"pocket_total": account.PocketBalance,
+		"pockets":      pocketBalances,
+		"held":         account.HeldBalance,
+		"available":    account.Available(),
+		"currency":     account.Currency,
+		"minor_units":  currency.MinorUnits(account.Currency),
+	})
 }
 
 // Implementasi metode Transfer
 func (a *accountImplement) Transfer(c *gin.Context) {
 	AccountID := c.GetInt64("account_id")
 	payload := struct {
-		ToAccountID int64 `json:"to_account_id"`
-		Amount      int64 `json:"amount"`
+		ToAccount     accountno.Ref `json:"to_account_id"`
+		BeneficiaryID int64         `json:"beneficiary_id"`
+		Amount        int64         `json:"amount"`
+		QuoteID       string        `json:"quote_id"`
+		// SaveBeneficiary menyimpan penerima ke daftar beneficiary setelah transfer berhasil
+		SaveBeneficiary bool `json:"save_beneficiary"`
 	}{}
 
 	if err := c.BindJSON(&payload); err != nil {
 		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-
-	// Fetch the current and target accounts
-	var senderAccount model.Account
-	var receiverAccount model.Account
-
-	if err := a.db.First(&senderAccount, AccountID).Error; err != nil {
-		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Sender account not found"})
+	if memberLimitExceeded(c, payload.Amount) {
 		return
 	}
 
-	if err := a.db.First(&receiverAccount, payload.ToAccountID).Error; err != nil {
-		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Target account not found"})
-		return
+	var toAccountID int64
+	if payload.ToAccount != "" {
+		var ok bool
+		if toAccountID, ok = resolveAccount(c, a.db, payload.ToAccount); !ok {
+			return
+		}
 	}
-
-	// Check balance and update if sufficient
-	if senderAccount.Balance < float64(payload.Amount) {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Insufficient balance"})
-		return
+	if payload.BeneficiaryID != 0 {
+		beneficiaryAccountID, err := beneficiary.Resolve(a.db, AccountID, payload.BeneficiaryID)
+		if err != nil {
+			abortBeneficiaryError(c, err)
+			return
+		}
+		if toAccountID != 0 && toAccountID != beneficiaryAccountID {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "to_account_id does not match beneficiary"})
+			return
+		}
+		toAccountID = beneficiaryAccountID
 	}
 
-	senderAccount.Balance -= float64(payload.Amount)
-	receiverAccount.Balance += float64(payload.Amount)
-
-	if err := a.db.Save(&senderAccount).Error; err != nil || a.db.Save(&receiverAccount).Error != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to transfer balance"})
+	var result ledger.TransferResult
+	err := a.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		result, err = ledger.Transfer(tx, ledger.TransferRequest{
+			FromAccountID: AccountID,
+			ToAccountID:   toAccountID,
+			Amount:        payload.Amount,
+			QuoteID:       payload.QuoteID,
+			Actor:         audit.ActorFromContext(c),
+		})
+		if err != nil {
+			return err
+		}
+		return beneficiary.Touch(tx, AccountID, result.Receiver, payload.SaveBeneficiary)
+	})
+	if err != nil {
+		abortTransferError(c, err)
 		return
 	}
 
-	// Convert AccountID and ToAccountID to int8
-	fromAccountID := int8(AccountID)
-	toAccountID := int8(payload.ToAccountID)
-	amount := int8(payload.Amount)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Transfer successful",
+		"fee":     result.Fee,
+	})
+}
 
-	// Create transaction record
-	transaction := model.Transaction{
-		AccountID:       fromAccountID,
-		FromAccountId:   fromAccountID,
-		ToAccountId:     toAccountID,
-		Amount:          amount,
-		TransactionDate: time.Now().Format("2006-01-02 15:04:05"), // format sebagai string
+// abortTransferError memetakan error dari ledger.Transfer ke response HTTP
+func abortTransferError(c *gin.Context, err error) {
+	var blocked ledger.AccountBlockedError
+	if errors.As(err, &blocked) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": blocked.Error()})
+		return
 	}
-	if err := a.db.Create(&transaction).Error; err != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to record transaction"})
+	var exceeded limit.Error
+	if errors.As(err, &exceeded) {
+		abortLimitError(c, exceeded)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Transfer successful"})
+	switch err {
+	case ledger.ErrSenderNotFound:
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Sender account not found"})
+	case ledger.ErrReceiverNotFound:
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Target account not found"})
+	case ledger.ErrInsufficientBalance:
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Insufficient balance"})
+	case ledger.ErrInvalidAmount, ledger.ErrSameAccount:
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case ledger.ErrQuoteRequired, ledger.ErrQuoteInvalid, ledger.ErrQuoteExpired,
+		ledger.ErrInquiryInvalid, ledger.ErrInquiryExpired:
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case ledger.ErrFeeChanged:
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to transfer balance"})
+	}
 }
 
 // Imp;ementasi metode Mutations
@@ -259,6 +581,10 @@ func (a *accountImplement) Mutation(c *gin.Context) {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	if err := accountno.FillAll(a.db, transactions); err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{"data": transactions})
 }
@@ -0,0 +1,249 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"task-golang-db/accountno"
+	"task-golang-db/audit"
+	"task-golang-db/ledger"
+	"task-golang-db/model"
+	"task-golang-db/webhook"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	errInvalidTransition   = errors.New("status transition not allowed")
+	errBalanceNotZero      = errors.New("account balance must be zero or swept to another account")
+	errInvalidSweepAccount = errors.New("sweep destination must be another open account in the same currency")
+	errAccountNotClosed    = errors.New("account must be closed before delete")
+	errActiveHolds         = errors.New("account has active holds, capture or void them first")
+	errActivePockets       = errors.New("account has money in pockets, close them first")
+)
+
+// changeStatus memindahkan status akun (sudah di-lock) dan mencatat riwayatnya
+func changeStatus(tx *gorm.DB, c *gin.Context, account *model.Account, status, reason string) error {
+	if !model.CanTransition(account.Status, status) {
+		return errInvalidTransition
+	}
+
+	before := *account
+	account.Status = status
+	account.StatusReason = reason
+	if err := tx.Model(account).Updates(map[string]interface{}{
+		"status":        status,
+		"status_reason": reason,
+	}).Error; err != nil {
+		return err
+	}
+
+	actor := audit.ActorFromContext(c)
+	history := model.AccountStatusHistory{
+		AccountID:   account.AccountID,
+		FromStatus:  before.Status,
+		ToStatus:    status,
+		Reason:      reason,
+		ActorAuthID: actor.AuthID,
+	}
+	if err := tx.Create(&history).Error; err != nil {
+		return err
+	}
+
+	return audit.Record(tx, actor, audit.Entry{
+		Action:   audit.ActionUpdate,
+		Entity:   "account",
+		EntityID: account.AccountID,
+		Before:   before,
+		After:    account,
+	})
+}
+
+// Implementasi metode UpdateStatus (admin): pindah status dengan alasan
+func (a *accountImplement) UpdateStatus(c *gin.Context) {
+	accountID, ok := accountParam(c, a.db)
+	if !ok {
+		return
+	}
+	var request struct {
+		Status string `json:"status" binding:"required"`
+		Reason string `json:"reason" binding:"required"`
+	}
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Penutupan akun harus lewat Close supaya aturan saldo nol berlaku
+	if request.Status == model.AccountStatusClosed {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Use /account/close to close an account"})
+		return
+	}
+
+	var account model.Account
+	err := a.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&account, accountID).Error; err != nil {
+			return err
+		}
+		return changeStatus(tx, c, &account, request.Status, request.Reason)
+	})
+	if err != nil {
+		switch err {
+		case gorm.ErrRecordNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+		case errInvalidTransition:
+			c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Cannot change status from %s to %s", account.Status, request.Status)})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Account status updated",
+		"account": account,
+	})
+}
+
+// Implementasi metode StatusHistory: riwayat perubahan status akun
+func (a *accountImplement) StatusHistory(c *gin.Context) {
+	accountID, ok := accountParam(c, a.db)
+	if !ok {
+		return
+	}
+
+	var histories []model.AccountStatusHistory
+	if err := a.db.Where("account_id = ?", accountID).
+		Order("account_status_history_id DESC").
+		Find(&histories).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": histories})
+}
+
+// Implementasi metode Close (requires auth): pemilik akun atau admin.
+// Akun hanya bisa ditutup dengan saldo nol, atau saldo dipindahkan ke sweep_to_account_id.
+func (a *accountImplement) Close(c *gin.Context) {
+	accountID, ok := accountParam(c, a.db)
+	if !ok {
+		return
+	}
+	var request struct {
+		SweepTo accountno.Ref `json:"sweep_to_account_id"`
+		Reason  string        `json:"reason" binding:"required"`
+	}
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var sweepToAccountID int64
+	if request.SweepTo != "" {
+		if sweepToAccountID, ok = resolveAccount(c, a.db, request.SweepTo); !ok {
+			return
+		}
+	}
+
+	var account model.Account
+	var sweep *model.Transaction
+	err := a.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&account, accountID).Error; err != nil {
+			return err
+		}
+		if account.AccountID != c.GetInt64("account_id") && c.GetString("role") != model.RoleAdmin {
+			return errForbidden
+		}
+		if !model.CanTransition(account.Status, model.AccountStatusClosed) {
+			return errInvalidTransition
+		}
+
+		if account.HeldBalance != 0 {
+			return errActiveHolds
+		}
+		if account.PocketBalance != 0 {
+			return errActivePockets
+		}
+
+		if account.Balance != 0 {
+			if sweepToAccountID == 0 {
+				return errBalanceNotZero
+			}
+			if sweepToAccountID == account.AccountID {
+				return errInvalidSweepAccount
+			}
+
+			var destination model.Account
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&destination, sweepToAccountID).Error; err != nil {
+				return errInvalidSweepAccount
+			}
+			if !destination.CanMoveMoney() || destination.Currency != account.Currency {
+				return errInvalidSweepAccount
+			}
+
+			amount := account.Balance
+			destination.Balance += amount
+			account.Balance = 0
+			if err := tx.Model(&account).Update("balance", 0).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&destination).Update("balance", destination.Balance).Error; err != nil {
+				return err
+			}
+
+			sweep = &model.Transaction{
+				AccountID:         account.AccountID,
+				FromAccountId:     account.AccountID,
+				FromAccountNumber: account.AccountNumber,
+				ToAccountId:       destination.AccountID,
+				ToAccountNumber:   destination.AccountNumber,
+				Amount:            int64(amount),
+				Currency:          account.Currency,
+				TransactionDate:   time.Now().Format("2006-01-02 15:04:05"),
+			}
+			if err := tx.Create(sweep).Error; err != nil {
+				return err
+			}
+			if err := ledger.PublishBalance(tx, account); err != nil {
+				return err
+			}
+			if err := ledger.PublishBalance(tx, destination); err != nil {
+				return err
+			}
+			if err := webhook.Enqueue(tx, webhook.EventTransferCompleted, gin.H{
+				"transaction_id":  sweep.TransactionID,
+				"from_account_id": account.AccountID,
+				"to_account_id":   destination.AccountID,
+				"amount":          sweep.Amount,
+			}); err != nil {
+				return err
+			}
+		}
+
+		return changeStatus(tx, c, &account, model.AccountStatusClosed, request.Reason)
+	})
+	if err != nil {
+		switch err {
+		case gorm.ErrRecordNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+		case errForbidden:
+			c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		case errInvalidTransition:
+			c.JSON(http.StatusConflict, gin.H{"error": "Account is already closed"})
+		case errBalanceNotZero, errInvalidSweepAccount, errActiveHolds, errActivePockets:
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Account closed",
+		"account": account,
+		"sweep":   sweep,
+	})
+}
@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"task-golang-db/accountno"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// resolveAccount mengubah referensi akun (account_id atau nomor rekening) menjadi
+// account_id. Check digit yang salah ditolak 400 sebelum lookup; response error sudah
+// dikirim jika false.
+func resolveAccount(c *gin.Context, db *gorm.DB, ref accountno.Ref) (int64, bool) {
+	accountID, err := ref.Resolve(db)
+	if err != nil {
+		switch {
+		case errors.Is(err, accountno.ErrInvalidFormat), errors.Is(err, accountno.ErrInvalidCheckDigit):
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+		default:
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return 0, false
+	}
+	return accountID, true
+}
+
+// accountParam membaca :id sebagai account_id atau nomor rekening
+func accountParam(c *gin.Context, db *gorm.DB) (int64, bool) {
+	return resolveAccount(c, db, accountno.Ref(c.Param("id")))
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"task-golang-db/audit"
+	"task-golang-db/model"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type AuditInterface interface {
+	List(*gin.Context)
+	Verify(*gin.Context)
+}
+
+type auditImplement struct {
+	db *gorm.DB
+}
+
+func NewAudit(db *gorm.DB) AuditInterface {
+	return &auditImplement{
+		db: db,
+	}
+}
+
+// List menampilkan audit log dengan filter entity, entity_id, from, dan to (RFC3339) (requires admin)
+func (a *auditImplement) List(c *gin.Context) {
+	query := a.db.Order("audit_log_id DESC")
+
+	if entity := c.Query("entity"); entity != "" {
+		query = query.Where("entity = ?", entity)
+	}
+	if entityID := c.Query("entity_id"); entityID != "" {
+		query = query.Where("entity_id = ?", entityID)
+	}
+	if from := c.Query("from"); from != "" {
+		t, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "from must be RFC3339"})
+			return
+		}
+		query = query.Where("created_at >= ?", t)
+	}
+	if to := c.Query("to"); to != "" {
+		t, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "to must be RFC3339"})
+			return
+		}
+		query = query.Where("created_at < ?", t)
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+
+	var logs []model.AuditLog
+	if err := query.Limit(limit).Find(&logs).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": logs})
+}
+
+// Verify memeriksa rantai hash audit log (requires admin)
+func (a *auditImplement) Verify(c *gin.Context) {
+	result, err := audit.Verify(a.db)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
@@ -1,9 +1,14 @@
 package handler
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"net/http"
+	"task-golang-db/accountno"
+	"task-golang-db/audit"
 	"task-golang-db/model"
+	"task-golang-db/notification"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -73,6 +78,14 @@ func (a *authImplement) Login(c *gin.Context) {
 		return
 	}
 
+	// Record device and notify if this is a new one
+	if err := a.trackDevice(c, &auth); err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
 	// Login is valid
 	token, err := a.createJWT(&auth)
 	if err != nil {
@@ -90,9 +103,9 @@ func (a *authImplement) Login(c *gin.Context) {
 }
 
 type authUpsertPayload struct {
-	AccountID int64  `json:"account_id"`
-	Username  string `json:"username"`
-	Password  string `json:"password"`
+	Account  accountno.Ref `json:"account_id"`
+	Username string        `json:"username"`
+	Password string        `json:"password"`
 }
 
 func (a *authImplement) Upsert(c *gin.Context) {
@@ -117,8 +130,12 @@ func (a *authImplement) Upsert(c *gin.Context) {
 	}
 
 	// Check AccountID is valid
+	accountID, ok := resolveAccount(c, a.db, payload.Account)
+	if !ok {
+		return
+	}
 	var account model.Account
-	if err := a.db.First(&account, payload.AccountID).Error; err != nil {
+	if err := a.db.First(&account, accountID).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
 				"error": "Account Not found",
@@ -132,22 +149,86 @@ func (a *authImplement) Upsert(c *gin.Context) {
 		return
 	}
 
+	if account.Status == model.AccountStatusClosed {
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{
+			"error": "Account is closed",
+		})
+		return
+	}
+
 	// Prepare new auth data with new password
 	auth := model.Auth{
-		AccountID: payload.AccountID,
+		AccountID: accountID,
 		Username:  payload.Username,
 		Password:  string(hashed),
 	}
 
-	// Upsert auth data (Insert or Update if already exists)
-	result := a.db.Clauses(
-		clause.OnConflict{
-			DoUpdates: clause.AssignmentColumns([]string{"username", "password"}),
-			Columns:   []clause.Column{{Name: "account_id"}},
-		}).Create(&auth)
-	if result.Error != nil {
+	err = a.db.Transaction(func(tx *gorm.DB) error {
+		// An existing auth row means this upsert changes the password
+		var existing int64
+		if err := tx.Model(&model.Auth{}).Where("account_id = ?", accountID).Count(&existing).Error; err != nil {
+			return err
+		}
+
+		// Upsert auth data (Insert or Update if already exists)
+		if err := tx.Clauses(
+			clause.OnConflict{
+				DoUpdates: clause.AssignmentColumns([]string{"username", "password"}),
+				Columns:   []clause.Column{{Name: "account_id"}},
+			}).Create(&auth).Error; err != nil {
+			return err
+		}
+
+		// Pemilik akun selalu menjadi member dengan hak manage
+		var owner model.Auth
+		if err := tx.Select("auth_id").Where("account_id = ?", accountID).First(&owner).Error; err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&model.AccountMember{
+			AccountID:  accountID,
+			AuthID:     owner.AuthID,
+			Permission: model.PermissionManage,
+		}).Error; err != nil {
+			return err
+		}
+
+		// Registering a login activates a pending account
+		if account.Status == model.AccountStatusPending {
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&account, account.AccountID).Error; err != nil {
+				return err
+			}
+			if err := changeStatus(tx, c, &account, model.AccountStatusActive, "login registered"); err != nil {
+				return err
+			}
+		}
+
+		action := audit.ActionCreate
+		if existing > 0 {
+			action = audit.ActionUpdate
+		}
+		// The password hash is never written to the audit log
+		if err := audit.Record(tx, audit.ActorFromContext(c), audit.Entry{
+			Action:   action,
+			Entity:   "auth",
+			EntityID: accountID,
+			After: gin.H{
+				"account_id": accountID,
+				"username":   payload.Username,
+			},
+		}); err != nil {
+			return err
+		}
+
+		if existing == 0 {
+			return nil
+		}
+		return notification.Notify(tx, accountID, notification.EventPasswordChanged, gin.H{
+			"username": payload.Username,
+		})
+	})
+	if err != nil {
 		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"error": result.Error.Error(),
+			"error": err.Error(),
 		})
 		return
 	}
@@ -168,6 +249,7 @@ func (a *authImplement) createJWT(auth *model.Auth) (string, error) {
 	claims["auth_id"] = auth.AuthID
 	claims["account_id"] = auth.AccountID
 	claims["username"] = auth.Username
+	claims["role"] = auth.Role
 	claims["exp"] = time.Now().Add(time.Hour * 72).Unix() // Token expires in 72 hours
 
 	// Encode
@@ -179,3 +261,54 @@ func (a *authImplement) createJWT(auth *model.Auth) (string, error) {
 	// Return the token
 	return tokenString, nil
 }
+
+// trackDevice records the login device. A login from an unknown device on an
+// account that already has known devices triggers a new-device notification.
+func (a *authImplement) trackDevice(c *gin.Context, auth *model.Auth) error {
+	// X-Device-ID is sent by the mobile app; browsers fall back to the User-Agent
+	deviceID := c.GetHeader("X-Device-ID")
+	if deviceID == "" {
+		deviceID = c.Request.UserAgent()
+	}
+	sum := sha256.Sum256([]byte(deviceID))
+	fingerprint := hex.EncodeToString(sum[:])
+
+	return a.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&model.KnownDevice{}).
+			Where("account_id = ? AND fingerprint = ?", auth.AccountID, fingerprint).
+			Updates(map[string]interface{}{"last_seen_at": now, "ip_address": c.ClientIP()})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			return nil
+		}
+
+		var known int64
+		if err := tx.Model(&model.KnownDevice{}).Where("account_id = ?", auth.AccountID).Count(&known).Error; err != nil {
+			return err
+		}
+
+		device := model.KnownDevice{
+			AccountID:   auth.AccountID,
+			Fingerprint: fingerprint,
+			UserAgent:   c.Request.UserAgent(),
+			IPAddress:   c.ClientIP(),
+			FirstSeenAt: now,
+			LastSeenAt:  now,
+		}
+		if err := tx.Create(&device).Error; err != nil {
+			return err
+		}
+
+		// The very first login is not a "new" device
+		if known == 0 {
+			return nil
+		}
+		return notification.Notify(tx, auth.AccountID, notification.EventLoginNewDevice, gin.H{
+			"user_agent": device.UserAgent,
+			"ip_address": device.IPAddress,
+		})
+	})
+}
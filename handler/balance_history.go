@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"net/http"
+	"task-golang-db/model"
+	"task-golang-db/statement"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxHistoryDays membatasi rentang riwayat saldo dalam satu request
+const maxHistoryDays = 366 * 2
+
+// BalanceHistory menampilkan riwayat saldo penutupan akun yang login untuk
+// ?from=&to= (YYYY-MM-DD, default 30 hari terakhir) per ?granularity=daily|weekly|monthly (requires auth)
+func (a *accountImplement) BalanceHistory(c *gin.Context) {
+	now := time.Now()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	from := to.AddDate(0, 0, -29)
+	for param, target := range map[string]*time.Time{"from": &from, "to": &to} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		day, err := time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": param + " must be in YYYY-MM-DD format"})
+			return
+		}
+		*target = day
+	}
+	if from.After(to) || to.Sub(from) > maxHistoryDays*24*time.Hour {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "from must be before to and the range at most 2 years"})
+		return
+	}
+
+	var account model.Account
+	if err := a.db.First(&account, c.GetInt64("account_id")).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+		return
+	}
+
+	granularity := c.DefaultQuery("granularity", statement.GranularityDaily)
+	points, err := statement.Series(a.db, account.AccountID, from, to, granularity)
+	if err != nil {
+		if err == statement.ErrInvalidGranularity {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"account_number": account.AccountNumber,
+		"currency":       account.Currency,
+		"granularity":    granularity,
+		"from":           from.Format("2006-01-02"),
+		"to":             to.Format("2006-01-02"),
+		"data":           points,
+	})
+}
+
+// BalanceAsOf menampilkan saldo akun yang login pada waktu ?at= (RFC3339 atau
+// YYYY-MM-DD untuk saldo penutupan hari itu) (requires auth)
+func (a *accountImplement) BalanceAsOf(c *gin.Context) {
+	value := c.Query("at")
+	at, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		day, derr := time.ParseInLocation("2006-01-02", value, time.Local)
+		if derr != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "at must be RFC3339 or YYYY-MM-DD"})
+			return
+		}
+		at = day.AddDate(0, 0, 1).Add(-time.Second)
+	}
+	if at.After(time.Now()) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "at must not be in the future"})
+		return
+	}
+
+	var account model.Account
+	if err := a.db.First(&account, c.GetInt64("account_id")).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+		return
+	}
+
+	balance, err := statement.AsOf(a.db, account.AccountID, at.In(time.Local))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"account_number": account.AccountNumber,
+		"at":             at,
+		"balance":        balance,
+		"currency":       account.Currency,
+	})
+}
@@ -0,0 +1,198 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"task-golang-db/audit"
+	"task-golang-db/batch"
+	"task-golang-db/ledger"
+	"task-golang-db/limit"
+	"task-golang-db/model"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type BatchInterface interface {
+	Create(*gin.Context)
+	List(*gin.Context)
+	Read(*gin.Context)
+	Result(*gin.Context)
+}
+
+type batchImplement struct {
+	db *gorm.DB
+}
+
+func NewBatch(db *gorm.DB) BatchInterface {
+	return &batchImplement{
+		db: db,
+	}
+}
+
+type batchPayload struct {
+	Mode  string       `json:"mode"`
+	Items []batch.Item `json:"items"`
+}
+
+// Create menerima daftar penerima sebagai JSON, CSV (body text/csv), atau upload
+// multipart field "file". Semua baris divalidasi dulu, lalu batch dieksekusi worker.
+// ?mode=atomic membuat batch all-or-nothing (default partial).
+func (a *batchImplement) Create(c *gin.Context) {
+	mode := c.DefaultQuery("mode", model.BatchModePartial)
+
+	var items []batch.Item
+	var err error
+	contentType := c.ContentType()
+	switch {
+	case strings.HasPrefix(contentType, "multipart/form-data"):
+		file, ferr := c.FormFile("file")
+		if ferr != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+			return
+		}
+		if value := c.PostForm("mode"); value != "" {
+			mode = value
+		}
+		f, ferr := file.Open()
+		if ferr != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": ferr.Error()})
+			return
+		}
+		defer f.Close()
+		items, err = batch.ParseCSV(f)
+	case contentType == "text/csv":
+		items, err = batch.ParseCSV(c.Request.Body)
+	default:
+		payload := batchPayload{}
+		if err := c.BindJSON(&payload); err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if payload.Mode != "" {
+			mode = payload.Mode
+		}
+		items = payload.Items
+	}
+	if err != nil {
+		abortBatchError(c, err)
+		return
+	}
+	for _, item := range items {
+		if memberLimitExceeded(c, item.Amount) {
+			return
+		}
+	}
+	if mode != model.BatchModePartial && mode != model.BatchModeAtomic {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": batch.ErrInvalidMode.Error()})
+		return
+	}
+
+	var created model.TransferBatch
+	err = a.db.Transaction(func(tx *gorm.DB) error {
+		sender, err := ledger.LockAccount(tx, c.GetInt64("account_id"))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ledger.ErrSenderNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if err := batch.Validate(tx, sender, items); err != nil {
+			return err
+		}
+		created, err = batch.Create(tx, sender, mode, items, audit.ActorFromContext(c))
+		return err
+	})
+	if err != nil {
+		abortBatchError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Batch accepted",
+		"data":    created,
+	})
+}
+
+// List menampilkan batch milik akun yang login (requires auth)
+func (a *batchImplement) List(c *gin.Context) {
+	var batches []model.TransferBatch
+	if err := a.db.Where("account_id = ?", c.GetInt64("account_id")).Order("transfer_batch_id DESC").Find(&batches).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": batches})
+}
+
+// Read menampilkan status batch beserta hasil setiap baris
+func (a *batchImplement) Read(c *gin.Context) {
+	record, items, ok := a.find(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  record,
+		"items": items,
+	})
+}
+
+// Result mengunduh hasil per baris sebagai CSV
+func (a *batchImplement) Result(c *gin.Context) {
+	record, items, ok := a.find(c)
+	if !ok {
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := batch.WriteResultCSV(&buf, items); err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"batch-%d-result.csv\"", record.TransferBatchID))
+	c.Data(http.StatusOK, "text/csv", buf.Bytes())
+}
+
+func (a *batchImplement) find(c *gin.Context) (model.TransferBatch, []model.TransferBatchItem, bool) {
+	var record model.TransferBatch
+	var items []model.TransferBatchItem
+
+	if err := a.db.First(&record, "transfer_batch_id = ? AND account_id = ?", c.Param("id"), c.GetInt64("account_id")).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Not found"})
+		return record, items, false
+	}
+	if err := a.db.Where("transfer_batch_id = ?", record.TransferBatchID).Order("row_number").Find(&items).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return record, items, false
+	}
+	if err := batch.FillAccountNumbers(a.db, items); err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return record, items, false
+	}
+	return record, items, true
+}
+
+// abortBatchError memetakan error validasi batch ke response HTTP
+func abortBatchError(c *gin.Context, err error) {
+	var invalid batch.ValidationError
+	var exceeded limit.Error
+	switch {
+	case errors.As(err, &invalid):
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{
+			"error":  invalid.Error(),
+			"rows":   invalid.Rows,
+			"detail": invalid.Batch,
+		})
+	case errors.As(err, &exceeded):
+		abortLimitError(c, exceeded)
+	case errors.Is(err, batch.ErrEmpty), errors.Is(err, batch.ErrTooManyItems),
+		errors.Is(err, batch.ErrMissingColumns), errors.Is(err, batch.ErrInvalidMode):
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		abortTransferError(c, err)
+	}
+}
@@ -0,0 +1,270 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"task-golang-db/accountno"
+	"task-golang-db/audit"
+	"task-golang-db/beneficiary"
+	"task-golang-db/model"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type BeneficiaryInterface interface {
+	Create(*gin.Context)
+	List(*gin.Context)
+	Read(*gin.Context)
+	Update(*gin.Context)
+	Delete(*gin.Context)
+	Frequent(*gin.Context)
+}
+
+type beneficiaryImplement struct {
+	db *gorm.DB
+}
+
+func NewBeneficiary(db *gorm.DB) BeneficiaryInterface {
+	return &beneficiaryImplement{
+		db: db,
+	}
+}
+
+// Create menyimpan penerima berdasarkan account_id atau username (requires auth)
+func (a *beneficiaryImplement) Create(c *gin.Context) {
+	var request struct {
+		BeneficiaryAccount accountno.Ref `json:"beneficiary_account_id"`
+		Username           string        `json:"username"`
+		Nickname           string        `json:"nickname" binding:"max=60"`
+		Favorite           bool          `json:"favorite"`
+	}
+	if err := c.BindJSON(&request); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if (request.BeneficiaryAccount == "") == (request.Username == "") {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Provide either beneficiary_account_id or username"})
+		return
+	}
+
+	accountID := c.GetInt64("account_id")
+	var targetID int64
+	if request.BeneficiaryAccount != "" {
+		var ok bool
+		if targetID, ok = resolveAccount(c, a.db, request.BeneficiaryAccount); !ok {
+			return
+		}
+	}
+	if request.Username != "" {
+		var auth model.Auth
+		if err := a.db.Where("username = ?", strings.TrimSpace(request.Username)).First(&auth).Error; err != nil {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Target account not found"})
+			return
+		}
+		targetID = auth.AccountID
+	}
+
+	var target model.Account
+	if err := a.db.First(&target, targetID).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Target account not found"})
+		return
+	}
+	if target.AccountID == accountID {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Cannot save your own account"})
+		return
+	}
+
+	var existing int64
+	if err := a.db.Model(&model.Beneficiary{}).Where("account_id = ? AND beneficiary_account_id = ?", accountID, target.AccountID).Count(&existing).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if existing > 0 {
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "Beneficiary already saved"})
+		return
+	}
+
+	saved := model.Beneficiary{
+		AccountID:                accountID,
+		BeneficiaryAccountID:     target.AccountID,
+		BeneficiaryAccountNumber: target.AccountNumber,
+		Nickname:                 strings.TrimSpace(request.Nickname),
+		Favorite:                 request.Favorite,
+	}
+	if saved.Nickname == "" {
+		saved.Nickname = target.Name
+	}
+	err := a.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&saved).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, audit.ActorFromContext(c), audit.Entry{
+			Action:   audit.ActionCreate,
+			Entity:   "beneficiary",
+			EntityID: saved.BeneficiaryID,
+			After:    saved,
+		})
+	})
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Create success",
+		"data":    saved,
+	})
+}
+
+// List menampilkan beneficiary: favorit dulu, lalu yang terakhir dipakai (requires auth)
+func (a *beneficiaryImplement) List(c *gin.Context) {
+	var beneficiaries []model.Beneficiary
+	err := a.db.Where("account_id = ?", c.GetInt64("account_id")).
+		Order("favorite DESC, last_used_at DESC NULLS LAST, nickname").
+		Find(&beneficiaries).Error
+	if err == nil {
+		err = accountno.FillAll(a.db, beneficiaries)
+	}
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": beneficiaries})
+}
+
+// Read menampilkan satu beneficiary milik akun yang login (requires auth)
+func (a *beneficiaryImplement) Read(c *gin.Context) {
+	saved, ok := a.find(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": saved})
+}
+
+// Update mengubah nickname dan/atau status favorit (requires auth)
+func (a *beneficiaryImplement) Update(c *gin.Context) {
+	var request struct {
+		Nickname *string `json:"nickname" binding:"omitempty,min=1,max=60"`
+		Favorite *bool   `json:"favorite"`
+	}
+	if err := c.BindJSON(&request); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	saved, ok := a.find(c)
+	if !ok {
+		return
+	}
+
+	before := saved
+	if request.Nickname != nil {
+		saved.Nickname = strings.TrimSpace(*request.Nickname)
+	}
+	if request.Favorite != nil {
+		saved.Favorite = *request.Favorite
+	}
+	err := a.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&saved).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, audit.ActorFromContext(c), audit.Entry{
+			Action:   audit.ActionUpdate,
+			Entity:   "beneficiary",
+			EntityID: saved.BeneficiaryID,
+			Before:   before,
+			After:    saved,
+		})
+	})
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Update success",
+		"data":    saved,
+	})
+}
+
+// Delete menghapus beneficiary (requires auth)
+func (a *beneficiaryImplement) Delete(c *gin.Context) {
+	saved, ok := a.find(c)
+	if !ok {
+		return
+	}
+
+	err := a.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&saved).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, audit.ActorFromContext(c), audit.Entry{
+			Action:   audit.ActionDelete,
+			Entity:   "beneficiary",
+			EntityID: saved.BeneficiaryID,
+			Before:   saved,
+		})
+	})
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Delete success"})
+}
+
+// Frequent menampilkan penerima transfer terbanyak dalam ?days= hari terakhir
+// (default 90) sebanyak ?limit= (default 5, maks 20) (requires auth)
+func (a *beneficiaryImplement) Frequent(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", "90"))
+	if err != nil || days <= 0 || days > 366 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "days must be between 1 and 366"})
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "5"))
+	if err != nil || limit <= 0 || limit > 20 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 20"})
+		return
+	}
+
+	recipients, err := beneficiary.Frequent(a.db, c.GetInt64("account_id"), time.Now().AddDate(0, 0, -days), limit)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": recipients})
+}
+
+// find mengambil beneficiary :id milik akun yang login; response error sudah dikirim jika false
+func (a *beneficiaryImplement) find(c *gin.Context) (model.Beneficiary, bool) {
+	var saved model.Beneficiary
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid beneficiary id"})
+		return saved, false
+	}
+	err = a.db.First(&saved, "beneficiary_id = ? AND account_id = ?", id, c.GetInt64("account_id")).Error
+	if err == nil {
+		err = accountno.Fill(a.db, &saved)
+	}
+	if err != nil {
+		abortBeneficiaryError(c, err)
+		return saved, false
+	}
+	return saved, true
+}
+
+// abortBeneficiaryError memetakan error beneficiary ke response HTTP
+func abortBeneficiaryError(c *gin.Context, err error) {
+	if err == beneficiary.ErrNotFound || err == gorm.ErrRecordNotFound {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Beneficiary not found"})
+		return
+	}
+	c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
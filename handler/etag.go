@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// errVersionConflict menandai UPDATE bersyarat version yang tidak mengenai baris apa pun
+var errVersionConflict = errors.New("version conflict")
+
+// etag membentuk ETag dari kolom version. Version dinaikkan trigger database pada
+// setiap UPDATE sehingga ETag berubah untuk perubahan dari jalur mana pun.
+func etag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// etagMatches mengecek apakah header If-Match/If-None-Match (daftar dipisah koma, boleh
+// "*") cocok dengan version. RFC 9110: If-None-Match memakai weak comparison (prefix W/
+// diabaikan), If-Match memakai strong comparison sehingga tag W/ tidak pernah cocok.
+func etagMatches(header string, version int64, weak bool) bool {
+	for _, value := range strings.Split(header, ",") {
+		value = strings.TrimSpace(value)
+		if weak {
+			value = strings.TrimPrefix(value, "W/")
+		}
+		if value == "*" || value == etag(version) {
+			return true
+		}
+	}
+	return false
+}
+
+// notModified memasang header ETag dan mengirim 304 jika If-None-Match cocok
+func notModified(c *gin.Context, version int64) bool {
+	c.Header("ETag", etag(version))
+	if header := c.GetHeader("If-None-Match"); header != "" && etagMatches(header, version, true) {
+		c.AbortWithStatus(http.StatusNotModified)
+		return true
+	}
+	return false
+}
+
+// preconditionFailed memvalidasi If-Match terhadap version saat ini: 428 jika header
+// tidak ada, 412 jika tidak cocok. Response error sudah dikirim jika true.
+func preconditionFailed(c *gin.Context, version int64) bool {
+	header := c.GetHeader("If-Match")
+	if header == "" {
+		c.AbortWithStatusJSON(http.StatusPreconditionRequired, gin.H{"error": "If-Match header is required"})
+		return true
+	}
+	if !etagMatches(header, version, false) {
+		abortVersionConflict(c, version)
+		return true
+	}
+	return false
+}
+
+// abortVersionConflict mengirim 412 beserta ETag terbaru supaya client bisa membaca ulang
+func abortVersionConflict(c *gin.Context, version int64) {
+	c.Header("ETag", etag(version))
+	c.AbortWithStatusJSON(http.StatusPreconditionFailed, gin.H{"error": "Resource was modified by another request; reload and try again"})
+}
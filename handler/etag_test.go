@@ -0,0 +1,24 @@
+package handler
+
+import "testing"
+
+func TestEtagMatches(t *testing.T) {
+	for _, tc := range []struct {
+		header string
+		weak   bool
+		want   bool
+	}{
+		{`"3"`, false, true},
+		{`"2", "3"`, false, true},
+		{`*`, false, true},
+		{`"2"`, false, false},
+		{`W/"3"`, false, false}, // If-Match: tag weak tidak memenuhi strong comparison
+		{`W/"3"`, true, true},
+		{`"1", W/"3"`, true, true},
+		{`W/"2"`, true, false},
+	} {
+		if got := etagMatches(tc.header, 3, tc.weak); got != tc.want {
+			t.Errorf("etagMatches(%s, weak=%v) = %v, want %v", tc.header, tc.weak, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"task-golang-db/audit"
+	"task-golang-db/currency"
+	"task-golang-db/fee"
+	"task-golang-db/limit"
+	"task-golang-db/model"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type FeeInterface interface {
+	Quote(*gin.Context)
+	Rules(*gin.Context)
+	CreateRule(*gin.Context)
+	UpdateRule(*gin.Context)
+	DeleteRule(*gin.Context)
+}
+
+type feeImplement struct {
+	db *gorm.DB
+}
+
+func NewFee(db *gorm.DB) FeeInterface {
+	return &feeImplement{
+		db: db,
+	}
+}
+
+type feeRulePayload struct {
+	Name         string `json:"name" binding:"required,max=60"`
+	Operation    string `json:"operation" binding:"required"`
+	Tier         string `json:"tier"`
+	Currency     string `json:"currency"`
+	FlatAmount   int64  `json:"flat_amount"`
+	PercentBps   int64  `json:"percent_bps"`
+	MinFee       int64  `json:"min_fee"`
+	MaxFee       *int64 `json:"max_fee"`
+	FreePerMonth int64  `json:"free_per_month"`
+	Active       *bool  `json:"active"`
+}
+
+// Quote menampilkan biaya operasi sebelum dikonfirmasi, contoh
+// /fee/quote?operation=transfer&amount=50000 (requires auth)
+func (a *feeImplement) Quote(c *gin.Context) {
+	operation := c.Query("operation")
+	if !limit.ValidOperation(operation) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Unknown operation"})
+		return
+	}
+	amount, err := strconv.ParseInt(c.Query("amount"), 10, 64)
+	if err != nil || amount <= 0 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "amount must be a positive integer"})
+		return
+	}
+
+	var account model.Account
+	if err := a.db.First(&account, c.GetInt64("account_id")).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+		return
+	}
+
+	quote, err := fee.Compute(a.db, account, operation, amount)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": quote})
+}
+
+// Rules menampilkan semua aturan biaya (admin only)
+func (a *feeImplement) Rules(c *gin.Context) {
+	var rules []model.FeeRule
+	if err := a.db.Order("fee_rule_id").Find(&rules).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": rules})
+}
+
+// CreateRule membuat aturan biaya baru (admin only)
+func (a *feeImplement) CreateRule(c *gin.Context) {
+	a.saveRule(c, model.FeeRule{Active: true})
+}
+
+// UpdateRule mengubah aturan biaya (admin only)
+func (a *feeImplement) UpdateRule(c *gin.Context) {
+	ruleID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Fee rule not found"})
+		return
+	}
+	var rule model.FeeRule
+	if err := a.db.First(&rule, ruleID).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Fee rule not found"})
+		return
+	}
+
+	a.saveRule(c, rule)
+}
+
+func (a *feeImplement) saveRule(c *gin.Context, rule model.FeeRule) {
+	payload := feeRulePayload{}
+	if err := c.BindJSON(&payload); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !limit.ValidOperation(payload.Operation) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Unknown operation"})
+		return
+	}
+	if payload.Tier != "" && !validTier(payload.Tier) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Unknown tier"})
+		return
+	}
+	payload.Currency = currency.Normalize(payload.Currency)
+	if !currency.Supported(payload.Currency) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Unsupported currency"})
+		return
+	}
+
+	before := rule
+	rule.Name = payload.Name
+	rule.Operation = payload.Operation
+	rule.Tier = payload.Tier
+	rule.Currency = payload.Currency
+	rule.FlatAmount = payload.FlatAmount
+	rule.PercentBps = payload.PercentBps
+	rule.MinFee = payload.MinFee
+	rule.MaxFee = payload.MaxFee
+	rule.FreePerMonth = payload.FreePerMonth
+	if payload.Active != nil {
+		rule.Active = *payload.Active
+	}
+	if err := fee.Validate(rule); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := a.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&rule).Error; err != nil {
+			return err
+		}
+
+		entry := audit.Entry{
+			Action:   audit.ActionCreate,
+			Entity:   "fee_rule",
+			EntityID: rule.FeeRuleID,
+			After:    rule,
+		}
+		if before.FeeRuleID != 0 {
+			entry.Action = audit.ActionUpdate
+			entry.Before = before
+		}
+		return audit.Record(tx, audit.ActorFromContext(c), entry)
+	})
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Save success",
+		"data":    rule,
+	})
+}
+
+// DeleteRule menghapus aturan biaya sehingga operasi terkait kembali gratis (admin only)
+func (a *feeImplement) DeleteRule(c *gin.Context) {
+	ruleID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Fee rule not found"})
+		return
+	}
+	var rule model.FeeRule
+	err = a.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&rule, ruleID).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&rule).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, audit.ActorFromContext(c), audit.Entry{
+			Action:   audit.ActionDelete,
+			Entity:   "fee_rule",
+			EntityID: rule.FeeRuleID,
+			Before:   rule,
+		})
+	})
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Fee rule not found"})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Delete success"})
+}
@@ -0,0 +1,202 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"os"
+	"strconv"
+	"task-golang-db/accountno"
+	"task-golang-db/audit"
+	"task-golang-db/currency"
+	"task-golang-db/model"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Masa berlaku quote kurs sebelum user mengonfirmasi transfer
+const fxQuoteTTL = 60 * time.Second
+
+type FxInterface interface {
+	Rates(*gin.Context)
+	CreateRates(*gin.Context)
+	Quote(*gin.Context)
+}
+
+type fxImplement struct {
+	db *gorm.DB
+}
+
+func NewFx(db *gorm.DB) FxInterface {
+	return &fxImplement{
+		db: db,
+	}
+}
+
+// Rates menampilkan kurs yang berlaku, atau riwayat pasangan tertentu dengan ?base=&quote=
+func (a *fxImplement) Rates(c *gin.Context) {
+	var rates []model.ExchangeRate
+
+	base := c.Query("base")
+	quote := c.Query("quote")
+	if base != "" && quote != "" {
+		if err := a.db.Where("base_currency = ? AND quote_currency = ?", currency.Normalize(base), currency.Normalize(quote)).
+			Order("effective_at DESC").
+			Find(&rates).Error; err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": rates})
+		return
+	}
+
+	// Kurs terbaru per pasangan yang sudah berlaku
+	if err := a.db.Raw(`SELECT DISTINCT ON (base_currency, quote_currency) *
+		FROM exchange_rates
+		WHERE effective_at <= ?
+		ORDER BY base_currency, quote_currency, effective_at DESC, exchange_rate_id DESC`, time.Now()).
+		Scan(&rates).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": rates})
+}
+
+type fxRatePayload struct {
+	BaseCurrency  string     `json:"base_currency" binding:"required"`
+	QuoteCurrency string     `json:"quote_currency" binding:"required"`
+	Rate          float64    `json:"rate" binding:"required,gt=0"`
+	EffectiveAt   *time.Time `json:"effective_at"`
+}
+
+// CreateRates menambahkan versi kurs baru (requires admin)
+func (a *fxImplement) CreateRates(c *gin.Context) {
+	var payload []fxRatePayload
+	if err := c.BindJSON(&payload); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rates := make([]model.ExchangeRate, 0, len(payload))
+	for _, p := range payload {
+		rate := model.ExchangeRate{
+			BaseCurrency:  currency.Normalize(p.BaseCurrency),
+			QuoteCurrency: currency.Normalize(p.QuoteCurrency),
+			Rate:          p.Rate,
+			EffectiveAt:   time.Now(),
+			Source:        "admin:" + c.GetString("username"),
+		}
+		if p.EffectiveAt != nil {
+			rate.EffectiveAt = *p.EffectiveAt
+		}
+		if err := currency.ValidateRate(rate); err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		rates = append(rates, rate)
+	}
+
+	err := a.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&rates).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, audit.ActorFromContext(c), audit.Entry{
+			Action: audit.ActionCreate,
+			Entity: "exchange_rate",
+			After:  rates,
+		})
+	})
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Create success",
+		"data":    rates,
+	})
+}
+
+// Quote mengunci kurs untuk transfer lintas mata uang (requires auth).
+// quote_id yang dikembalikan dikirim ke /account/transfer untuk konfirmasi.
+func (a *fxImplement) Quote(c *gin.Context) {
+	accountID := c.GetInt64("account_id")
+	var request struct {
+		ToAccount accountno.Ref `json:"to_account_id" binding:"required"`
+		Amount    int64         `json:"amount" binding:"required,gt=0"`
+	}
+	if err := c.BindJSON(&request); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	toAccountID, ok := resolveAccount(c, a.db, request.ToAccount)
+	if !ok {
+		return
+	}
+
+	var sender, receiver model.Account
+	if err := a.db.First(&sender, accountID).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Sender account not found"})
+		return
+	}
+	if err := a.db.First(&receiver, toAccountID).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Target account not found"})
+		return
+	}
+	if sender.Currency == receiver.Currency {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Accounts use the same currency, no quote needed"})
+		return
+	}
+
+	quote, err := newFxQuote(a.db, sender, receiver, request.Amount)
+	if err != nil {
+		if err == currency.ErrRateNotFound {
+			c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": "No exchange rate for " + sender.Currency + "/" + receiver.Currency})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": quote})
+}
+
+// newFxQuote menghitung dan menyimpan quote dengan spread FX_SPREAD_BPS (default 50 bps).
+// Spread di luar 0..9999 bps diabaikan karena membuat kurs nol atau negatif.
+func newFxQuote(db *gorm.DB, sender, receiver model.Account, amount int64) (model.FxQuote, error) {
+	rate, err := currency.LatestRate(db, sender.Currency, receiver.Currency, time.Now())
+	if err != nil {
+		return model.FxQuote{}, err
+	}
+
+	spreadBps, err := strconv.ParseInt(os.Getenv("FX_SPREAD_BPS"), 10, 64)
+	if err != nil || spreadBps < 0 || spreadBps >= 10000 {
+		spreadBps = 50
+	}
+	applied := rate.Rate * (1 - float64(spreadBps)/10000)
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return model.FxQuote{}, err
+	}
+
+	quote := model.FxQuote{
+		FxQuoteID:       hex.EncodeToString(id),
+		FromAccountID:   sender.AccountID,
+		ToAccountID:     receiver.AccountID,
+		ToAccountNumber: receiver.AccountNumber,
+		FromCurrency:    sender.Currency,
+		ToCurrency:      receiver.Currency,
+		MidRate:         rate.Rate,
+		SpreadBps:       spreadBps,
+		AppliedRate:     applied,
+		SourceAmount:    amount,
+		TargetAmount:    currency.Convert(amount, sender.Currency, receiver.Currency, applied),
+		ExpiresAt:       time.Now().Add(fxQuoteTTL),
+	}
+	return quote, db.Create(&quote).Error
+}
@@ -0,0 +1,246 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"task-golang-db/accountno"
+	"task-golang-db/audit"
+	"task-golang-db/ledger"
+	"task-golang-db/model"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Batas default masa berlaku hold jika tidak diisi
+const defaultHoldTTL = 7 * 24 * time.Hour
+
+type HoldInterface interface {
+	Create(*gin.Context)
+	Capture(*gin.Context)
+	Void(*gin.Context)
+	List(*gin.Context)
+	Read(*gin.Context)
+}
+
+type holdImplement struct {
+	db *gorm.DB
+}
+
+func NewHold(db *gorm.DB) HoldInterface {
+	return &holdImplement{
+		db: db,
+	}
+}
+
+type holdPayload struct {
+	MerchantAccount  accountno.Ref `json:"merchant_account_id" binding:"required"`
+	Amount           int64         `json:"amount" binding:"required,gt=0"`
+	Description      string        `json:"description"`
+	ExpiresInSeconds int64         `json:"expires_in_seconds"`
+	ExpiresAt        *time.Time    `json:"expires_at"`
+}
+
+// Create menahan dana akun yang login untuk merchant (requires auth)
+func (a *holdImplement) Create(c *gin.Context) {
+	payload := holdPayload{}
+	if err := c.BindJSON(&payload); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if memberLimitExceeded(c, payload.Amount) {
+		return
+	}
+	merchantAccountID, ok := resolveAccount(c, a.db, payload.MerchantAccount)
+	if !ok {
+		return
+	}
+
+	expiresAt := time.Now().Add(defaultHoldTTL)
+	if payload.ExpiresAt != nil {
+		expiresAt = *payload.ExpiresAt
+	} else if payload.ExpiresInSeconds > 0 {
+		expiresAt = time.Now().Add(time.Duration(payload.ExpiresInSeconds) * time.Second)
+	}
+	if !expiresAt.After(time.Now()) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "expires_at must be in the future"})
+		return
+	}
+
+	var hold model.Hold
+	err := a.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		hold, err = ledger.PlaceHold(tx, ledger.HoldRequest{
+			AccountID:         c.GetInt64("account_id"),
+			MerchantAccountID: merchantAccountID,
+			Amount:            payload.Amount,
+			Description:       payload.Description,
+			ExpiresAt:         expiresAt,
+			Actor:             audit.ActorFromContext(c),
+		})
+		return err
+	})
+	if err != nil {
+		abortHoldError(c, err)
+		return
+	}
+	if err := accountno.Fill(a.db, &hold); err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Hold success",
+		"data":    hold,
+	})
+}
+
+type capturePayload struct {
+	// Amount kosong berarti capture penuh
+	Amount int64 `json:"amount"`
+}
+
+// Capture menarik sebagian atau seluruh dana hold ke akun merchant (merchant only)
+func (a *holdImplement) Capture(c *gin.Context) {
+	holdID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid hold id"})
+		return
+	}
+
+	payload := capturePayload{}
+	if c.Request.ContentLength > 0 {
+		if err := c.BindJSON(&payload); err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	var hold model.Hold
+	var result ledger.TransferResult
+	err = a.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		hold, err = ledger.LockHold(tx, holdID)
+		if err != nil {
+			return err
+		}
+		if hold.MerchantAccountID != c.GetInt64("account_id") {
+			return errForbidden
+		}
+
+		amount := payload.Amount
+		if amount == 0 {
+			amount = hold.Amount
+		}
+		hold, result, err = ledger.CaptureHold(tx, hold, amount, audit.ActorFromContext(c))
+		return err
+	})
+	if err != nil {
+		abortHoldError(c, err)
+		return
+	}
+	if err := accountno.Fill(a.db, &hold); err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Capture success",
+		"data":        hold,
+		"transaction": result.Transaction,
+	})
+}
+
+// Void membatalkan hold dan mengembalikan dana ke saldo available (merchant atau admin)
+func (a *holdImplement) Void(c *gin.Context) {
+	holdID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid hold id"})
+		return
+	}
+
+	var hold model.Hold
+	err = a.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		hold, err = ledger.LockHold(tx, holdID)
+		if err != nil {
+			return err
+		}
+		if hold.MerchantAccountID != c.GetInt64("account_id") && c.GetString("role") != model.RoleAdmin {
+			return errForbidden
+		}
+
+		hold, err = ledger.ReleaseHold(tx, hold, model.HoldVoided, audit.ActorFromContext(c))
+		return err
+	})
+	if err != nil {
+		abortHoldError(c, err)
+		return
+	}
+	if err := accountno.Fill(a.db, &hold); err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Void success",
+		"data":    hold,
+	})
+}
+
+// List menampilkan hold di mana akun yang login sebagai pembayar atau merchant
+func (a *holdImplement) List(c *gin.Context) {
+	accountID := c.GetInt64("account_id")
+
+	var holds []model.Hold
+	query := a.db.Where("account_id = ? OR merchant_account_id = ?", accountID, accountID).Order("hold_id DESC")
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Find(&holds).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := accountno.FillAll(a.db, holds); err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": holds})
+}
+
+func (a *holdImplement) Read(c *gin.Context) {
+	accountID := c.GetInt64("account_id")
+
+	var hold model.Hold
+	if err := a.db.First(&hold, "hold_id = ? AND (account_id = ? OR merchant_account_id = ?)", c.Param("id"), accountID, accountID).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Not found"})
+		return
+	}
+	if err := accountno.Fill(a.db, &hold); err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": hold})
+}
+
+// abortHoldError memetakan error hold ke response HTTP
+func abortHoldError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Hold not found"})
+	case errors.Is(err, errForbidden):
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, ledger.ErrHoldNotActive):
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, ledger.ErrCaptureExceedsHold), errors.Is(err, ledger.ErrCurrencyMismatch):
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, ledger.ErrReceiverNotFound):
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Merchant account not found"})
+	default:
+		abortTransferError(c, err)
+	}
+}
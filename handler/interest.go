@@ -0,0 +1,324 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"task-golang-db/audit"
+	"task-golang-db/interest"
+	"task-golang-db/model"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type InterestInterface interface {
+	My(*gin.Context)
+	Products(*gin.Context)
+	CreateProduct(*gin.Context)
+	UpdateProduct(*gin.Context)
+	SetAccountProduct(*gin.Context)
+	Accruals(*gin.Context)
+	Postings(*gin.Context)
+	Recompute(*gin.Context)
+	Run(*gin.Context)
+}
+
+type interestImplement struct {
+	db     *gorm.DB
+	config interest.Config
+}
+
+func NewInterest(db *gorm.DB, config interest.Config) InterestInterface {
+	return &interestImplement{
+		db:     db,
+		config: config,
+	}
+}
+
+type interestProductPayload struct {
+	Name        string               `json:"name" binding:"required,max=60"`
+	AnnualRate  float64              `json:"annual_rate"`
+	Bands       []model.InterestBand `json:"bands"`
+	DayCount    string               `json:"day_count" binding:"required"`
+	Compounding string               `json:"compounding" binding:"required"`
+	TaxBps      int64                `json:"tax_bps"`
+	Active      *bool                `json:"active"`
+}
+
+// My menampilkan produk bunga, bunga berjalan yang belum dikreditkan, dan riwayat
+// pengkreditan akun yang login (requires auth)
+func (a *interestImplement) My(c *gin.Context) {
+	var account model.Account
+	if err := a.db.First(&account, c.GetInt64("account_id")).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+		return
+	}
+
+	var product *model.InterestProduct
+	if account.InterestProductID != nil {
+		product = &model.InterestProduct{}
+		if err := a.db.First(product, *account.InterestProductID).Error; err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	var pending float64
+	err := a.db.Model(&model.InterestAccrual{}).
+		Where("account_id = ? AND interest_posting_id IS NULL", account.AccountID).
+		Select("COALESCE(SUM(amount), 0)").
+		Scan(&pending).Error
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var postings []model.InterestPosting
+	if err := a.db.Where("account_id = ?", account.AccountID).Order("period_end DESC").Limit(12).Find(&postings).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"product":         product,
+		"pending_accrued": pending,
+		"postings":        postings,
+	})
+}
+
+// Products menampilkan semua produk bunga (admin only)
+func (a *interestImplement) Products(c *gin.Context) {
+	var products []model.InterestProduct
+	if err := a.db.Order("interest_product_id").Find(&products).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": products})
+}
+
+// CreateProduct membuat produk bunga baru (admin only)
+func (a *interestImplement) CreateProduct(c *gin.Context) {
+	a.saveProduct(c, model.InterestProduct{Active: true})
+}
+
+// UpdateProduct mengubah produk bunga. Perubahan berlaku untuk accrual berikutnya;
+// accrual yang sudah tercatat menyimpan snapshot aturannya sendiri (admin only)
+func (a *interestImplement) UpdateProduct(c *gin.Context) {
+	productID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Interest product not found"})
+		return
+	}
+	var product model.InterestProduct
+	if err := a.db.First(&product, productID).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Interest product not found"})
+		return
+	}
+
+	a.saveProduct(c, product)
+}
+
+func (a *interestImplement) saveProduct(c *gin.Context, product model.InterestProduct) {
+	payload := interestProductPayload{}
+	if err := c.BindJSON(&payload); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	before := product
+	product.Name = payload.Name
+	product.AnnualRate = payload.AnnualRate
+	product.Bands = payload.Bands
+	product.DayCount = payload.DayCount
+	product.Compounding = payload.Compounding
+	product.TaxBps = payload.TaxBps
+	if payload.Active != nil {
+		product.Active = *payload.Active
+	}
+	if err := interest.Validate(product); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := a.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&product).Error; err != nil {
+			return err
+		}
+
+		entry := audit.Entry{
+			Action:   audit.ActionCreate,
+			Entity:   "interest_product",
+			EntityID: product.InterestProductID,
+			After:    product,
+		}
+		if before.InterestProductID != 0 {
+			entry.Action = audit.ActionUpdate
+			entry.Before = before
+		}
+		return audit.Record(tx, audit.ActorFromContext(c), entry)
+	})
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Save success",
+		"data":    product,
+	})
+}
+
+// SetAccountProduct memasang atau melepas (interest_product_id null) produk bunga akun (admin only)
+func (a *interestImplement) SetAccountProduct(c *gin.Context) {
+	var request struct {
+		InterestProductID *int64 `json:"interest_product_id"`
+	}
+	if err := c.BindJSON(&request); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	accountID, ok := accountParam(c, a.db)
+	if !ok {
+		return
+	}
+
+	var account model.Account
+	err := a.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&account, accountID).Error; err != nil {
+			return err
+		}
+		if request.InterestProductID != nil {
+			if err := tx.First(&model.InterestProduct{}, *request.InterestProductID).Error; err != nil {
+				return err
+			}
+		}
+
+		before := account
+		account.InterestProductID = request.InterestProductID
+		if err := tx.Model(&account).Update("interest_product_id", account.InterestProductID).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, audit.ActorFromContext(c), audit.Entry{
+			Action:   audit.ActionUpdate,
+			Entity:   "account",
+			EntityID: account.AccountID,
+			Before:   before,
+			After:    account,
+		})
+	})
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Account or interest product not found"})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Update success",
+		"data":    account,
+	})
+}
+
+// Accruals menampilkan bunga harian akun, opsional dibatasi ?from= dan ?to= (YYYY-MM-DD) (admin only)
+func (a *interestImplement) Accruals(c *gin.Context) {
+	accountID, ok := accountParam(c, a.db)
+	if !ok {
+		return
+	}
+
+	query := a.db.Where("account_id = ?", accountID).Order("accrual_date")
+	for param, condition := range map[string]string{"from": "accrual_date >= ?", "to": "accrual_date <= ?"} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": param + " must be in YYYY-MM-DD format"})
+			return
+		}
+		query = query.Where(condition, value)
+	}
+
+	var accruals []model.InterestAccrual
+	if err := query.Find(&accruals).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": accruals})
+}
+
+// Postings menampilkan riwayat pengkreditan bunga akun (admin only)
+func (a *interestImplement) Postings(c *gin.Context) {
+	accountID, ok := accountParam(c, a.db)
+	if !ok {
+		return
+	}
+
+	var postings []model.InterestPosting
+	if err := a.db.Where("account_id = ?", accountID).Order("period_end").Find(&postings).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": postings})
+}
+
+// Recompute menghitung ulang bunga satu hari (?date=YYYY-MM-DD) dan membandingkannya
+// dengan accrual yang tercatat (admin only)
+func (a *interestImplement) Recompute(c *gin.Context) {
+	accountID, ok := accountParam(c, a.db)
+	if !ok {
+		return
+	}
+	day, err := time.ParseInLocation("2006-01-02", c.Query("date"), time.Local)
+	if err != nil || !day.Before(time.Now()) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "date must be a past day in YYYY-MM-DD format"})
+		return
+	}
+
+	result, err := interest.Recompute(a.db, accountID, day)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+		case errors.Is(err, interest.ErrNoProduct):
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
+
+// Run menjalankan accrual dan posting akun sampai kemarin tanpa menunggu jadwal.
+// Hari yang sudah tercatat dilewati sehingga aman dipanggil berulang (admin only)
+func (a *interestImplement) Run(c *gin.Context) {
+	accountID, ok := accountParam(c, a.db)
+	if !ok {
+		return
+	}
+
+	accrued, err := interest.Accrue(a.db, a.config, accountID, time.Now().AddDate(0, 0, -1))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Run success",
+		"accrued": accrued,
+	})
+}
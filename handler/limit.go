@@ -0,0 +1,276 @@
+package handler
+
+import (
+	"net/http"
+	"task-golang-db/accountno"
+	"task-golang-db/audit"
+	"task-golang-db/limit"
+	"task-golang-db/model"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type LimitInterface interface {
+	Remaining(*gin.Context)
+	List(*gin.Context)
+	SetTier(*gin.Context)
+	SetOverride(*gin.Context)
+	DeleteOverride(*gin.Context)
+	SetAccountTier(*gin.Context)
+}
+
+type limitImplement struct {
+	db *gorm.DB
+}
+
+func NewLimit(db *gorm.DB) LimitInterface {
+	return &limitImplement{
+		db: db,
+	}
+}
+
+type limitPayload struct {
+	MaxSingle     *int64 `json:"max_single" binding:"omitempty,gte=0"`
+	MaxDaily      *int64 `json:"max_daily" binding:"omitempty,gte=0"`
+	MaxMonthly    *int64 `json:"max_monthly" binding:"omitempty,gte=0"`
+	MaxDailyCount *int64 `json:"max_daily_count" binding:"omitempty,gte=0"`
+}
+
+// Remaining menampilkan limit efektif dan sisa limit akun yang login (requires auth)
+func (a *limitImplement) Remaining(c *gin.Context) {
+	var account model.Account
+	if err := a.db.First(&account, c.GetInt64("account_id")).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+		return
+	}
+
+	data := gin.H{}
+	for _, operation := range limit.Operations {
+		limits, err := limit.Effective(a.db, account, operation)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		usage, err := limit.CurrentUsage(a.db, account.AccountID, operation, time.Now())
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		data[operation] = gin.H{
+			"limits":    limits,
+			"usage":     usage,
+			"remaining": limit.Remaining(limits, usage),
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"tier": account.Tier,
+		"data": data,
+	})
+}
+
+// List menampilkan semua limit tier dan override (admin only)
+func (a *limitImplement) List(c *gin.Context) {
+	var limits []model.TransactionLimit
+	query := a.db.Order("transaction_limit_id")
+	if value := c.Query("account_id"); value != "" {
+		accountID, ok := resolveAccount(c, a.db, accountno.Ref(value))
+		if !ok {
+			return
+		}
+		query = query.Where("account_id = ?", accountID)
+	}
+	if err := query.Find(&limits).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": limits})
+}
+
+// SetTier membuat atau mengubah limit sebuah tier untuk satu operasi (admin only)
+func (a *limitImplement) SetTier(c *gin.Context) {
+	a.upsert(c, c.Param("tier"), nil)
+}
+
+// SetOverride membuat atau mengubah override limit sebuah akun (admin only)
+func (a *limitImplement) SetOverride(c *gin.Context) {
+	accountID, ok := accountParam(c, a.db)
+	if !ok {
+		return
+	}
+	if err := a.db.First(&model.Account{}, accountID).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+		return
+	}
+
+	a.upsert(c, "", &accountID)
+}
+
+func (a *limitImplement) upsert(c *gin.Context, tier string, accountID *int64) {
+	operation := c.Param("operation")
+	if !limit.ValidOperation(operation) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Unknown operation"})
+		return
+	}
+	if accountID == nil && !validTier(tier) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Unknown tier"})
+		return
+	}
+
+	payload := limitPayload{}
+	if err := c.BindJSON(&payload); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var row model.TransactionLimit
+	err := a.db.Transaction(func(tx *gorm.DB) error {
+		query := tx.Where("operation = ?", operation)
+		if accountID != nil {
+			query = query.Where("account_id = ?", *accountID)
+		} else {
+			query = query.Where("tier = ? AND account_id IS NULL", tier)
+		}
+		if err := query.Limit(1).Find(&row).Error; err != nil {
+			return err
+		}
+
+		before := row
+		action := audit.ActionUpdate
+		if row.TransactionLimitID == 0 {
+			action = audit.ActionCreate
+			row = model.TransactionLimit{Tier: tier, AccountID: accountID, Operation: operation}
+		}
+		row.MaxSingle = payload.MaxSingle
+		row.MaxDaily = payload.MaxDaily
+		row.MaxMonthly = payload.MaxMonthly
+		row.MaxDailyCount = payload.MaxDailyCount
+
+		if err := tx.Save(&row).Error; err != nil {
+			return err
+		}
+
+		entry := audit.Entry{
+			Action:   action,
+			Entity:   "transaction_limit",
+			EntityID: row.TransactionLimitID,
+			After:    row,
+		}
+		if action == audit.ActionUpdate {
+			entry.Before = before
+		}
+		return audit.Record(tx, audit.ActorFromContext(c), entry)
+	})
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Update success",
+		"data":    row,
+	})
+}
+
+// DeleteOverride menghapus override sehingga akun kembali memakai limit tier (admin only)
+func (a *limitImplement) DeleteOverride(c *gin.Context) {
+	accountID, ok := accountParam(c, a.db)
+	if !ok {
+		return
+	}
+
+	var row model.TransactionLimit
+	err := a.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&row, "account_id = ? AND operation = ?", accountID, c.Param("operation")).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&row).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, audit.ActorFromContext(c), audit.Entry{
+			Action:   audit.ActionDelete,
+			Entity:   "transaction_limit",
+			EntityID: row.TransactionLimitID,
+			Before:   row,
+		})
+	})
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Not found"})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Delete success"})
+}
+
+// SetAccountTier memindahkan akun ke tier lain (admin only)
+func (a *limitImplement) SetAccountTier(c *gin.Context) {
+	var request struct {
+		Tier string `json:"tier" binding:"required"`
+	}
+	if err := c.BindJSON(&request); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !validTier(request.Tier) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Unknown tier"})
+		return
+	}
+	accountID, ok := accountParam(c, a.db)
+	if !ok {
+		return
+	}
+
+	var account model.Account
+	err := a.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&account, accountID).Error; err != nil {
+			return err
+		}
+		before := account
+		account.Tier = request.Tier
+		if err := tx.Model(&account).Update("tier", account.Tier).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, audit.ActorFromContext(c), audit.Entry{
+			Action:   audit.ActionUpdate,
+			Entity:   "account",
+			EntityID: account.AccountID,
+			Before:   before,
+			After:    account,
+		})
+	})
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Update success",
+		"data":    account,
+	})
+}
+
+func validTier(tier string) bool {
+	return tier == model.AccountTierBasic || tier == model.AccountTierPremium
+}
+
+// abortLimitError mengembalikan 422 dengan kode limit yang terlampaui
+func abortLimitError(c *gin.Context, err limit.Error) {
+	c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{
+		"error":     err.Error(),
+		"code":      err.Code,
+		"operation": err.Operation,
+		"limit":     err.Limit,
+		"remaining": err.Remaining,
+	})
+}
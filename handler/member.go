@@ -0,0 +1,462 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"task-golang-db/audit"
+	"task-golang-db/model"
+	"task-golang-db/notification"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// memberInviteTTL adalah masa berlaku undangan member
+const memberInviteTTL = 7 * 24 * time.Hour
+
+var (
+	errInviteClosed      = errors.New("Invite is no longer pending")
+	errAlreadyMember     = errors.New("User is already a member of this account")
+	errLastManager       = errors.New("An account must keep at least one member with manage permission")
+	errInvalidLimit      = errors.New("transact_limit is required and must be positive for transact_limited permission")
+	errUnknownPermission = errors.New("Unknown permission")
+)
+
+type MemberInterface interface {
+	Accounts(*gin.Context)
+	List(*gin.Context)
+	Invite(*gin.Context)
+	Invites(*gin.Context)
+	Accept(*gin.Context)
+	Decline(*gin.Context)
+	Cancel(*gin.Context)
+	Update(*gin.Context)
+	Remove(*gin.Context)
+}
+
+type memberImplement struct {
+	db *gorm.DB
+}
+
+func NewMember(db *gorm.DB) MemberInterface {
+	return &memberImplement{
+		db: db,
+	}
+}
+
+type memberAccount struct {
+	AccountID     int64  `json:"-"`
+	AccountNumber string `json:"account_number"`
+	Name          string `json:"name"`
+	Currency      string `json:"currency"`
+	Status        string `json:"status"`
+	Permission    string `json:"permission"`
+	TransactLimit *int64 `json:"transact_limit"`
+	Home          bool   `json:"home"`
+}
+
+// Accounts menampilkan semua akun yang bisa dipilih login ini lewat header X-Account-ID (requires auth)
+func (a *memberImplement) Accounts(c *gin.Context) {
+	var accounts []memberAccount
+	err := a.db.Table("account_members m").
+		Select("a.account_id, a.account_number, a.name, a.currency, a.status, m.permission, m.transact_limit, a.account_id = ? AS home", c.GetInt64("home_account_id")).
+		Joins("JOIN accounts a ON a.account_id = m.account_id AND a.deleted_at IS NULL").
+		Where("m.auth_id = ?", c.GetInt64("auth_id")).
+		Order("home DESC, a.account_id").
+		Scan(&accounts).Error
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": accounts})
+}
+
+// List menampilkan member akun terpilih (requires auth)
+func (a *memberImplement) List(c *gin.Context) {
+	var members []struct {
+		model.AccountMember
+		Username string `json:"username"`
+	}
+	err := a.db.Table("account_members m").
+		Select("m.*, au.username").
+		Joins("JOIN auths au ON au.auth_id = m.auth_id").
+		Where("m.account_id = ?", c.GetInt64("account_id")).
+		Order("m.account_member_id").
+		Scan(&members).Error
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": members})
+}
+
+// Invite mengundang login lain (berdasarkan username) menjadi member akun terpilih (manage only)
+func (a *memberImplement) Invite(c *gin.Context) {
+	var request struct {
+		Username      string `json:"username" binding:"required"`
+		Permission    string `json:"permission" binding:"required"`
+		TransactLimit *int64 `json:"transact_limit"`
+	}
+	if err := c.BindJSON(&request); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateMemberPermission(request.Permission, request.TransactLimit); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var invitee model.Auth
+	if err := a.db.Where("username = ?", strings.TrimSpace(request.Username)).First(&invitee).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	accountID := c.GetInt64("account_id")
+	invite := model.AccountInvite{
+		AccountID:       accountID,
+		AuthID:          invitee.AuthID,
+		InvitedByAuthID: c.GetInt64("auth_id"),
+		Permission:      request.Permission,
+		TransactLimit:   memberLimit(request.Permission, request.TransactLimit),
+		Status:          model.InvitePending,
+		ExpiresAt:       time.Now().Add(memberInviteTTL),
+	}
+
+	err := a.db.Transaction(func(tx *gorm.DB) error {
+		var existing int64
+		if err := tx.Model(&model.AccountMember{}).Where("account_id = ? AND auth_id = ?", accountID, invitee.AuthID).Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return errAlreadyMember
+		}
+
+		// Undangan lama yang masih pending digantikan undangan baru
+		if err := tx.Model(&model.AccountInvite{}).
+			Where("account_id = ? AND auth_id = ? AND status = ?", accountID, invitee.AuthID, model.InvitePending).
+			Updates(map[string]interface{}{"status": model.InviteCancelled, "responded_at": time.Now()}).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&invite).Error; err != nil {
+			return err
+		}
+		if err := audit.Record(tx, audit.ActorFromContext(c), audit.Entry{
+			Action:   audit.ActionCreate,
+			Entity:   "account_invite",
+			EntityID: invite.AccountInviteID,
+			After:    invite,
+		}); err != nil {
+			return err
+		}
+		var account model.Account
+		if err := tx.Select("account_number").First(&account, accountID).Error; err != nil {
+			return err
+		}
+		return notification.Notify(tx, invitee.AccountID, notification.EventMemberInvited, gin.H{
+			"account_invite_id": invite.AccountInviteID,
+			"account_number":    account.AccountNumber,
+			"invited_by":        c.GetString("username"),
+			"permission":        invite.Permission,
+			"expires_at":        invite.ExpiresAt.Format("2006-01-02 15:04"),
+		})
+	})
+	if err != nil {
+		abortMemberError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Invite sent",
+		"data":    invite,
+	})
+}
+
+// Invites menampilkan undangan pending untuk login ini (requires auth)
+func (a *memberImplement) Invites(c *gin.Context) {
+	var invites []model.AccountInvite
+	err := a.db.Where("auth_id = ? AND status = ? AND expires_at > ?", c.GetInt64("auth_id"), model.InvitePending, time.Now()).
+		Order("created_at DESC").
+		Find(&invites).Error
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": invites})
+}
+
+// Accept menerima undangan dan menjadikan login ini member akun (invitee only)
+func (a *memberImplement) Accept(c *gin.Context) {
+	var member model.AccountMember
+	a.respond(c, "auth_id = ?", c.GetInt64("auth_id"), func(tx *gorm.DB, invite *model.AccountInvite) error {
+		invite.Status = model.InviteAccepted
+		member = model.AccountMember{
+			AccountID:     invite.AccountID,
+			AuthID:        invite.AuthID,
+			Permission:    invite.Permission,
+			TransactLimit: invite.TransactLimit,
+		}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&member)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errAlreadyMember
+		}
+		return audit.Record(tx, audit.ActorFromContext(c), audit.Entry{
+			Action:   audit.ActionCreate,
+			Entity:   "account_member",
+			EntityID: member.AccountMemberID,
+			After:    member,
+		})
+	})
+}
+
+// Decline menolak undangan (invitee only)
+func (a *memberImplement) Decline(c *gin.Context) {
+	a.respond(c, "auth_id = ?", c.GetInt64("auth_id"), func(tx *gorm.DB, invite *model.AccountInvite) error {
+		invite.Status = model.InviteDeclined
+		return nil
+	})
+}
+
+// Cancel membatalkan undangan yang belum dijawab (manage only)
+func (a *memberImplement) Cancel(c *gin.Context) {
+	a.respond(c, "account_id = ?", c.GetInt64("account_id"), func(tx *gorm.DB, invite *model.AccountInvite) error {
+		invite.Status = model.InviteCancelled
+		return nil
+	})
+}
+
+// respond mengunci undangan pending :id yang cocok dengan condition lalu menjalankan apply
+func (a *memberImplement) respond(c *gin.Context, condition string, value int64, apply func(*gorm.DB, *model.AccountInvite) error) {
+	inviteID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid invite id"})
+		return
+	}
+
+	var invite model.AccountInvite
+	err = a.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where(condition, value).
+			First(&invite, "account_invite_id = ?", inviteID).Error; err != nil {
+			return err
+		}
+		if invite.Status != model.InvitePending || time.Now().After(invite.ExpiresAt) {
+			return errInviteClosed
+		}
+
+		before := invite
+		if err := apply(tx, &invite); err != nil {
+			return err
+		}
+		now := time.Now()
+		invite.RespondedAt = &now
+		if err := tx.Model(&invite).Updates(map[string]interface{}{
+			"status":       invite.Status,
+			"responded_at": invite.RespondedAt,
+		}).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, audit.ActorFromContext(c), audit.Entry{
+			Action:   audit.ActionUpdate,
+			Entity:   "account_invite",
+			EntityID: invite.AccountInviteID,
+			Before:   before,
+			After:    invite,
+		})
+	})
+	if err != nil {
+		abortMemberError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Invite " + invite.Status,
+		"data":    invite,
+	})
+}
+
+// Update mengubah permission dan/atau transact_limit member akun terpilih (manage only)
+func (a *memberImplement) Update(c *gin.Context) {
+	var request struct {
+		Permission    string `json:"permission" binding:"required"`
+		TransactLimit *int64 `json:"transact_limit"`
+	}
+	if err := c.BindJSON(&request); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateMemberPermission(request.Permission, request.TransactLimit); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	member, ok := a.change(c, func(tx *gorm.DB, member *model.AccountMember) error {
+		if member.Permission == model.PermissionManage && request.Permission != model.PermissionManage {
+			if err := keepManager(tx, member.AccountID); err != nil {
+				return err
+			}
+		}
+
+		before := *member
+		member.Permission = request.Permission
+		member.TransactLimit = memberLimit(request.Permission, request.TransactLimit)
+		if err := tx.Model(member).Updates(map[string]interface{}{
+			"permission":     member.Permission,
+			"transact_limit": member.TransactLimit,
+			"updated_at":     time.Now(),
+		}).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, audit.ActorFromContext(c), audit.Entry{
+			Action:   audit.ActionUpdate,
+			Entity:   "account_member",
+			EntityID: member.AccountMemberID,
+			Before:   before,
+			After:    member,
+		})
+	})
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Update success",
+		"data":    member,
+	})
+}
+
+// Remove mengeluarkan member dari akun terpilih. Member manage bisa mengeluarkan siapa
+// saja, member lain hanya bisa keluar sendiri (requires auth)
+func (a *memberImplement) Remove(c *gin.Context) {
+	_, ok := a.change(c, func(tx *gorm.DB, member *model.AccountMember) error {
+		if member.AuthID != c.GetInt64("auth_id") && !model.PermissionAllows(c.GetString("permission"), model.PermissionManage) {
+			return errForbidden
+		}
+		if member.Permission == model.PermissionManage {
+			if err := keepManager(tx, member.AccountID); err != nil {
+				return err
+			}
+		}
+		// Pemilik akun (akun utama login) tidak bisa dikeluarkan
+		var home int64
+		if err := tx.Model(&model.Auth{}).Where("auth_id = ? AND account_id = ?", member.AuthID, member.AccountID).Count(&home).Error; err != nil {
+			return err
+		}
+		if home > 0 {
+			return errForbidden
+		}
+
+		if err := tx.Delete(member).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, audit.ActorFromContext(c), audit.Entry{
+			Action:   audit.ActionDelete,
+			Entity:   "account_member",
+			EntityID: member.AccountMemberID,
+			Before:   member,
+		})
+	})
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Remove success"})
+}
+
+// change mengunci member :id di akun terpilih lalu menjalankan apply dalam satu transaksi;
+// response error sudah dikirim jika false
+func (a *memberImplement) change(c *gin.Context, apply func(*gorm.DB, *model.AccountMember) error) (model.AccountMember, bool) {
+	var member model.AccountMember
+	memberID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid member id"})
+		return member, false
+	}
+
+	err = a.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&member, "account_member_id = ? AND account_id = ?", memberID, c.GetInt64("account_id")).Error; err != nil {
+			return err
+		}
+		return apply(tx, &member)
+	})
+	if err != nil {
+		abortMemberError(c, err)
+		return member, false
+	}
+	return member, true
+}
+
+// keepManager memastikan masih ada member manage lain selain yang sedang diubah/dihapus
+func keepManager(tx *gorm.DB, accountID int64) error {
+	var managers []model.AccountMember
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("account_id = ? AND permission = ?", accountID, model.PermissionManage).
+		Find(&managers).Error; err != nil {
+		return err
+	}
+	if len(managers) <= 1 {
+		return errLastManager
+	}
+	return nil
+}
+
+// validateMemberPermission memvalidasi permission beserta batas nominalnya
+func validateMemberPermission(permission string, transactLimit *int64) error {
+	if !model.ValidPermission(permission) {
+		return errUnknownPermission
+	}
+	if permission == model.PermissionTransactLimited && (transactLimit == nil || *transactLimit <= 0) {
+		return errInvalidLimit
+	}
+	return nil
+}
+
+// memberLimit hanya menyimpan transact_limit untuk permission transact_limited
+func memberLimit(permission string, transactLimit *int64) *int64 {
+	if permission != model.PermissionTransactLimited {
+		return nil
+	}
+	return transactLimit
+}
+
+// memberLimitExceeded mengirim 403 jika nominal melewati batas per transaksi member
+// transact_limited pada akun terpilih
+func memberLimitExceeded(c *gin.Context, amount int64) bool {
+	limit, ok := c.Get("transact_limit")
+	if !ok || amount <= limit.(int64) {
+		return false
+	}
+	c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+		"error": "Amount exceeds your transaction limit for this account",
+		"code":  "MEMBER_LIMIT_EXCEEDED",
+		"limit": limit,
+	})
+	return true
+}
+
+// abortMemberError memetakan error member/undangan ke response HTTP
+func abortMemberError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Not found"})
+	case errors.Is(err, errForbidden):
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, errAlreadyMember):
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, errInviteClosed), errors.Is(err, errLastManager):
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
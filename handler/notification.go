@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"net/http"
+	"task-golang-db/model"
+	"task-golang-db/notification"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type NotificationInterface interface {
+	Preferences(*gin.Context)
+	UpdatePreferences(*gin.Context)
+	List(*gin.Context)
+}
+
+type notificationImplement struct {
+	db *gorm.DB
+}
+
+func NewNotification(db *gorm.DB) NotificationInterface {
+	return &notificationImplement{
+		db: db,
+	}
+}
+
+// Preferences menampilkan preferensi notifikasi akun yang login (requires auth)
+func (a *notificationImplement) Preferences(c *gin.Context) {
+	accountID := c.GetInt64("account_id")
+
+	pref := model.NotificationPreference{
+		AccountID: accountID,
+		Language:  notification.DefaultLanguage,
+	}
+	if err := a.db.First(&pref, accountID).Error; err != nil && err != gorm.ErrRecordNotFound {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": pref})
+}
+
+type notificationPreferencePayload struct {
+	Language     string `json:"language"`
+	Email        string `json:"email" binding:"omitempty,email"`
+	Phone        string `json:"phone"`
+	PushToken    string `json:"push_token"`
+	EmailEnabled bool   `json:"email_enabled"`
+	SMSEnabled   bool   `json:"sms_enabled"`
+	PushEnabled  bool   `json:"push_enabled"`
+}
+
+// UpdatePreferences menyimpan bahasa, kontak, dan kanal notifikasi (requires auth)
+func (a *notificationImplement) UpdatePreferences(c *gin.Context) {
+	accountID := c.GetInt64("account_id")
+
+	payload := notificationPreferencePayload{}
+	if err := c.BindJSON(&payload); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if payload.Language == "" {
+		payload.Language = notification.DefaultLanguage
+	}
+	if !notification.SupportedLanguage(payload.Language) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Unsupported language"})
+		return
+	}
+
+	pref := model.NotificationPreference{
+		AccountID:    accountID,
+		Language:     payload.Language,
+		Email:        payload.Email,
+		Phone:        payload.Phone,
+		PushToken:    payload.PushToken,
+		EmailEnabled: payload.EmailEnabled,
+		SMSEnabled:   payload.SMSEnabled,
+		PushEnabled:  payload.PushEnabled,
+	}
+	if err := a.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&pref).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Update success",
+		"data":    pref,
+	})
+}
+
+// List menampilkan riwayat notifikasi akun yang login (requires auth)
+func (a *notificationImplement) List(c *gin.Context) {
+	accountID := c.GetInt64("account_id")
+
+	var notifications []model.Notification
+	if err := a.db.Where("account_id = ?", accountID).
+		Order("notification_id DESC").
+		Limit(100).
+		Find(&notifications).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": notifications})
+}
@@ -0,0 +1,293 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"task-golang-db/accountno"
+	"task-golang-db/audit"
+	"task-golang-db/ledger"
+	"task-golang-db/model"
+	"task-golang-db/notification"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Masa berlaku default dan maksimal payment request
+const (
+	defaultPaymentRequestTTL = 7 * 24 * time.Hour
+	maxPaymentRequestTTL     = 30 * 24 * time.Hour
+)
+
+var errPaymentRequestClosed = errors.New("payment request is no longer pending")
+
+type PaymentRequestInterface interface {
+	Create(*gin.Context)
+	Incoming(*gin.Context)
+	Outgoing(*gin.Context)
+	Read(*gin.Context)
+	Accept(*gin.Context)
+	Decline(*gin.Context)
+	Cancel(*gin.Context)
+}
+
+type paymentRequestImplement struct {
+	db *gorm.DB
+}
+
+func NewPaymentRequest(db *gorm.DB) PaymentRequestInterface {
+	return &paymentRequestImplement{
+		db: db,
+	}
+}
+
+type paymentRequestPayload struct {
+	PayerAccount     accountno.Ref `json:"payer_account_id" binding:"required"`
+	Amount           int64         `json:"amount" binding:"required,gt=0"`
+	Note             string        `json:"note"`
+	ExpiresInSeconds int64         `json:"expires_in_seconds"`
+	ExpiresAt        *time.Time    `json:"expires_at"`
+}
+
+// Create meminta payer membayar akun yang login (requires auth)
+func (a *paymentRequestImplement) Create(c *gin.Context) {
+	accountID := c.GetInt64("account_id")
+
+	payload := paymentRequestPayload{}
+	if err := c.BindJSON(&payload); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	payerAccountID, ok := resolveAccount(c, a.db, payload.PayerAccount)
+	if !ok {
+		return
+	}
+	if payerAccountID == accountID {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Cannot request payment from the same account"})
+		return
+	}
+
+	expiresAt := time.Now().Add(defaultPaymentRequestTTL)
+	if payload.ExpiresAt != nil {
+		expiresAt = *payload.ExpiresAt
+	} else if payload.ExpiresInSeconds > 0 {
+		expiresAt = time.Now().Add(time.Duration(payload.ExpiresInSeconds) * time.Second)
+	}
+	if !expiresAt.After(time.Now()) || expiresAt.After(time.Now().Add(maxPaymentRequestTTL)) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "expires_at must be in the future and within 30 days"})
+		return
+	}
+
+	var requester, payer model.Account
+	if err := a.db.First(&requester, accountID).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+		return
+	}
+	if err := a.db.First(&payer, payerAccountID).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Payer account not found"})
+		return
+	}
+	if err := ledger.CheckMoneyAllowed(requester, payer); err != nil {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if requester.Currency != payer.Currency {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": ledger.ErrCurrencyMismatch.Error()})
+		return
+	}
+
+	request := model.PaymentRequest{
+		RequesterAccountID:     accountID,
+		RequesterAccountNumber: requester.AccountNumber,
+		PayerAccountID:         payer.AccountID,
+		PayerAccountNumber:     payer.AccountNumber,
+		Amount:                 payload.Amount,
+		Currency:               requester.Currency,
+		Note:                   payload.Note,
+		Status:                 model.PaymentRequestPending,
+		ExpiresAt:              expiresAt,
+	}
+	err := a.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&request).Error; err != nil {
+			return err
+		}
+		if err := audit.Record(tx, audit.ActorFromContext(c), audit.Entry{
+			Action:   audit.ActionCreate,
+			Entity:   "payment_request",
+			EntityID: request.PaymentRequestID,
+			After:    request,
+		}); err != nil {
+			return err
+		}
+		return notification.Notify(tx, payer.AccountID, notification.EventPaymentRequestReceived, gin.H{
+			"payment_request_id":       request.PaymentRequestID,
+			"requester_account_number": requester.AccountNumber,
+			"amount":                   request.Amount,
+			"note":                     request.Note,
+			"expires_at":               request.ExpiresAt.Format("2006-01-02 15:04"),
+		})
+	})
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Create success",
+		"data":    request,
+	})
+}
+
+// Incoming menampilkan payment request yang harus dibayar akun yang login
+func (a *paymentRequestImplement) Incoming(c *gin.Context) {
+	a.list(c, "payer_account_id")
+}
+
+// Outgoing menampilkan payment request yang dibuat akun yang login
+func (a *paymentRequestImplement) Outgoing(c *gin.Context) {
+	a.list(c, "requester_account_id")
+}
+
+func (a *paymentRequestImplement) list(c *gin.Context, column string) {
+	var requests []model.PaymentRequest
+	query := a.db.Where(column+" = ?", c.GetInt64("account_id")).Order("payment_request_id DESC")
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Find(&requests).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := accountno.FillAll(a.db, requests); err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": requests})
+}
+
+func (a *paymentRequestImplement) Read(c *gin.Context) {
+	accountID := c.GetInt64("account_id")
+
+	var request model.PaymentRequest
+	if err := a.db.First(&request, "payment_request_id = ? AND (requester_account_id = ? OR payer_account_id = ?)", c.Param("id"), accountID, accountID).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Not found"})
+		return
+	}
+	if err := accountno.Fill(a.db, &request); err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": request})
+}
+
+// Accept membayar payment request dengan transfer dari payer ke requester (payer only)
+func (a *paymentRequestImplement) Accept(c *gin.Context) {
+	var pending model.PaymentRequest
+	if err := a.db.Select("amount").First(&pending, "payment_request_id = ? AND payer_account_id = ?", c.Param("id"), c.GetInt64("account_id")).Error; err == nil && memberLimitExceeded(c, pending.Amount) {
+		return
+	}
+
+	a.respond(c, "payer_account_id", func(tx *gorm.DB, request *model.PaymentRequest) error {
+		result, err := ledger.Transfer(tx, ledger.TransferRequest{
+			FromAccountID: request.PayerAccountID,
+			ToAccountID:   request.RequesterAccountID,
+			Amount:        request.Amount,
+			Actor:         audit.ActorFromContext(c),
+		})
+		if err != nil {
+			return err
+		}
+		request.Status = model.PaymentRequestPaid
+		request.TransactionID = &result.Transaction.TransactionID
+		return notification.Notify(tx, request.RequesterAccountID, notification.EventPaymentRequestPaid, gin.H{
+			"payment_request_id":   request.PaymentRequestID,
+			"payer_account_number": request.PayerAccountNumber,
+			"amount":               request.Amount,
+		})
+	})
+}
+
+// Decline menolak payment request (payer only)
+func (a *paymentRequestImplement) Decline(c *gin.Context) {
+	a.respond(c, "payer_account_id", func(tx *gorm.DB, request *model.PaymentRequest) error {
+		request.Status = model.PaymentRequestDeclined
+		return notification.Notify(tx, request.RequesterAccountID, notification.EventPaymentRequestDeclined, gin.H{
+			"payment_request_id":   request.PaymentRequestID,
+			"payer_account_number": request.PayerAccountNumber,
+			"amount":               request.Amount,
+		})
+	})
+}
+
+// Cancel membatalkan payment request yang belum dibayar (requester only)
+func (a *paymentRequestImplement) Cancel(c *gin.Context) {
+	a.respond(c, "requester_account_id", func(tx *gorm.DB, request *model.PaymentRequest) error {
+		request.Status = model.PaymentRequestCancelled
+		return nil
+	})
+}
+
+// respond mengunci payment request milik akun yang login (sebagai column) lalu menjalankan apply
+func (a *paymentRequestImplement) respond(c *gin.Context, column string, apply func(*gorm.DB, *model.PaymentRequest) error) {
+	requestID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid payment request id"})
+		return
+	}
+
+	var request model.PaymentRequest
+	err = a.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&request, "payment_request_id = ? AND "+column+" = ?", requestID, c.GetInt64("account_id")).Error; err != nil {
+			return err
+		}
+		if request.Status != model.PaymentRequestPending || time.Now().After(request.ExpiresAt) {
+			return errPaymentRequestClosed
+		}
+		if err := accountno.Fill(tx, &request); err != nil {
+			return err
+		}
+
+		before := request
+		if err := apply(tx, &request); err != nil {
+			return err
+		}
+		now := time.Now()
+		request.RespondedAt = &now
+		if err := tx.Model(&request).Updates(map[string]interface{}{
+			"status":         request.Status,
+			"transaction_id": request.TransactionID,
+			"responded_at":   request.RespondedAt,
+		}).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, audit.ActorFromContext(c), audit.Entry{
+			Action:   audit.ActionUpdate,
+			Entity:   "payment_request",
+			EntityID: request.PaymentRequestID,
+			Before:   before,
+			After:    request,
+		})
+	})
+	if err != nil {
+		switch err {
+		case gorm.ErrRecordNotFound:
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Not found"})
+		case errPaymentRequestClosed:
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error(), "status": request.Status})
+		default:
+			abortTransferError(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Update success",
+		"data":    request,
+	})
+}
@@ -0,0 +1,270 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"task-golang-db/audit"
+	"task-golang-db/ledger"
+	"task-golang-db/model"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type PocketInterface interface {
+	Create(*gin.Context)
+	List(*gin.Context)
+	Read(*gin.Context)
+	Update(*gin.Context)
+	Deposit(*gin.Context)
+	Withdraw(*gin.Context)
+	Close(*gin.Context)
+}
+
+type pocketImplement struct {
+	db *gorm.DB
+}
+
+func NewPocket(db *gorm.DB) PocketInterface {
+	return &pocketImplement{
+		db: db,
+	}
+}
+
+type pocketPayload struct {
+	Name         string     `json:"name" binding:"required,max=60"`
+	TargetAmount int64      `json:"target_amount" binding:"gte=0"`
+	Deadline     *time.Time `json:"deadline"`
+}
+
+// Create membuat pocket baru untuk akun yang login (requires auth)
+func (a *pocketImplement) Create(c *gin.Context) {
+	payload := pocketPayload{}
+	if err := c.BindJSON(&payload); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if payload.Deadline != nil && payload.Deadline.Before(time.Now()) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "deadline must be in the future"})
+		return
+	}
+
+	pocket := model.Pocket{
+		AccountID:    c.GetInt64("account_id"),
+		Name:         payload.Name,
+		TargetAmount: payload.TargetAmount,
+		Deadline:     payload.Deadline,
+		Status:       model.PocketActive,
+	}
+	err := a.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&pocket).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, audit.ActorFromContext(c), audit.Entry{
+			Action:   audit.ActionCreate,
+			Entity:   "pocket",
+			EntityID: pocket.PocketID,
+			After:    pocket,
+		})
+	})
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Create success",
+		"data":    pocket,
+	})
+}
+
+// List menampilkan pocket milik akun yang login; ?status=closed untuk pocket yang sudah ditutup
+func (a *pocketImplement) List(c *gin.Context) {
+	var pockets []model.Pocket
+	if err := a.db.Where("account_id = ? AND status = ?", c.GetInt64("account_id"), c.DefaultQuery("status", model.PocketActive)).
+		Order("pocket_id").
+		Find(&pockets).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": pockets})
+}
+
+func (a *pocketImplement) Read(c *gin.Context) {
+	var pocket model.Pocket
+	if err := a.db.First(&pocket, "pocket_id = ? AND account_id = ?", c.Param("id"), c.GetInt64("account_id")).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":     pocket,
+		"progress": pocket.Progress(),
+	})
+}
+
+// Update mengubah nama, target, dan deadline pocket
+func (a *pocketImplement) Update(c *gin.Context) {
+	payload := pocketPayload{}
+	if err := c.BindJSON(&payload); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var pocket model.Pocket
+	err := a.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		pocket, err = a.lock(tx, c)
+		if err != nil {
+			return err
+		}
+		if pocket.Status != model.PocketActive {
+			return ledger.ErrPocketClosed
+		}
+
+		before := pocket
+		pocket.Name = payload.Name
+		pocket.TargetAmount = payload.TargetAmount
+		pocket.Deadline = payload.Deadline
+		if err := tx.Model(&pocket).Updates(map[string]interface{}{
+			"name":          pocket.Name,
+			"target_amount": pocket.TargetAmount,
+			"deadline":      pocket.Deadline,
+		}).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, audit.ActorFromContext(c), audit.Entry{
+			Action:   audit.ActionUpdate,
+			Entity:   "pocket",
+			EntityID: pocket.PocketID,
+			Before:   before,
+			After:    pocket,
+		})
+	})
+	if err != nil {
+		abortPocketError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Update success",
+		"data":    pocket,
+	})
+}
+
+type pocketMovePayload struct {
+	Amount int64 `json:"amount" binding:"required,gt=0"`
+}
+
+// Deposit memindahkan saldo utama ke pocket
+func (a *pocketImplement) Deposit(c *gin.Context) {
+	a.move(c, 1)
+}
+
+// Withdraw memindahkan saldo pocket kembali ke saldo utama
+func (a *pocketImplement) Withdraw(c *gin.Context) {
+	a.move(c, -1)
+}
+
+func (a *pocketImplement) move(c *gin.Context, sign int64) {
+	payload := pocketMovePayload{}
+	if err := c.BindJSON(&payload); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var pocket model.Pocket
+	var transaction model.Transaction
+	err := a.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		pocket, err = a.lock(tx, c)
+		if err != nil {
+			return err
+		}
+		transaction, err = ledger.MovePocket(tx, &pocket, sign*payload.Amount, audit.ActorFromContext(c))
+		return err
+	})
+	if err != nil {
+		abortPocketError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Move success",
+		"data":        pocket,
+		"transaction": transaction,
+	})
+}
+
+// Close memindahkan sisa saldo pocket ke saldo utama lalu menutup pocket
+func (a *pocketImplement) Close(c *gin.Context) {
+	var pocket model.Pocket
+	err := a.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		pocket, err = a.lock(tx, c)
+		if err != nil {
+			return err
+		}
+		if pocket.Status != model.PocketActive {
+			return ledger.ErrPocketClosed
+		}
+		if pocket.Balance > 0 {
+			if _, err := ledger.MovePocket(tx, &pocket, -pocket.Balance, audit.ActorFromContext(c)); err != nil {
+				return err
+			}
+		}
+
+		before := pocket
+		now := time.Now()
+		pocket.Status = model.PocketClosed
+		pocket.ClosedAt = &now
+		if err := tx.Model(&pocket).Updates(map[string]interface{}{
+			"status":    pocket.Status,
+			"closed_at": pocket.ClosedAt,
+		}).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, audit.ActorFromContext(c), audit.Entry{
+			Action:   audit.ActionDelete,
+			Entity:   "pocket",
+			EntityID: pocket.PocketID,
+			Before:   before,
+			After:    pocket,
+		})
+	})
+	if err != nil {
+		abortPocketError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Close success",
+		"data":    pocket,
+	})
+}
+
+// lock mengunci pocket milik akun yang login berdasarkan :id
+func (a *pocketImplement) lock(tx *gorm.DB, c *gin.Context) (model.Pocket, error) {
+	pocketID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return model.Pocket{}, ledger.ErrPocketNotFound
+	}
+	return ledger.LockPocket(tx, c.GetInt64("account_id"), pocketID)
+}
+
+// abortPocketError memetakan error pocket ke response HTTP
+func abortPocketError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ledger.ErrPocketNotFound):
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Pocket not found"})
+	case errors.Is(err, ledger.ErrPocketClosed):
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, ledger.ErrPocketInsufficient):
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		abortTransferError(c, err)
+	}
+}
@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"task-golang-db/accountno"
+	"task-golang-db/ledger"
+	"task-golang-db/model"
+	"task-golang-db/qrpay"
+
+	"github.com/gin-gonic/gin"
+	"github.com/skip2/go-qrcode"
+	"gorm.io/gorm"
+)
+
+type QRInterface interface {
+	Receive(*gin.Context)
+	Scan(*gin.Context)
+}
+
+type qrImplement struct {
+	db *gorm.DB
+}
+
+func NewQR(db *gorm.DB) QRInterface {
+	return &qrImplement{
+		db: db,
+	}
+}
+
+// Receive membuat QR untuk menerima uang ke akun yang login. Tanpa ?amount QR bersifat
+// static (pembayar mengisi nominal); dengan ?amount nominal dikunci di QR. Default
+// response berupa PNG (?size=128..1024), ?format=json mengembalikan payload (requires auth)
+func (a *qrImplement) Receive(c *gin.Context) {
+	var amount int64
+	if value := c.Query("amount"); value != "" {
+		var err error
+		amount, err = strconv.ParseInt(value, 10, 64)
+		if err != nil || amount <= 0 {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "amount must be a positive integer"})
+			return
+		}
+	}
+	size := 256
+	if value := c.Query("size"); value != "" {
+		var err error
+		size, err = strconv.Atoi(value)
+		if err != nil || size < 128 || size > 1024 {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "size must be between 128 and 1024"})
+			return
+		}
+	}
+
+	var account model.Account
+	if err := a.db.First(&account, c.GetInt64("account_id")).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+		return
+	}
+	if err := ledger.CheckMoneyAllowed(account); err != nil {
+		abortTransferError(c, err)
+		return
+	}
+	if account.AccountNumber == "" {
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "Account has no account number yet"})
+		return
+	}
+
+	payment := qrpay.Payment{
+		AccountNumber: account.AccountNumber,
+		Name:          account.Name,
+		Currency:      account.Currency,
+		Amount:        amount,
+	}
+	payload, err := qrpay.Build(qrpay.ConfigFromEnv(), payment)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if c.Query("format") == "json" {
+		c.JSON(http.StatusOK, gin.H{
+			"data": gin.H{
+				"payload":        payload,
+				"account_number": payment.AccountNumber,
+				"currency":       payment.Currency,
+				"amount":         payment.Amount,
+				"dynamic":        payment.Dynamic(),
+			},
+		})
+		return
+	}
+
+	png, err := qrcode.Encode(payload, qrcode.Medium, size)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Data(http.StatusOK, "image/png", png)
+}
+
+// Scan membaca payload QR hasil scan, memvalidasi CRC, lalu memulai transfer inquiry
+// ke akun di QR. amount wajib untuk QR static; untuk QR dynamic boleh kosong atau sama
+// dengan nominal di QR. Lanjutkan dengan /account/transfer/confirm (requires auth)
+func (a *qrImplement) Scan(c *gin.Context) {
+	var request struct {
+		Payload string `json:"payload" binding:"required"`
+		Amount  int64  `json:"amount"`
+	}
+	if err := c.BindJSON(&request); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	payment, err := qrpay.Parse(qrpay.ConfigFromEnv(), request.Payload)
+	if err != nil {
+		if errors.Is(err, qrpay.ErrNotSupported) {
+			c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	amount := request.Amount
+	switch {
+	case payment.Dynamic() && amount != 0 && amount != payment.Amount:
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "amount does not match the amount in the QR"})
+		return
+	case payment.Dynamic():
+		amount = payment.Amount
+	case amount <= 0:
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "amount is required for a static QR"})
+		return
+	}
+	if memberLimitExceeded(c, amount) {
+		return
+	}
+
+	toAccountID, ok := resolveAccount(c, a.db, accountno.Ref(payment.AccountNumber))
+	if !ok {
+		return
+	}
+	// Mata uang di QR harus sama dengan akun tujuan supaya nominal tidak ditafsirkan beda
+	var receiver model.Account
+	if err := a.db.First(&receiver, toAccountID).Error; err != nil {
+		abortTransferError(c, ledger.ErrReceiverNotFound)
+		return
+	}
+	if receiver.Currency != payment.Currency {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "QR currency does not match the target account"})
+		return
+	}
+
+	startInquiry(c, a.db, toAccountID, amount)
+}
@@ -0,0 +1,221 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"task-golang-db/audit"
+	"task-golang-db/model"
+	"task-golang-db/reconcile"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var errTicketClosed = errors.New("Correction ticket is already closed")
+
+type ReconciliationInterface interface {
+	Status(*gin.Context)
+	Metrics(*gin.Context)
+	Runs(*gin.Context)
+	Read(*gin.Context)
+	Run(*gin.Context)
+	Check(*gin.Context)
+	Tickets(*gin.Context)
+	UpdateTicket(*gin.Context)
+}
+
+type reconciliationImplement struct {
+	db *gorm.DB
+}
+
+func NewReconciliation(db *gorm.DB) ReconciliationInterface {
+	return &reconciliationImplement{
+		db: db,
+	}
+}
+
+// Status menampilkan run terakhir, run sukses terakhir, dan jumlah tiket open (admin only)
+func (a *reconciliationImplement) Status(c *gin.Context) {
+	status, err := reconcile.CurrentStatus(a.db)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": status})
+}
+
+// Metrics menampilkan status rekonsiliasi dalam format Prometheus (admin only)
+func (a *reconciliationImplement) Metrics(c *gin.Context) {
+	metrics, err := reconcile.Metrics(a.db)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Data(http.StatusOK, "text/plain; version=0.0.4", []byte(metrics))
+}
+
+// Runs menampilkan 50 run rekonsiliasi terakhir (admin only)
+func (a *reconciliationImplement) Runs(c *gin.Context) {
+	var runs []model.ReconciliationRun
+	if err := a.db.Order("started_at DESC").Limit(50).Find(&runs).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": runs})
+}
+
+// Read menampilkan satu run beserta semua selisih yang ditemukan (admin only)
+func (a *reconciliationImplement) Read(c *gin.Context) {
+	runID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Reconciliation run not found"})
+		return
+	}
+	var run model.ReconciliationRun
+	if err := a.db.First(&run, runID).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Reconciliation run not found"})
+		return
+	}
+
+	var discrepancies []model.ReconciliationDiscrepancy
+	if err := a.db.Where("reconciliation_run_id = ?", run.ReconciliationRunID).Order("account_id, field").Find(&discrepancies).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":          run,
+		"discrepancies": discrepancies,
+	})
+}
+
+// Run menjalankan rekonsiliasi semua akun sekarang; ?open_tickets=true membuka tiket
+// koreksi untuk setiap selisih (admin only)
+func (a *reconciliationImplement) Run(c *gin.Context) {
+	run, err := reconcile.Run(a.db, reconcile.Options{
+		Trigger:     reconcile.TriggerManual,
+		OpenTickets: c.Query("open_tickets") == "true",
+		Actor:       audit.ActorFromContext(c),
+	})
+	if err != nil {
+		if errors.Is(err, reconcile.ErrRunInProgress) {
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if run.ReconciliationRunID == 0 {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Run " + run.Status,
+		"data":    run,
+	})
+}
+
+// Check mencocokkan satu akun tanpa menyimpan hasil (admin only)
+func (a *reconciliationImplement) Check(c *gin.Context) {
+	accountID, ok := accountParam(c, a.db)
+	if !ok {
+		return
+	}
+
+	discrepancies, err := reconcile.Check(a.db, accountID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"account_id":    accountID,
+		"balanced":      len(discrepancies) == 0,
+		"discrepancies": discrepancies,
+	})
+}
+
+// Tickets menampilkan tiket koreksi, default yang masih open (?status=all untuk semua) (admin only)
+func (a *reconciliationImplement) Tickets(c *gin.Context) {
+	query := a.db.Order("created_at DESC")
+	if status := c.DefaultQuery("status", model.TicketOpen); status != "all" {
+		query = query.Where("status = ?", status)
+	}
+
+	var tickets []model.CorrectionTicket
+	if err := query.Find(&tickets).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": tickets})
+}
+
+// UpdateTicket menutup tiket koreksi sebagai resolved atau dismissed beserta catatan (admin only)
+func (a *reconciliationImplement) UpdateTicket(c *gin.Context) {
+	var request struct {
+		Status string `json:"status" binding:"required,oneof=resolved dismissed"`
+		Note   string `json:"note" binding:"required"`
+	}
+	if err := c.BindJSON(&request); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ticketID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Correction ticket not found"})
+		return
+	}
+	var ticket model.CorrectionTicket
+	err = a.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&ticket, ticketID).Error; err != nil {
+			return err
+		}
+		if ticket.Status != model.TicketOpen {
+			return errTicketClosed
+		}
+
+		before := ticket
+		now := time.Now()
+		authID := c.GetInt64("auth_id")
+		ticket.Status = request.Status
+		ticket.Note = request.Note
+		ticket.ResolvedByAuthID = &authID
+		ticket.ResolvedAt = &now
+		if err := tx.Save(&ticket).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, audit.ActorFromContext(c), audit.Entry{
+			Action:   audit.ActionUpdate,
+			Entity:   "correction_ticket",
+			EntityID: ticket.CorrectionTicketID,
+			Before:   before,
+			After:    ticket,
+		})
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Correction ticket not found"})
+		case errors.Is(err, errTicketClosed):
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Update success",
+		"data":    ticket,
+	})
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"net/http"
+	"task-golang-db/accountno"
+	"task-golang-db/model"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ReferralInterface interface {
+	My(*gin.Context)
+}
+
+type referralImplement struct {
+	db *gorm.DB
+}
+
+func NewReferral(db *gorm.DB) ReferralInterface {
+	return &referralImplement{
+		db: db,
+	}
+}
+
+// My menampilkan kode referral, akun yang diajak, dan total bonus yang didapat (requires auth)
+func (a *referralImplement) My(c *gin.Context) {
+	accountID := c.GetInt64("account_id")
+
+	var account model.Account
+	if err := a.db.First(&account, accountID).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+		return
+	}
+
+	var referrals []model.Referral
+	if err := a.db.Where("referrer_account_id = ?", accountID).Order("referral_id DESC").Find(&referrals).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := accountno.FillAll(a.db, referrals); err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Akun yang mengajak ditampilkan dengan nomor rekening
+	var referrer model.Account
+	if account.ReferralAccountID != nil {
+		if err := a.db.Unscoped().Select("account_number").First(&referrer, *account.ReferralAccountID).Error; err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	var earned int64
+	counts := map[string]int{
+		model.ReferralPending:  0,
+		model.ReferralRewarded: 0,
+		model.ReferralRejected: 0,
+	}
+	for _, referral := range referrals {
+		counts[referral.Status]++
+		if referral.Status == model.ReferralRewarded {
+			earned += referral.ReferrerBonus
+		}
+	}
+
+	// Bonus yang didapat akun ini sebagai pihak yang diajak
+	var received int64
+	if err := a.db.Model(&model.Referral{}).
+		Select("COALESCE(SUM(referee_bonus), 0)").
+		Where("referee_account_id = ? AND status = ?", accountID, model.ReferralRewarded).
+		Scan(&received).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"referral_code":           account.ReferralCode,
+		"referrer_account_number": referrer.AccountNumber,
+		"total_earned":            earned + received,
+		"earned_as_referrer":      earned,
+		"earned_as_referee":       received,
+		"counts":                  counts,
+		"data":                    referrals,
+	})
+}
@@ -0,0 +1,261 @@
+package handler
+
+import (
+	"net/http"
+	"task-golang-db/accountno"
+	"task-golang-db/audit"
+	"task-golang-db/model"
+	"task-golang-db/scheduler"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type StandingOrderInterface interface {
+	Create(*gin.Context)
+	List(*gin.Context)
+	Read(*gin.Context)
+	Executions(*gin.Context)
+	Pause(*gin.Context)
+	Resume(*gin.Context)
+	Cancel(*gin.Context)
+}
+
+type standingOrderImplement struct {
+	db *gorm.DB
+}
+
+func NewStandingOrder(db *gorm.DB) StandingOrderInterface {
+	return &standingOrderImplement{
+		db: db,
+	}
+}
+
+type standingOrderPayload struct {
+	ToAccount    accountno.Ref `json:"to_account_id" binding:"required"`
+	Amount       int64         `json:"amount" binding:"required,gt=0"`
+	Description  string        `json:"description"`
+	ScheduleType string        `json:"schedule_type" binding:"required"`
+	CronExpr     string        `json:"cron_expr"`
+	StartAt      time.Time     `json:"start_at" binding:"required"`
+	EndAt        *time.Time    `json:"end_at"`
+	MaxCount     *int          `json:"max_count"`
+}
+
+// Create membuat transfer terjadwal milik akun yang login (requires auth)
+func (a *standingOrderImplement) Create(c *gin.Context) {
+	accountID := c.GetInt64("account_id")
+
+	payload := standingOrderPayload{}
+	if err := c.BindJSON(&payload); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if memberLimitExceeded(c, payload.Amount) {
+		return
+	}
+
+	toAccountID, ok := resolveAccount(c, a.db, payload.ToAccount)
+	if !ok {
+		return
+	}
+	if toAccountID == accountID {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Cannot schedule a transfer to the same account"})
+		return
+	}
+	if payload.StartAt.Before(time.Now().Add(-time.Minute)) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "start_at must be in the future"})
+		return
+	}
+
+	var receiver model.Account
+	if err := a.db.First(&receiver, toAccountID).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Target account not found"})
+		return
+	}
+
+	order := model.StandingOrder{
+		AccountID:       accountID,
+		ToAccountID:     toAccountID,
+		ToAccountNumber: receiver.AccountNumber,
+		Amount:          payload.Amount,
+		Description:     payload.Description,
+		ScheduleType:    payload.ScheduleType,
+		CronExpr:        payload.CronExpr,
+		StartAt:         payload.StartAt,
+		EndAt:           payload.EndAt,
+		MaxCount:        payload.MaxCount,
+		Status:          model.StandingOrderActive,
+	}
+	if err := scheduler.Validate(order); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	first, err := scheduler.FirstRun(order)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	order.NextRunAt = &first
+
+	err = a.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&order).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, audit.ActorFromContext(c), audit.Entry{
+			Action:   audit.ActionCreate,
+			Entity:   "standing_order",
+			EntityID: order.StandingOrderID,
+			After:    order,
+		})
+	})
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Create success",
+		"data":    order,
+	})
+}
+
+// List menampilkan standing order milik akun yang login (requires auth)
+func (a *standingOrderImplement) List(c *gin.Context) {
+	accountID := c.GetInt64("account_id")
+
+	var orders []model.StandingOrder
+	query := a.db.Where("account_id = ?", accountID).Order("standing_order_id DESC")
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Find(&orders).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := accountno.FillAll(a.db, orders); err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": orders})
+}
+
+func (a *standingOrderImplement) Read(c *gin.Context) {
+	var order model.StandingOrder
+	if err := a.db.First(&order, "standing_order_id = ? AND account_id = ?", c.Param("id"), c.GetInt64("account_id")).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Not found"})
+		return
+	}
+	if err := accountno.Fill(a.db, &order); err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": order})
+}
+
+// Executions menampilkan riwayat eksekusi sebuah standing order
+func (a *standingOrderImplement) Executions(c *gin.Context) {
+	var order model.StandingOrder
+	if err := a.db.First(&order, "standing_order_id = ? AND account_id = ?", c.Param("id"), c.GetInt64("account_id")).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Not found"})
+		return
+	}
+
+	var executions []model.StandingOrderExecution
+	if err := a.db.Where("standing_order_id = ?", order.StandingOrderID).
+		Order("standing_order_execution_id DESC").
+		Find(&executions).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": executions})
+}
+
+func (a *standingOrderImplement) Pause(c *gin.Context) {
+	a.changeStatus(c, []string{model.StandingOrderActive}, model.StandingOrderPaused)
+}
+
+func (a *standingOrderImplement) Resume(c *gin.Context) {
+	a.changeStatus(c, []string{model.StandingOrderPaused}, model.StandingOrderActive)
+}
+
+func (a *standingOrderImplement) Cancel(c *gin.Context) {
+	a.changeStatus(c, []string{model.StandingOrderActive, model.StandingOrderPaused}, model.StandingOrderCancelled)
+}
+
+// changeStatus memindahkan status standing order milik akun yang login
+func (a *standingOrderImplement) changeStatus(c *gin.Context, from []string, to string) {
+	var order model.StandingOrder
+	err := a.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&order, "standing_order_id = ? AND account_id = ?", c.Param("id"), c.GetInt64("account_id")).Error; err != nil {
+			return err
+		}
+
+		allowed := false
+		for _, status := range from {
+			if order.Status == status {
+				allowed = true
+			}
+		}
+		if !allowed {
+			return errInvalidTransition
+		}
+
+		before := order
+		updates := map[string]interface{}{"status": to}
+
+		// Jadwal yang terlewat selama pause tidak dieksekusi susulan
+		if to == model.StandingOrderActive && order.NextRunAt != nil {
+			next := *order.NextRunAt
+			for next.Before(time.Now()) && order.ScheduleType != model.ScheduleOnce {
+				n, ok := scheduler.NextRun(order, next)
+				if !ok {
+					updates["status"] = model.StandingOrderCompleted
+					break
+				}
+				next = n
+			}
+			updates["next_run_at"] = next
+			updates["retry_count"] = 0
+			updates["scheduled_for"] = nil
+		}
+
+		if err := tx.Model(&order).Updates(updates).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, audit.ActorFromContext(c), audit.Entry{
+			Action:   audit.ActionUpdate,
+			Entity:   "standing_order",
+			EntityID: order.StandingOrderID,
+			Before:   before,
+			After:    order,
+		})
+	})
+	if err != nil {
+		switch err {
+		case gorm.ErrRecordNotFound:
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Not found"})
+		case errInvalidTransition:
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "Standing order is " + order.Status})
+		default:
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	if err := accountno.Fill(a.db, &order); err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Update success",
+		"data":    order,
+	})
+}
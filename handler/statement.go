@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"os"
+	"task-golang-db/accountno"
+	"task-golang-db/model"
+	"task-golang-db/statement"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type StatementInterface interface {
+	Download(*gin.Context)
+}
+
+type statementImplement struct {
+	db  *gorm.DB
+	dir string
+}
+
+func NewStatement(db *gorm.DB, dir string) StatementInterface {
+	return &statementImplement{
+		db:  db,
+		dir: dir,
+	}
+}
+
+// Download mengunduh rekening koran ?month=YYYY-MM&format=csv|pdf|json (requires auth).
+// Admin bisa mengunduh akun lain dengan ?account_id=.
+func (a *statementImplement) Download(c *gin.Context) {
+	accountID := c.GetInt64("account_id")
+	if value := c.Query("account_id"); value != "" {
+		id, ok := resolveAccount(c, a.db, accountno.Ref(value))
+		if !ok {
+			return
+		}
+		if id != accountID && c.GetString("role") != model.RoleAdmin {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": errForbidden.Error()})
+			return
+		}
+		accountID = id
+	}
+
+	month, err := statement.ParseMonth(c.Query("month"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	format := c.DefaultQuery("format", statement.FormatPDF)
+	if format != statement.FormatCSV && format != statement.FormatPDF && format != "json" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": statement.ErrUnknownFormat.Error()})
+		return
+	}
+	filename := fmt.Sprintf("statement-%d-%s.%s", accountID, month.Format("2006-01"), format)
+
+	// Bulan yang sudah lewat dilayani dari file hasil job malam jika tersedia
+	currentMonth := time.Date(time.Now().Year(), time.Now().Month(), 1, 0, 0, 0, 0, time.Local)
+	if format != "json" && month.Before(currentMonth) {
+		path := statement.Path(a.dir, accountID, month.Format("2006-01"), format)
+		if _, err := os.Stat(path); err == nil {
+			c.FileAttachment(path, filename)
+			return
+		}
+	}
+
+	st, err := statement.Generate(a.db, accountID, month)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if format == "json" {
+		c.JSON(http.StatusOK, gin.H{"data": st})
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := statement.Write(&buf, st, format); err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, statement.ContentType(format), buf.Bytes())
+}
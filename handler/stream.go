@@ -0,0 +1,235 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"task-golang-db/model"
+	"task-golang-db/realtime"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+	"gorm.io/gorm"
+)
+
+type StreamInterface interface {
+	Ticket(*gin.Context)
+	SSE(*gin.Context)
+	WebSocket(*gin.Context)
+}
+
+type streamImplement struct {
+	db  *gorm.DB
+	hub *realtime.Hub
+}
+
+func NewStream(db *gorm.DB, hub *realtime.Hub) StreamInterface {
+	return &streamImplement{
+		db:  db,
+		hub: hub,
+	}
+}
+
+// Interval heartbeat supaya koneksi tidak diputus proxy
+const streamHeartbeat = 25 * time.Second
+
+// Masa berlaku stream ticket, cukup untuk membuka koneksi setelah tiket diterima
+const streamTicketTTL = 30 * time.Second
+
+// Ticket menerbitkan tiket sekali pakai untuk /stream/events?ticket= dan /stream/ws?ticket=
+// dari browser, untuk akun terpilih (X-Account-ID). Tiket hangus setelah dipakai, jadi
+// saat reconnect client meminta tiket baru dan mengirim last_event_id (requires auth)
+func (a *streamImplement) Ticket(c *gin.Context) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Tiket kedaluwarsa yang tidak pernah dipakai dibersihkan sekalian
+	if err := a.db.Where("expires_at < now()").Delete(&model.StreamTicket{}).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ticket := model.StreamTicket{
+		StreamTicketID: hex.EncodeToString(token),
+		AuthID:         c.GetInt64("auth_id"),
+		AccountID:      c.GetInt64("account_id"),
+		ExpiresAt:      time.Now().Add(streamTicketTTL),
+	}
+	if err := a.db.Create(&ticket).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": ticket})
+}
+
+var errInvalidLastEventID = errors.New("last event id must be a non-negative integer")
+
+// resumeFrom menentukan posisi awal stream dari header Last-Event-ID atau query
+// last_event_id. Koneksi baru tanpa posisi resume mulai dari event terbaru akun supaya
+// tidak me-replay seluruh riwayat. Panggil setelah Subscribe.
+func resumeFrom(c *gin.Context, db *gorm.DB, accountID int64) (int64, error) {
+	value := c.GetHeader("Last-Event-ID")
+	if value == "" {
+		value = c.Query("last_event_id")
+	}
+	if value == "" {
+		return realtime.LatestID(db, accountID)
+	}
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id < 0 {
+		return 0, errInvalidLastEventID
+	}
+	return id, nil
+}
+
+// SSE mengirim perubahan saldo dan mutasi baru milik akun yang login. Jika resume
+// tertinggal lebih dari realtime.MaxBacklog event, dikirim event resync. Jika client
+// terlalu lambat, stream diakhiri dan client resume dengan Last-Event-ID (requires auth)
+func (a *streamImplement) SSE(c *gin.Context) {
+	accountID := c.GetInt64("account_id")
+
+	// Subscribe sebelum replay supaya tidak ada event yang terlewat
+	events, unsubscribe := a.hub.Subscribe(accountID)
+	defer unsubscribe()
+
+	lastID, err := resumeFrom(c, a.db, accountID)
+	if err == errInvalidLastEventID {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	backlog, err := realtime.Since(a.db, accountID, lastID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	write := func(event model.AccountEvent) {
+		if event.AccountEventID <= lastID {
+			return
+		}
+		fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", event.AccountEventID, event.EventType, event.Payload)
+		lastID = event.AccountEventID
+	}
+
+	if backlog.Reset {
+		fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: {\"latest_event_id\":%d}\n\n", backlog.LatestID, realtime.EventResync, backlog.LatestID)
+		lastID = backlog.LatestID
+	}
+	for _, event := range backlog.Events {
+		write(event)
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			write(event)
+			c.Writer.Flush()
+		case <-heartbeat.C:
+			io.WriteString(c.Writer, ": ping\n\n")
+			c.Writer.Flush()
+		}
+	}
+}
+
+// WebSocket mengirim event yang sama dengan SSE dalam bentuk pesan JSON. Koneksi
+// ditutup jika client terlalu lambat; reconnect dengan last_event_id (requires auth)
+func (a *streamImplement) WebSocket(c *gin.Context) {
+	accountID := c.GetInt64("account_id")
+
+	websocket.Handler(func(ws *websocket.Conn) {
+		defer ws.Close()
+
+		events, unsubscribe := a.hub.Subscribe(accountID)
+		defer unsubscribe()
+
+		lastID, err := resumeFrom(c, a.db, accountID)
+		if err != nil {
+			websocket.JSON.Send(ws, gin.H{"error": err.Error()})
+			return
+		}
+
+		// Client tidak mengirim data; goroutine ini hanya mendeteksi koneksi ditutup
+		closed := make(chan struct{})
+		go func() {
+			defer close(closed)
+			var discard string
+			for websocket.Message.Receive(ws, &discard) == nil {
+			}
+		}()
+
+		send := func(event model.AccountEvent) error {
+			if event.AccountEventID <= lastID {
+				return nil
+			}
+			lastID = event.AccountEventID
+			return websocket.JSON.Send(ws, event)
+		}
+
+		backlog, err := realtime.Since(a.db, accountID, lastID)
+		if err != nil {
+			websocket.JSON.Send(ws, gin.H{"error": err.Error()})
+			return
+		}
+		if backlog.Reset {
+			if err := websocket.JSON.Send(ws, gin.H{"event_type": realtime.EventResync, "latest_event_id": backlog.LatestID}); err != nil {
+				return
+			}
+			lastID = backlog.LatestID
+		}
+		for _, event := range backlog.Events {
+			if err := send(event); err != nil {
+				return
+			}
+		}
+
+		heartbeat := time.NewTicker(streamHeartbeat)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case <-closed:
+				return
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				if err := send(event); err != nil {
+					return
+				}
+			case <-heartbeat.C:
+				if err := websocket.JSON.Send(ws, gin.H{"event_type": "ping"}); err != nil {
+					return
+				}
+			}
+		}
+	}).ServeHTTP(c.Writer, c.Request)
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"net/http"
+	"task-golang-db/audit"
 	"task-golang-db/model"
 
 	"github.com/gin-gonic/gin"
@@ -41,10 +42,20 @@ func (a *transcatImplement) Create(c *gin.Context) {
 	}
 
 	// Create data
-	result := a.db.Create(&payload)
-	if result.Error != nil {
+	err = a.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&payload).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, audit.ActorFromContext(c), audit.Entry{
+			Action:   audit.ActionCreate,
+			Entity:   "transaction_category",
+			EntityID: payload.TransactionCategoryID,
+			After:    payload,
+		})
+	})
+	if err != nil {
 		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"error": result.Error.Error(),
+			"error": err.Error(),
 		})
 		return
 	}
@@ -63,7 +74,7 @@ func (a *transcatImplement) Read(c *gin.Context) {
 	id := c.Param("id")
 
 	// Find first data based on id and put to transcatImplement model
-	if err := a.db.First(&transcatImplement, id).Error; err != nil {
+	if err := a.db.First(&transcatImplement, "transaction_category_id = ?", id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
 				"error": "Not found",
@@ -77,6 +88,10 @@ func (a *transcatImplement) Read(c *gin.Context) {
 		return
 	}
 
+	if notModified(c, transcatImplement.Version) {
+		return
+	}
+
 	// Success response
 	c.JSON(http.StatusOK, gin.H{
 		"data": transcatImplement,
@@ -114,13 +129,50 @@ func (a *transcatImplement) Update(c *gin.Context) {
 		return
 	}
 
-	// Update data
+	if preconditionFailed(c, transcatImplement.Version) {
+		return
+	}
+
+	// Update data, hanya jika version belum diubah request lain sejak dibaca
+	before := transcatImplement
 	transcatImplement.Name = payload.Name
-	a.db.Save(transcatImplement)
+	err = a.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.TransCat{}).
+			Where("transaction_category_id = ? AND version = ?", transcatImplement.TransactionCategoryID, before.Version).
+			Update("name", transcatImplement.Name)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errVersionConflict
+		}
+		transcatImplement.Version = before.Version + 1
+		return audit.Record(tx, audit.ActorFromContext(c), audit.Entry{
+			Action:   audit.ActionUpdate,
+			Entity:   "transaction_category",
+			EntityID: transcatImplement.TransactionCategoryID,
+			Before:   before,
+			After:    transcatImplement,
+		})
+	})
+	if err == errVersionConflict {
+		current := model.TransCat{}
+		a.db.Select("version").First(&current, "transaction_category_id = ?", transcatImplement.TransactionCategoryID)
+		abortVersionConflict(c, current.Version)
+		return
+	}
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
 
 	// Success response
+	c.Header("ETag", etag(transcatImplement.Version))
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Update success",
+		"data":    transcatImplement,
 	})
 }
 
@@ -129,7 +181,22 @@ func (a *transcatImplement) Delete(c *gin.Context) {
 	id := c.Param("id")
 
 	// Find first data based on id and delete it
-	if err := a.db.Where("transaction_category_id = ?", id).Delete(&model.TransCat{}).Error; err != nil {
+	transcatImplement := model.TransCat{}
+	err := a.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&transcatImplement, "transaction_category_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("transaction_category_id = ?", id).Delete(&model.TransCat{}).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, audit.ActorFromContext(c), audit.Entry{
+			Action:   audit.ActionDelete,
+			Entity:   "transaction_category",
+			EntityID: transcatImplement.TransactionCategoryID,
+			Before:   transcatImplement,
+		})
+	})
+	if err != nil {
 		// No data found and deleted
 		if err == gorm.ErrRecordNotFound {
 			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
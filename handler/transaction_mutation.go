@@ -1,16 +1,26 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+	"task-golang-db/accountno"
+	"task-golang-db/audit"
+	"task-golang-db/ledger"
 	"task-golang-db/model"
+	"task-golang-db/realtime"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type NewTransactionInterface interface {
 	NewTransaction(*gin.Context)
 	TransactionList(*gin.Context)
+	Read(*gin.Context)
+	Refund(*gin.Context)
+	Reverse(*gin.Context)
 }
 
 type newTransactionImplement struct {
@@ -26,11 +36,11 @@ func NewTrans(db *gorm.DB) NewTransactionInterface {
 // NewTransaction creates a new transaction and updates the account balance
 func (a *newTransactionImplement) NewTransaction(c *gin.Context) {
 	var data struct {
-		AccountID             int8  `json:"account_id"`
-		TransactionCategoryID *int8 `json:"transaction_category_id"`
-		FromAccountId         *int8 `json:"from_account_id"`
-		ToAccountId           *int8 `json:"to_account_id"`
-		Amount                int8  `json:"amount"`
+		Account               accountno.Ref  `json:"account_id"`
+		TransactionCategoryID *int64         `json:"transaction_category_id"`
+		FromAccount           *accountno.Ref `json:"from_account_id"`
+		ToAccount             *accountno.Ref `json:"to_account_id"`
+		Amount                int64          `json:"amount"`
 	}
 
 	// Bind JSON to data struct
@@ -39,53 +49,235 @@ func (a *newTransactionImplement) NewTransaction(c *gin.Context) {
 		return
 	}
 
+	accountID, ok := resolveAccount(c, a.db, data.Account)
+	if !ok {
+		return
+	}
+
 	// Create new transaction record
 	transaction := model.Transaction{
-		AccountID: data.AccountID,
-		Amount:    data.Amount,
-	}
-	if data.TransactionCategoryID != nil {
-		transaction.TransactionCategoryID = *data.TransactionCategoryID
+		AccountID:             accountID,
+		TransactionCategoryID: data.TransactionCategoryID,
+		Amount:                data.Amount,
 	}
-	if data.FromAccountId != nil {
-		transaction.FromAccountId = *data.FromAccountId
+	if data.FromAccount != nil {
+		if transaction.FromAccountId, ok = resolveAccount(c, a.db, *data.FromAccount); !ok {
+			return
+		}
 	}
-	if data.ToAccountId != nil {
-		transaction.ToAccountId = *data.ToAccountId
+	if data.ToAccount != nil {
+		if transaction.ToAccountId, ok = resolveAccount(c, a.db, *data.ToAccount); !ok {
+			return
+		}
 	}
-	// Save transaction to the database
-	if err := a.db.Create(&transaction).Error; err != nil {
+	// Save transaction and update balance in one DB transaction
+	err := a.db.Transaction(func(tx *gorm.DB) error {
+		// Retrieve the account and update balance
+		var account model.Account
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&account, accountID).Error; err != nil {
+			return err
+		}
+		if err := ledger.CheckMoneyAllowed(account); err != nil {
+			return err
+		}
+
+		transaction.Currency = account.Currency
+		if err := tx.Create(&transaction).Error; err != nil {
+			return err
+		}
+
+		if err := audit.Record(tx, audit.ActorFromContext(c), audit.Entry{
+			Action:   audit.ActionCreate,
+			Entity:   "transaction",
+			EntityID: transaction.TransactionID,
+			After:    transaction,
+		}); err != nil {
+			return err
+		}
+
+		account.Balance += float64(data.Amount)
+		if err := tx.Save(&account).Error; err != nil {
+			return err
+		}
+
+		if err := ledger.PublishBalance(tx, account); err != nil {
+			return err
+		}
+		return realtime.Publish(tx, account.AccountID, model.AccountEventMutationCreated, transaction)
+	})
+	if err != nil {
+		var blocked ledger.AccountBlockedError
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+			return
+		}
+		if errors.As(err, &blocked) {
+			c.JSON(http.StatusForbidden, gin.H{"error": blocked.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Retrieve the account and update balance
-	var account model.Account
-	if err := a.db.First(&account, data.AccountID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+	// Return the created transaction
+	if err := accountno.Fill(a.db, &transaction); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-
-	account.Balance += float64(data.Amount)
-	a.db.Save(&account)
-
-	// Return the created transaction
 	c.JSON(http.StatusOK, transaction)
 }
 
 // TransactionList retrieves transactions by account_id, ordered by transaction date
 func (a *newTransactionImplement) TransactionList(c *gin.Context) {
-	accountID := c.Query("account_id")
-	if accountID == "" {
+	if c.Query("account_id") == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "account_id is required"})
 		return
 	}
+	accountID, ok := resolveAccount(c, a.db, accountno.Ref(c.Query("account_id")))
+	if !ok {
+		return
+	}
 
 	var transaction []model.Transaction
 	if err := a.db.Where("account_id = ?", accountID).Order("transaction_date desc").Find(&transaction).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	if err := accountno.FillAll(a.db, transaction); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 
 	c.JSON(http.StatusOK, transaction)
 }
+
+// Read menampilkan detail transaksi beserta status reversal-nya (pihak terkait atau admin)
+func (a *newTransactionImplement) Read(c *gin.Context) {
+	transactionID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Transaction not found"})
+		return
+	}
+	var transaction model.Transaction
+	if err := a.db.First(&transaction, transactionID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Transaction not found"})
+		return
+	}
+
+	accountID := c.GetInt64("account_id")
+	involved := transaction.AccountID == accountID || transaction.FromAccountId == accountID || transaction.ToAccountId == accountID
+	if !involved && c.GetString("role") != model.RoleAdmin {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Transaction not found"})
+		return
+	}
+
+	var reversals []model.TransactionReversal
+	if err := a.db.Where("transaction_id = ?", transaction.TransactionID).Order("transaction_reversal_id").Find(&reversals).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := accountno.Fill(a.db, &transaction); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": transaction,
+		"reversal": gin.H{
+			"state":           transaction.ReversalState(),
+			"reversed_amount": transaction.ReversedAmount,
+			"remaining":       transaction.Amount - transaction.ReversedAmount,
+			"history":         reversals,
+		},
+	})
+}
+
+type reversePayload struct {
+	// Amount kosong berarti seluruh sisa yang belum dikembalikan
+	Amount int64  `json:"amount" binding:"gte=0"`
+	Reason string `json:"reason"`
+}
+
+// Refund mengembalikan sebagian atau seluruh transfer, hanya oleh penerima transfer (requires auth)
+func (a *newTransactionImplement) Refund(c *gin.Context) {
+	a.reverse(c, model.ReversalRefund, func(transaction model.Transaction, payload reversePayload) error {
+		if transaction.ToAccountId != c.GetInt64("account_id") {
+			return errForbidden
+		}
+		return nil
+	})
+}
+
+// Reverse membatalkan transfer oleh admin dengan alasan wajib (admin only)
+func (a *newTransactionImplement) Reverse(c *gin.Context) {
+	a.reverse(c, model.ReversalReverse, func(transaction model.Transaction, payload reversePayload) error {
+		if payload.Reason == "" {
+			return errReasonRequired
+		}
+		return nil
+	})
+}
+
+var errReasonRequired = errors.New("reason is required")
+
+func (a *newTransactionImplement) reverse(c *gin.Context, kind string, authorize func(model.Transaction, reversePayload) error) {
+	transactionID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid transaction id"})
+		return
+	}
+
+	payload := reversePayload{}
+	if c.Request.ContentLength > 0 {
+		if err := c.BindJSON(&payload); err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	var result ledger.ReverseResult
+	err = a.db.Transaction(func(tx *gorm.DB) error {
+		transaction, err := ledger.LockTransaction(tx, transactionID)
+		if err != nil {
+			return err
+		}
+		if err := authorize(transaction, payload); err != nil {
+			return err
+		}
+
+		result, err = ledger.Reverse(tx, ledger.ReverseRequest{
+			TransactionID: transactionID,
+			Amount:        payload.Amount,
+			Kind:          kind,
+			Reason:        payload.Reason,
+			Actor:         audit.ActorFromContext(c),
+		})
+		return err
+	})
+	if err != nil {
+		switch err {
+		case ledger.ErrTransactionNotFound:
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Transaction not found"})
+		case errForbidden:
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Only the receiver can refund this transaction"})
+		case errReasonRequired:
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case ledger.ErrNotReversible, ledger.ErrReversalCrossCurrency, ledger.ErrReversalExceedsRemaining:
+			c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		default:
+			abortTransferError(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Reversal success",
+		"data":     result.Reversal,
+		"original": result.Original,
+		"reversal": gin.H{
+			"state":           result.Original.ReversalState(),
+			"reversed_amount": result.Original.ReversedAmount,
+			"remaining":       result.Original.Amount - result.Original.ReversedAmount,
+		},
+	})
+}
@@ -0,0 +1,260 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"task-golang-db/accountno"
+	"task-golang-db/audit"
+	"task-golang-db/beneficiary"
+	"task-golang-db/currency"
+	"task-golang-db/fee"
+	"task-golang-db/ledger"
+	"task-golang-db/limit"
+	"task-golang-db/model"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// transferInquiryTTL adalah masa berlaku inquiry sebelum harus dikonfirmasi
+const transferInquiryTTL = 2 * time.Minute
+
+// TransferInquiry memvalidasi tujuan transfer (to_account_id, to_username, atau beneficiary_id) dan
+// mengembalikan nama penerima yang disamarkan, biaya, saldo setelah transfer, dan
+// inquiry_token untuk /account/transfer/confirm (requires auth)
+func (a *accountImplement) TransferInquiry(c *gin.Context) {
+	var request struct {
+		ToAccount     accountno.Ref `json:"to_account_id"`
+		ToUsername    string        `json:"to_username"`
+		BeneficiaryID int64         `json:"beneficiary_id"`
+		Amount        int64         `json:"amount" binding:"required,gt=0"`
+	}
+	if err := c.BindJSON(&request); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if memberLimitExceeded(c, request.Amount) {
+		return
+	}
+	destinations := 0
+	for _, given := range []bool{request.ToAccount != "", request.ToUsername != "", request.BeneficiaryID != 0} {
+		if given {
+			destinations++
+		}
+	}
+	if destinations != 1 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Provide exactly one of to_account_id, to_username or beneficiary_id"})
+		return
+	}
+
+	var toAccountID int64
+	if request.ToAccount != "" {
+		var ok bool
+		if toAccountID, ok = resolveAccount(c, a.db, request.ToAccount); !ok {
+			return
+		}
+	}
+	if request.ToUsername != "" {
+		var auth model.Auth
+		if err := a.db.Where("username = ?", strings.TrimSpace(request.ToUsername)).First(&auth).Error; err != nil {
+			abortTransferError(c, ledger.ErrReceiverNotFound)
+			return
+		}
+		toAccountID = auth.AccountID
+	}
+	if request.BeneficiaryID != 0 {
+		var err error
+		toAccountID, err = beneficiary.Resolve(a.db, c.GetInt64("account_id"), request.BeneficiaryID)
+		if err != nil {
+			abortBeneficiaryError(c, err)
+			return
+		}
+	}
+
+	startInquiry(c, a.db, toAccountID, request.Amount)
+}
+
+// startInquiry memvalidasi transfer dari akun yang login ke toAccountID, menyimpan
+// inquiry, dan mengirim response inquiry. Dipakai TransferInquiry dan scan QR.
+func startInquiry(c *gin.Context, db *gorm.DB, toAccountID, amount int64) {
+	var sender, receiver model.Account
+	if err := db.First(&sender, c.GetInt64("account_id")).Error; err != nil {
+		abortTransferError(c, ledger.ErrSenderNotFound)
+		return
+	}
+	if err := db.First(&receiver, toAccountID).Error; err != nil {
+		abortTransferError(c, ledger.ErrReceiverNotFound)
+		return
+	}
+	if sender.AccountID == receiver.AccountID {
+		abortTransferError(c, ledger.ErrSameAccount)
+		return
+	}
+	if err := ledger.CheckMoneyAllowed(sender, receiver); err != nil {
+		abortTransferError(c, err)
+		return
+	}
+
+	quote, err := fee.Compute(db, sender, model.OperationTransfer, amount)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if sender.Available() < float64(quote.Total) {
+		abortTransferError(c, ledger.ErrInsufficientBalance)
+		return
+	}
+
+	// Limit dicek lebih awal supaya nasabah tahu sebelum konfirmasi
+	limits, err := limit.Effective(db, sender, model.OperationTransfer)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	usage, err := limit.CurrentUsage(db, sender.AccountID, model.OperationTransfer, time.Now())
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := limit.Check(limits, usage, model.OperationTransfer, amount); err != nil {
+		abortTransferError(c, err)
+		return
+	}
+
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	inquiry := model.TransferInquiry{
+		TransferInquiryID: hex.EncodeToString(token),
+		FromAccountID:     sender.AccountID,
+		ToAccountID:       receiver.AccountID,
+		Amount:            amount,
+		Fee:               quote.Fee,
+		Currency:          sender.Currency,
+		Credit:            amount,
+		CreditCurrency:    receiver.Currency,
+		ExpiresAt:         time.Now().Add(transferInquiryTTL),
+	}
+
+	// Transfer lintas mata uang langsung mengunci kurs; inquiry ikut kedaluwarsa bersama quote
+	if sender.Currency != receiver.Currency {
+		fxQuote, err := newFxQuote(db, sender, receiver, amount)
+		if err != nil {
+			if err == currency.ErrRateNotFound {
+				c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": "No exchange rate for " + sender.Currency + "/" + receiver.Currency})
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		inquiry.FxQuoteID = &fxQuote.FxQuoteID
+		inquiry.Credit = fxQuote.TargetAmount
+		if fxQuote.ExpiresAt.Before(inquiry.ExpiresAt) {
+			inquiry.ExpiresAt = fxQuote.ExpiresAt
+		}
+	}
+
+	if err := db.Create(&inquiry).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"inquiry_token":     inquiry.TransferInquiryID,
+			"to_account_number": receiver.AccountNumber,
+			"recipient_name":    maskName(receiver.Name),
+			"amount":            inquiry.Amount,
+			"fee":               inquiry.Fee,
+			"total":             quote.Total,
+			"currency":          inquiry.Currency,
+			"credit":            inquiry.Credit,
+			"credit_currency":   inquiry.CreditCurrency,
+			"balance_after":     int64(sender.Balance) - quote.Total,
+			"available_after":   int64(sender.Available()) - quote.Total,
+			"expires_at":        inquiry.ExpiresAt,
+		},
+	})
+}
+
+// TransferConfirm mengeksekusi tepat isi inquiry: penerima, nominal, kurs, dan biaya
+// tidak bisa diubah di langkah ini (requires auth)
+func (a *accountImplement) TransferConfirm(c *gin.Context) {
+	var request struct {
+		InquiryToken    string `json:"inquiry_token" binding:"required"`
+		SaveBeneficiary bool   `json:"save_beneficiary"`
+	}
+	if err := c.BindJSON(&request); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Inquiry bisa dibuat member lain di akun yang sama, jadi batas nominal dicek ulang
+	var pending model.TransferInquiry
+	if err := a.db.Select("amount").First(&pending, "transfer_inquiry_id = ?", request.InquiryToken).Error; err == nil && memberLimitExceeded(c, pending.Amount) {
+		return
+	}
+
+	var result ledger.TransferResult
+	err := a.db.Transaction(func(tx *gorm.DB) error {
+		inquiry, err := ledger.UseInquiry(tx, request.InquiryToken, c.GetInt64("account_id"))
+		if err != nil {
+			return err
+		}
+
+		transferRequest := ledger.TransferRequest{
+			FromAccountID: inquiry.FromAccountID,
+			ToAccountID:   inquiry.ToAccountID,
+			Amount:        inquiry.Amount,
+			ExpectedFee:   &inquiry.Fee,
+			Actor:         audit.ActorFromContext(c),
+		}
+		if inquiry.FxQuoteID != nil {
+			transferRequest.QuoteID = *inquiry.FxQuoteID
+		}
+		result, err = ledger.Transfer(tx, transferRequest)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Model(&inquiry).Update("transaction_id", result.Transaction.TransactionID).Error; err != nil {
+			return err
+		}
+		return beneficiary.Touch(tx, inquiry.FromAccountID, result.Receiver, request.SaveBeneficiary)
+	})
+	if err != nil {
+		abortTransferError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Transfer successful",
+		"data": gin.H{
+			"transaction_id": result.Transaction.TransactionID,
+			"amount":         result.Transaction.Amount,
+			"fee":            result.Fee,
+			"credit":         result.Credit,
+			"balance":        result.Sender.Balance,
+		},
+	})
+}
+
+// maskName menyamarkan nama penerima: dua huruf pertama tiap kata tetap terlihat,
+// contoh "Rizky Dharma" -> "Ri*** Dh****"
+func maskName(name string) string {
+	words := strings.Fields(name)
+	for i, word := range words {
+		runes := []rune(word)
+		visible := 2
+		if len(runes) <= 2 {
+			visible = 1
+		}
+		words[i] = string(runes[:visible]) + strings.Repeat("*", len(runes)-visible)
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,211 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"task-golang-db/audit"
+	"task-golang-db/model"
+	"task-golang-db/webhook"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type WebhookInterface interface {
+	Create(*gin.Context)
+	List(*gin.Context)
+	Delete(*gin.Context)
+	Deliveries(*gin.Context)
+	DeadLetters(*gin.Context)
+	Redeliver(*gin.Context)
+}
+
+type webhookImplement struct {
+	db *gorm.DB
+}
+
+func NewWebhook(db *gorm.DB) WebhookInterface {
+	return &webhookImplement{
+		db: db,
+	}
+}
+
+type webhookCreatePayload struct {
+	Partner    string   `json:"partner" binding:"required"`
+	URL        string   `json:"url" binding:"required,url"`
+	EventTypes []string `json:"event_types" binding:"required,min=1"`
+}
+
+// Create mendaftarkan subscription baru. Secret hanya ditampilkan sekali di response ini.
+func (a *webhookImplement) Create(c *gin.Context) {
+	payload := webhookCreatePayload{}
+	if err := c.BindJSON(&payload); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	for _, t := range payload.EventTypes {
+		if !validEventType(t) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error":       "Unknown event type: " + t,
+				"event_types": webhook.EventTypes,
+			})
+			return
+		}
+	}
+
+	secret, err := generateSecret()
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	subscription := model.WebhookSubscription{
+		Partner:    payload.Partner,
+		URL:        payload.URL,
+		Secret:     secret,
+		EventTypes: payload.EventTypes,
+		Active:     true,
+	}
+	err = a.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&subscription).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, audit.ActorFromContext(c), audit.Entry{
+			Action:   audit.ActionCreate,
+			Entity:   "webhook_subscription",
+			EntityID: subscription.WebhookSubscriptionID,
+			After:    subscription,
+		})
+	})
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Success response
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Create success",
+		"data":    subscription,
+		"secret":  secret,
+	})
+}
+
+func (a *webhookImplement) List(c *gin.Context) {
+	var subscriptions []model.WebhookSubscription
+
+	query := a.db.Order("webhook_subscription_id")
+	if partner := c.Query("partner"); partner != "" {
+		query = query.Where("partner = ?", partner)
+	}
+	if err := query.Find(&subscriptions).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": subscriptions})
+}
+
+// Delete menonaktifkan subscription; riwayat delivery tetap disimpan
+func (a *webhookImplement) Delete(c *gin.Context) {
+	id := c.Param("id")
+
+	var subscription model.WebhookSubscription
+	if err := a.db.First(&subscription, "webhook_subscription_id = ?", id).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Not found"})
+		return
+	}
+
+	before := subscription
+	subscription.Active = false
+	err := a.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&subscription).Update("active", false).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, audit.ActorFromContext(c), audit.Entry{
+			Action:   audit.ActionDelete,
+			Entity:   "webhook_subscription",
+			EntityID: subscription.WebhookSubscriptionID,
+			Before:   before,
+			After:    subscription,
+		})
+	})
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Delete success"})
+}
+
+// Deliveries menampilkan riwayat delivery sebuah subscription
+func (a *webhookImplement) Deliveries(c *gin.Context) {
+	id := c.Param("id")
+
+	var deliveries []model.WebhookDelivery
+	query := a.db.Where("webhook_subscription_id = ?", id).Order("webhook_delivery_id DESC")
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Find(&deliveries).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": deliveries})
+}
+
+// DeadLetters menampilkan delivery yang gagal setelah retry maksimum
+func (a *webhookImplement) DeadLetters(c *gin.Context) {
+	var deliveries []model.WebhookDelivery
+	if err := a.db.Where("status = ?", model.WebhookDeliveryDeadLetter).
+		Order("webhook_delivery_id DESC").
+		Find(&deliveries).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": deliveries})
+}
+
+// Redeliver mengantrikan ulang delivery secara manual
+func (a *webhookImplement) Redeliver(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid delivery id"})
+		return
+	}
+
+	if err := webhook.Redeliver(a.db, id); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Not found"})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Redelivery scheduled"})
+}
+
+func validEventType(eventType string) bool {
+	if eventType == "*" {
+		return true
+	}
+	for _, t := range webhook.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func generateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,366 @@
+package interest
+
+import (
+	"errors"
+	"math"
+	"os"
+	"strconv"
+	"task-golang-db/audit"
+	"task-golang-db/ledger"
+	"task-golang-db/model"
+	"task-golang-db/notification"
+	"task-golang-db/realtime"
+	"task-golang-db/statement"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrNoProduct          = errors.New("account has no interest product")
+	ErrTaxAccountNotSet   = errors.New("INTEREST_TAX_ACCOUNT_ID is not set")
+	ErrTaxAccountCurrency = errors.New("interest tax account uses a different currency")
+)
+
+// Kategori transaksi untuk bunga dan potongan pajaknya
+const (
+	CategoryInterest    = "Interest"
+	CategoryInterestTax = "Interest Tax"
+)
+
+// ActionPost adalah aksi audit untuk pengkreditan bunga
+const ActionPost = "interest_post"
+
+const dateLayout = "2006-01-02"
+
+// Config adalah konfigurasi engine bunga
+type Config struct {
+	// TaxAccountID adalah akun sistem penampung pajak bunga
+	TaxAccountID int64
+}
+
+// ConfigFromEnv membaca INTEREST_TAX_ACCOUNT_ID
+func ConfigFromEnv() Config {
+	id, _ := strconv.ParseInt(os.Getenv("INTEREST_TAX_ACCOUNT_ID"), 10, 64)
+	return Config{TaxAccountID: id}
+}
+
+// Accrue mencatat bunga harian akun mulai hari setelah accrual terakhir sampai through
+// (hari pertama adalah through jika belum pernah ada accrual), lalu mengkreditkan
+// bunga untuk setiap akhir periode yang terlewati. Accrual unik per akun dan tanggal,
+// posting unik per akun dan akhir periode, sehingga aman dijalankan ulang.
+func Accrue(db *gorm.DB, cfg Config, accountID int64, through time.Time) (int, error) {
+	through = dateOf(through)
+	accrued := 0
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// Akun pajak ikut dikunci di awal supaya urutan lock selalu account_id naik;
+		// withhold nanti mengambil ulang baris yang sudah dikunci tx ini
+		account, _, err := ledger.LockAccountPair(tx, accountID, cfg.TaxAccountID)
+		if err != nil {
+			return err
+		}
+		if account.AccountID == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if account.InterestProductID == nil || account.Status == model.AccountStatusClosed {
+			return nil
+		}
+
+		var product model.InterestProduct
+		if err := tx.First(&product, *account.InterestProductID).Error; err != nil {
+			return err
+		}
+		if !product.Active {
+			return nil
+		}
+
+		start := through
+		var last model.InterestAccrual
+		result := tx.Where("account_id = ?", accountID).Order("accrual_date DESC").Limit(1).Find(&last)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			start = dateOf(last.AccrualDate).AddDate(0, 0, 1)
+		}
+		if start.After(through) {
+			return nil
+		}
+
+		balances, err := statement.DailyBalances(tx, accountID, start, through)
+		if err != nil {
+			return err
+		}
+
+		for day := start; !day.After(through); day = day.AddDate(0, 0, 1) {
+			balance := balances[day.Format(dateLayout)]
+			accrual := model.InterestAccrual{
+				AccountID:         accountID,
+				AccrualDate:       day,
+				InterestProductID: product.InterestProductID,
+				Balance:           balance,
+				AnnualRate:        product.AnnualRate,
+				Bands:             product.Bands,
+				DayCount:          product.DayCount,
+				Amount:            round(Daily(balance, product.AnnualRate, product.Bands, product.DayCount, day)),
+			}
+			result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&accrual)
+			if result.Error != nil {
+				return result.Error
+			}
+			accrued += int(result.RowsAffected)
+
+			if IsPeriodEnd(product.Compounding, day) {
+				if _, err := post(tx, cfg, &account, product, day); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	return accrued, err
+}
+
+// post mengkreditkan semua accrual yang belum diposting sampai periodEnd. Pecahan di
+// bawah satuan terkecil disimpan sebagai Remainder dan ikut dihitung di periode berikutnya.
+func post(tx *gorm.DB, cfg Config, account *model.Account, product model.InterestProduct, periodEnd time.Time) (model.InterestPosting, error) {
+	var posting model.InterestPosting
+
+	result := tx.Where("account_id = ? AND period_end = ?", account.AccountID, periodEnd.Format(dateLayout)).
+		Limit(1).Find(&posting)
+	if result.Error != nil || result.RowsAffected > 0 {
+		return posting, result.Error
+	}
+
+	var accruals []model.InterestAccrual
+	err := tx.Where("account_id = ? AND interest_posting_id IS NULL AND accrual_date <= ?",
+		account.AccountID, periodEnd.Format(dateLayout)).
+		Order("accrual_date").
+		Find(&accruals).Error
+	if err != nil || len(accruals) == 0 {
+		return posting, err
+	}
+
+	var previous model.InterestPosting
+	if err := tx.Where("account_id = ?", account.AccountID).Order("period_end DESC").Limit(1).Find(&previous).Error; err != nil {
+		return posting, err
+	}
+
+	total := previous.Remainder
+	for _, accrual := range accruals {
+		total += accrual.Amount
+	}
+	gross := int64(math.Floor(round(total)))
+	tax := gross * product.TaxBps / 10000
+
+	posting = model.InterestPosting{
+		AccountID:   account.AccountID,
+		PeriodStart: dateOf(accruals[0].AccrualDate),
+		PeriodEnd:   periodEnd,
+		Accrued:     round(total),
+		Gross:       gross,
+		Tax:         tax,
+		Net:         gross - tax,
+		Remainder:   round(total - float64(gross)),
+	}
+
+	if gross > 0 {
+		transaction, err := credit(tx, account, gross)
+		if err != nil {
+			return posting, err
+		}
+		posting.TransactionID = &transaction.TransactionID
+	}
+	if tax > 0 {
+		transaction, err := withhold(tx, cfg, account, tax)
+		if err != nil {
+			return posting, err
+		}
+		posting.TaxTransactionID = &transaction.TransactionID
+	}
+
+	if err := tx.Create(&posting).Error; err != nil {
+		return posting, err
+	}
+	err = tx.Model(&model.InterestAccrual{}).
+		Where("account_id = ? AND interest_posting_id IS NULL AND accrual_date <= ?",
+			account.AccountID, periodEnd.Format(dateLayout)).
+		Update("interest_posting_id", posting.InterestPostingID).Error
+	if err != nil {
+		return posting, err
+	}
+
+	if err := audit.Record(tx, audit.Actor{Username: "scheduler"}, audit.Entry{
+		Action:   ActionPost,
+		Entity:   "interest_posting",
+		EntityID: posting.InterestPostingID,
+		After:    posting,
+	}); err != nil {
+		return posting, err
+	}
+
+	if gross == 0 {
+		return posting, nil
+	}
+	return posting, notification.Notify(tx, account.AccountID, notification.EventInterestPosted, map[string]interface{}{
+		"period_start": posting.PeriodStart.Format(dateLayout),
+		"period_end":   posting.PeriodEnd.Format(dateLayout),
+		"gross":        gross,
+		"tax":          tax,
+		"net":          gross - tax,
+	})
+}
+
+// credit menambah saldo akun sebesar bunga bruto sebagai transaksi berkategori Interest
+func credit(tx *gorm.DB, account *model.Account, amount int64) (model.Transaction, error) {
+	var transaction model.Transaction
+
+	categoryID, err := ledger.CategoryID(tx, CategoryInterest)
+	if err != nil {
+		return transaction, err
+	}
+
+	account.Balance += float64(amount)
+	if err := tx.Model(account).Update("balance", account.Balance).Error; err != nil {
+		return transaction, err
+	}
+
+	transaction = model.Transaction{
+		TransactionCategoryID: &categoryID,
+		AccountID:             account.AccountID,
+		ToAccountId:           account.AccountID,
+		ToAccountNumber:       account.AccountNumber,
+		Amount:                amount,
+		Currency:              account.Currency,
+		TransactionDate:       time.Now().Format("2006-01-02 15:04:05"),
+	}
+	if err := tx.Create(&transaction).Error; err != nil {
+		return transaction, err
+	}
+
+	if err := ledger.PublishBalance(tx, *account); err != nil {
+		return transaction, err
+	}
+	return transaction, realtime.Publish(tx, account.AccountID, model.AccountEventMutationCreated, transaction)
+}
+
+// withhold memindahkan pajak bunga dari akun ke akun sistem pajak. Dicatat sebagai
+// transfer sehingga muncul sebagai debit di rekening koran nasabah.
+func withhold(tx *gorm.DB, cfg Config, account *model.Account, amount int64) (model.Transaction, error) {
+	var transaction model.Transaction
+
+	if cfg.TaxAccountID == 0 {
+		return transaction, ErrTaxAccountNotSet
+	}
+	taxAccount, err := ledger.LockAccount(tx, cfg.TaxAccountID)
+	if err != nil {
+		return transaction, err
+	}
+	if taxAccount.Currency != account.Currency {
+		return transaction, ErrTaxAccountCurrency
+	}
+
+	categoryID, err := ledger.CategoryID(tx, CategoryInterestTax)
+	if err != nil {
+		return transaction, err
+	}
+
+	account.Balance -= float64(amount)
+	taxAccount.Balance += float64(amount)
+	if err := tx.Model(account).Update("balance", account.Balance).Error; err != nil {
+		return transaction, err
+	}
+	if err := tx.Model(&taxAccount).Update("balance", taxAccount.Balance).Error; err != nil {
+		return transaction, err
+	}
+
+	transaction = model.Transaction{
+		TransactionCategoryID: &categoryID,
+		AccountID:             account.AccountID,
+		FromAccountId:         account.AccountID,
+		FromAccountNumber:     account.AccountNumber,
+		ToAccountId:           taxAccount.AccountID,
+		ToAccountNumber:       taxAccount.AccountNumber,
+		Amount:                amount,
+		Currency:              account.Currency,
+		TransactionDate:       time.Now().Format("2006-01-02 15:04:05"),
+	}
+	if err := tx.Create(&transaction).Error; err != nil {
+		return transaction, err
+	}
+
+	for _, a := range []model.Account{*account, taxAccount} {
+		if err := ledger.PublishBalance(tx, a); err != nil {
+			return transaction, err
+		}
+		if err := realtime.Publish(tx, a.AccountID, model.AccountEventMutationCreated, transaction); err != nil {
+			return transaction, err
+		}
+	}
+	return transaction, nil
+}
+
+// Recomputation adalah hasil hitung ulang bunga satu hari untuk audit
+type Recomputation struct {
+	AccountID int64                  `json:"account_id"`
+	Date      string                 `json:"date"`
+	Balance   int64                  `json:"balance"`
+	Amount    float64                `json:"amount"`
+	Stored    *model.InterestAccrual `json:"stored"`
+	Match     bool                   `json:"match"`
+}
+
+// Recompute menghitung ulang bunga hari day dari riwayat transaksi. Jika accrual sudah
+// tercatat, aturan yang dipakai adalah snapshot pada accrual tersebut; jika belum,
+// dipakai produk akun saat ini. Match bernilai true jika hasilnya sama dengan yang tercatat.
+func Recompute(db *gorm.DB, accountID int64, day time.Time) (Recomputation, error) {
+	day = dateOf(day)
+	result := Recomputation{AccountID: accountID, Date: day.Format(dateLayout)}
+
+	var stored model.InterestAccrual
+	found := db.Where("account_id = ? AND accrual_date = ?", accountID, day.Format(dateLayout)).Limit(1).Find(&stored)
+	if found.Error != nil {
+		return result, found.Error
+	}
+
+	annualRate, bands, dayCount := stored.AnnualRate, stored.Bands, stored.DayCount
+	if found.RowsAffected > 0 {
+		result.Stored = &stored
+	} else {
+		var account model.Account
+		if err := db.Unscoped().First(&account, accountID).Error; err != nil {
+			return result, err
+		}
+		if account.InterestProductID == nil {
+			return result, ErrNoProduct
+		}
+		var product model.InterestProduct
+		if err := db.First(&product, *account.InterestProductID).Error; err != nil {
+			return result, err
+		}
+		annualRate, bands, dayCount = product.AnnualRate, product.Bands, product.DayCount
+	}
+
+	balances, err := statement.DailyBalances(db, accountID, day, day)
+	if err != nil {
+		return result, err
+	}
+	result.Balance = balances[day.Format(dateLayout)]
+	result.Amount = round(Daily(result.Balance, annualRate, bands, dayCount, day))
+	result.Match = result.Stored != nil &&
+		result.Stored.Balance == result.Balance &&
+		math.Abs(result.Stored.Amount-result.Amount) < 1e-6
+	return result, nil
+}
+
+// dateOf membuang jam dari t; kolom date dibaca sebagai tengah malam UTC
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
+
+// round membulatkan ke 6 desimal sesuai kolom numeric(20,6)
+func round(value float64) float64 {
+	return math.Round(value*1e6) / 1e6
+}
@@ -0,0 +1,148 @@
+package interest
+
+import (
+	"errors"
+	"sort"
+	"task-golang-db/model"
+	"time"
+)
+
+var (
+	ErrInvalidDayCount    = errors.New("day_count must be one of ACT/365, ACT/360, ACT/ACT, 30/360")
+	ErrInvalidCompounding = errors.New("compounding must be one of daily, monthly, quarterly, yearly")
+	ErrInvalidRate        = errors.New("annual_rate must be between 0 and 100")
+	ErrInvalidBands       = errors.New("bands must start at min_balance 0 with unique min_balance")
+	ErrInvalidTax         = errors.New("tax_bps must be between 0 and 10000")
+)
+
+// Validate mengecek aturan produk bunga sebelum disimpan
+func Validate(product model.InterestProduct) error {
+	switch product.DayCount {
+	case model.DayCountAct365, model.DayCountAct360, model.DayCountActAct, model.DayCount30360:
+	default:
+		return ErrInvalidDayCount
+	}
+	switch product.Compounding {
+	case model.CompoundingDaily, model.CompoundingMonthly, model.CompoundingQuarterly, model.CompoundingYearly:
+	default:
+		return ErrInvalidCompounding
+	}
+	if product.AnnualRate < 0 || product.AnnualRate > 100 {
+		return ErrInvalidRate
+	}
+	if product.TaxBps < 0 || product.TaxBps > 10000 {
+		return ErrInvalidTax
+	}
+
+	if len(product.Bands) > 0 {
+		bands := sortedBands(product.Bands)
+		if bands[0].MinBalance != 0 {
+			return ErrInvalidBands
+		}
+		for i, band := range bands {
+			if band.AnnualRate < 0 || band.AnnualRate > 100 {
+				return ErrInvalidRate
+			}
+			if i > 0 && band.MinBalance == bands[i-1].MinBalance {
+				return ErrInvalidBands
+			}
+		}
+	}
+	return nil
+}
+
+// Yearly menghitung bunga setahun untuk balance. Dengan band, tiap lapis saldo
+// mendapat rate band-nya sendiri (contoh band 0: 1%, 10jt: 3% -> saldo 15jt
+// mendapat 1% atas 10jt pertama dan 3% atas 5jt sisanya).
+func Yearly(balance int64, annualRate float64, bands []model.InterestBand) float64 {
+	if balance <= 0 {
+		return 0
+	}
+	if len(bands) == 0 {
+		return float64(balance) * annualRate / 100
+	}
+
+	bands = sortedBands(bands)
+	var total float64
+	for i, band := range bands {
+		if balance <= band.MinBalance {
+			break
+		}
+		upper := balance
+		if i+1 < len(bands) && bands[i+1].MinBalance < balance {
+			upper = bands[i+1].MinBalance
+		}
+		total += float64(upper-band.MinBalance) * band.AnnualRate / 100
+	}
+	return total
+}
+
+// DayFraction adalah porsi tahun yang diwakili satu hari menurut konvensi dayCount.
+// Pada 30/360 setiap bulan dianggap 30 hari: tanggal 31 tidak berbunga dan hari
+// terakhir Februari menutup sisa hari sampai tanggal 30.
+func DayFraction(dayCount string, day time.Time) float64 {
+	switch dayCount {
+	case model.DayCountAct360:
+		return 1.0 / 360
+	case model.DayCountActAct:
+		return 1.0 / float64(daysInYear(day.Year()))
+	case model.DayCount30360:
+		if day.Day() == 31 {
+			return 0
+		}
+		if day.Month() == time.February && isLastDayOfMonth(day) {
+			return float64(30-day.Day()+1) / 360
+		}
+		return 1.0 / 360
+	default:
+		return 1.0 / 365
+	}
+}
+
+// Daily adalah bunga satu hari untuk saldo akhir hari balance, dalam satuan terkecil (pecahan)
+func Daily(balance int64, annualRate float64, bands []model.InterestBand, dayCount string, day time.Time) float64 {
+	return Yearly(balance, annualRate, bands) * DayFraction(dayCount, day)
+}
+
+// PeriodStart adalah hari pertama periode kapitalisasi yang memuat day
+func PeriodStart(compounding string, day time.Time) time.Time {
+	switch compounding {
+	case model.CompoundingMonthly:
+		return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, day.Location())
+	case model.CompoundingQuarterly:
+		month := day.Month() - (day.Month()-1)%3
+		return time.Date(day.Year(), month, 1, 0, 0, 0, 0, day.Location())
+	case model.CompoundingYearly:
+		return time.Date(day.Year(), time.January, 1, 0, 0, 0, 0, day.Location())
+	default:
+		return time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	}
+}
+
+// IsPeriodEnd bernilai true jika day adalah hari terakhir periode kapitalisasi
+func IsPeriodEnd(compounding string, day time.Time) bool {
+	switch compounding {
+	case model.CompoundingMonthly:
+		return isLastDayOfMonth(day)
+	case model.CompoundingQuarterly:
+		return isLastDayOfMonth(day) && day.Month()%3 == 0
+	case model.CompoundingYearly:
+		return day.Month() == time.December && day.Day() == 31
+	default:
+		return true
+	}
+}
+
+func sortedBands(bands []model.InterestBand) []model.InterestBand {
+	sorted := append([]model.InterestBand(nil), bands...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].MinBalance < sorted[j].MinBalance })
+	return sorted
+}
+
+func isLastDayOfMonth(day time.Time) bool {
+	return day.AddDate(0, 0, 1).Day() == 1
+}
+
+func daysInYear(year int) int {
+	return time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC).YearDay()
+}
@@ -0,0 +1,85 @@
+package ledger
+
+import (
+	"errors"
+	"task-golang-db/fee"
+	"task-golang-db/model"
+	"task-golang-db/realtime"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrFeeAccountNotSet   = errors.New("FEE_REVENUE_ACCOUNT_ID is not set")
+	ErrFeeAccountCurrency = errors.New("fee revenue account uses a different currency")
+	ErrFeeExceedsAmount   = errors.New("fee exceeds the top-up amount")
+)
+
+// CategoryFee adalah kategori transaksi biaya
+const CategoryFee = "Fee"
+
+// ChargeFee memindahkan biaya dari payer ke akun pendapatan sebagai transaksi terpisah
+// yang menunjuk transaksi asal. payer harus sudah di-lock dan saldonya sudah dicek.
+// Mengembalikan nil jika biaya nol atau payer adalah akun pendapatan itu sendiri.
+func ChargeFee(tx *gorm.DB, payer *model.Account, quote fee.Quote, parent model.Transaction) (*model.Transaction, error) {
+	if quote.Fee <= 0 {
+		return nil, nil
+	}
+
+	revenueID := fee.RevenueAccountID()
+	if revenueID == 0 {
+		return nil, ErrFeeAccountNotSet
+	}
+	if revenueID == payer.AccountID {
+		return nil, nil
+	}
+	revenue, err := LockAccount(tx, revenueID)
+	if err != nil {
+		return nil, err
+	}
+	if revenue.Currency != payer.Currency {
+		return nil, ErrFeeAccountCurrency
+	}
+
+	categoryID, err := CategoryID(tx, CategoryFee)
+	if err != nil {
+		return nil, err
+	}
+
+	payer.Balance -= float64(quote.Fee)
+	revenue.Balance += float64(quote.Fee)
+	if err := tx.Model(payer).Update("balance", payer.Balance).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Model(&revenue).Update("balance", revenue.Balance).Error; err != nil {
+		return nil, err
+	}
+
+	transaction := model.Transaction{
+		TransactionCategoryID: &categoryID,
+		AccountID:             payer.AccountID,
+		FromAccountId:         payer.AccountID,
+		FromAccountNumber:     payer.AccountNumber,
+		ToAccountId:           revenue.AccountID,
+		ToAccountNumber:       revenue.AccountNumber,
+		Amount:                quote.Fee,
+		Currency:              payer.Currency,
+		FeeOfID:               &parent.TransactionID,
+		TransactionDate:       time.Now().Format("2006-01-02 15:04:05"),
+	}
+	if err := tx.Create(&transaction).Error; err != nil {
+		return nil, err
+	}
+
+	// Saldo payer dikirim oleh pemanggil setelah semua mutasi selesai
+	if err := PublishBalance(tx, revenue); err != nil {
+		return nil, err
+	}
+	for _, accountID := range []int64{payer.AccountID, revenue.AccountID} {
+		if err := realtime.Publish(tx, accountID, model.AccountEventMutationCreated, transaction); err != nil {
+			return nil, err
+		}
+	}
+	return &transaction, nil
+}
@@ -0,0 +1,197 @@
+package ledger
+
+import (
+	"errors"
+	"task-golang-db/audit"
+	"task-golang-db/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrHoldNotActive      = errors.New("hold is no longer active")
+	ErrCaptureExceedsHold = errors.New("capture amount exceeds held amount")
+	ErrCurrencyMismatch   = errors.New("accounts use different currencies")
+)
+
+// Aksi audit untuk hold
+const (
+	ActionHold    = "hold"
+	ActionCapture = "capture"
+	ActionRelease = "release"
+)
+
+// HoldRequest adalah input untuk menahan dana pembayar atas nama merchant
+type HoldRequest struct {
+	AccountID         int64
+	MerchantAccountID int64
+	Amount            int64
+	Description       string
+	ExpiresAt         time.Time
+	Actor             audit.Actor
+}
+
+// PlaceHold memindahkan amount dari saldo available ke held
+func PlaceHold(tx *gorm.DB, req HoldRequest) (model.Hold, error) {
+	var hold model.Hold
+
+	if req.Amount <= 0 {
+		return hold, ErrInvalidAmount
+	}
+	if req.AccountID == req.MerchantAccountID {
+		return hold, ErrSameAccount
+	}
+
+	account, err := LockAccount(tx, req.AccountID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return hold, ErrSenderNotFound
+	}
+	if err != nil {
+		return hold, err
+	}
+	var merchant model.Account
+	if err := tx.First(&merchant, req.MerchantAccountID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return hold, ErrReceiverNotFound
+		}
+		return hold, err
+	}
+	if err := CheckMoneyAllowed(account, merchant); err != nil {
+		return hold, err
+	}
+	if account.Currency != merchant.Currency {
+		return hold, ErrCurrencyMismatch
+	}
+	if account.Available() < float64(req.Amount) {
+		return hold, ErrInsufficientBalance
+	}
+
+	account.HeldBalance += float64(req.Amount)
+	if err := tx.Model(&account).Update("held_balance", account.HeldBalance).Error; err != nil {
+		return hold, err
+	}
+
+	hold = model.Hold{
+		AccountID:         req.AccountID,
+		MerchantAccountID: req.MerchantAccountID,
+		Amount:            req.Amount,
+		Currency:          account.Currency,
+		Description:       req.Description,
+		Status:            model.HoldActive,
+		ExpiresAt:         req.ExpiresAt,
+	}
+	if err := tx.Create(&hold).Error; err != nil {
+		return hold, err
+	}
+
+	if err := audit.Record(tx, req.Actor, audit.Entry{
+		Action:   ActionHold,
+		Entity:   "hold",
+		EntityID: hold.HoldID,
+		After:    hold,
+	}); err != nil {
+		return hold, err
+	}
+	return hold, PublishBalance(tx, account)
+}
+
+// LockHold mengambil hold dengan SELECT ... FOR UPDATE
+func LockHold(tx *gorm.DB, holdID int64) (model.Hold, error) {
+	var hold model.Hold
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&hold, holdID).Error
+	return hold, err
+}
+
+// CaptureHold melepas seluruh hold lalu mentransfer amount (<= hold) ke merchant.
+// Capture bersifat final: sisa hold yang tidak di-capture kembali ke saldo available.
+func CaptureHold(tx *gorm.DB, hold model.Hold, amount int64, actor audit.Actor) (model.Hold, TransferResult, error) {
+	var result TransferResult
+
+	if hold.Status != model.HoldActive || time.Now().After(hold.ExpiresAt) {
+		return hold, result, ErrHoldNotActive
+	}
+	if amount <= 0 {
+		return hold, result, ErrInvalidAmount
+	}
+	if amount > hold.Amount {
+		return hold, result, ErrCaptureExceedsHold
+	}
+
+	if err := releaseHeld(tx, hold); err != nil {
+		return hold, result, err
+	}
+
+	result, err := Transfer(tx, TransferRequest{
+		FromAccountID: hold.AccountID,
+		ToAccountID:   hold.MerchantAccountID,
+		Amount:        amount,
+		Actor:         actor,
+	})
+	if err != nil {
+		return hold, result, err
+	}
+
+	before := hold
+	now := time.Now()
+	hold.Status = model.HoldCaptured
+	hold.CapturedAmount = amount
+	hold.TransactionID = &result.Transaction.TransactionID
+	hold.ClosedAt = &now
+	if err := tx.Save(&hold).Error; err != nil {
+		return hold, result, err
+	}
+
+	return hold, result, audit.Record(tx, actor, audit.Entry{
+		Action:   ActionCapture,
+		Entity:   "hold",
+		EntityID: hold.HoldID,
+		Before:   before,
+		After:    hold,
+	})
+}
+
+// ReleaseHold mengembalikan dana hold ke saldo available dengan status voided atau expired
+func ReleaseHold(tx *gorm.DB, hold model.Hold, status string, actor audit.Actor) (model.Hold, error) {
+	if hold.Status != model.HoldActive {
+		return hold, ErrHoldNotActive
+	}
+
+	if err := releaseHeld(tx, hold); err != nil {
+		return hold, err
+	}
+
+	before := hold
+	now := time.Now()
+	hold.Status = status
+	hold.ClosedAt = &now
+	if err := tx.Save(&hold).Error; err != nil {
+		return hold, err
+	}
+
+	return hold, audit.Record(tx, actor, audit.Entry{
+		Action:   ActionRelease,
+		Entity:   "hold",
+		EntityID: hold.HoldID,
+		Before:   before,
+		After:    hold,
+	})
+}
+
+// releaseHeld mengurangi held_balance akun pembayar sebesar nilai hold
+func releaseHeld(tx *gorm.DB, hold model.Hold) error {
+	account, err := LockAccount(tx, hold.AccountID)
+	if err != nil {
+		return err
+	}
+
+	account.HeldBalance -= float64(hold.Amount)
+	if account.HeldBalance < 0 {
+		account.HeldBalance = 0
+	}
+	if err := tx.Model(&account).Update("held_balance", account.HeldBalance).Error; err != nil {
+		return err
+	}
+	return PublishBalance(tx, account)
+}
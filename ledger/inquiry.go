@@ -0,0 +1,40 @@
+package ledger
+
+import (
+	"errors"
+	"task-golang-db/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInquiryInvalid = errors.New("inquiry token is invalid or was already used")
+	ErrInquiryExpired = errors.New("inquiry has expired, start a new inquiry")
+	ErrFeeChanged     = errors.New("fee changed since the inquiry, start a new inquiry")
+)
+
+// UseInquiry mengunci inquiry milik accountID, memastikan belum dipakai dan belum
+// kedaluwarsa, lalu menandainya terpakai di dalam tx
+func UseInquiry(tx *gorm.DB, token string, accountID int64) (model.TransferInquiry, error) {
+	var inquiry model.TransferInquiry
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&inquiry, "transfer_inquiry_id = ?", token).Error
+	if err == gorm.ErrRecordNotFound {
+		return inquiry, ErrInquiryInvalid
+	}
+	if err != nil {
+		return inquiry, err
+	}
+
+	if inquiry.UsedAt != nil || inquiry.FromAccountID != accountID {
+		return inquiry, ErrInquiryInvalid
+	}
+	if time.Now().After(inquiry.ExpiresAt) {
+		return inquiry, ErrInquiryExpired
+	}
+
+	now := time.Now()
+	inquiry.UsedAt = &now
+	return inquiry, tx.Model(&inquiry).Update("used_at", now).Error
+}
@@ -0,0 +1,132 @@
+package ledger
+
+import (
+	"errors"
+	"fmt"
+	"task-golang-db/model"
+	"task-golang-db/realtime"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrSenderNotFound      = errors.New("sender account not found")
+	ErrReceiverNotFound    = errors.New("target account not found")
+	ErrSameAccount         = errors.New("cannot transfer to the same account")
+	ErrInvalidAmount       = errors.New("amount must be greater than 0")
+	ErrInsufficientBalance = errors.New("insufficient balance")
+	ErrQuoteRequired       = errors.New("quote_id is required for cross-currency transfer, request one from /fx/quote")
+	ErrQuoteInvalid        = errors.New("quote does not match this transfer or was already used")
+	ErrQuoteExpired        = errors.New("quote has expired, request a new one")
+)
+
+// AccountBlockedError dikembalikan jika akun frozen/closed ikut dalam perpindahan saldo
+type AccountBlockedError struct {
+	AccountID int64
+	Status    string
+}
+
+func (e AccountBlockedError) Error() string {
+	return fmt.Sprintf("account %d is %s", e.AccountID, e.Status)
+}
+
+// CheckMoneyAllowed menolak perpindahan saldo jika salah satu akun frozen atau closed
+func CheckMoneyAllowed(accounts ...model.Account) error {
+	for _, account := range accounts {
+		if !account.CanMoveMoney() {
+			return AccountBlockedError{AccountID: account.AccountID, Status: account.Status}
+		}
+	}
+	return nil
+}
+
+// LockAccount mengambil akun dengan SELECT ... FOR UPDATE sampai tx selesai
+func LockAccount(tx *gorm.DB, accountID int64) (model.Account, error) {
+	var account model.Account
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&account, accountID).Error
+	return account, err
+}
+
+// LockAccountPair mengunci dua akun dalam urutan account_id naik, bukan urutan
+// pengirim-penerima, supaya transfer A->B dan B->A yang berjalan bersamaan tidak
+// saling deadlock. Akun yang tidak ada dikembalikan kosong (AccountID 0); error hanya
+// untuk kegagalan database seperti deadlock atau serialization failure.
+func LockAccountPair(tx *gorm.DB, firstID, secondID int64) (model.Account, model.Account, error) {
+	lowID, highID := firstID, secondID
+	if lowID > highID {
+		lowID, highID = highID, lowID
+	}
+
+	locked := make(map[int64]model.Account, 2)
+	for _, id := range []int64{lowID, highID} {
+		if _, ok := locked[id]; ok {
+			continue
+		}
+		account, err := LockAccount(tx, id)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.Account{}, model.Account{}, err
+		}
+		locked[id] = account
+	}
+	return locked[firstID], locked[secondID], nil
+}
+
+// PublishBalance mengirim saldo terbaru ke stream realtime pemilik akun
+func PublishBalance(tx *gorm.DB, account model.Account) error {
+	return realtime.Publish(tx, account.AccountID, model.AccountEventBalanceUpdated, map[string]interface{}{
+		"account_number": account.AccountNumber,
+		"balance":        account.Balance,
+		"held_balance":   account.HeldBalance,
+		"pocket_balance": account.PocketBalance,
+		"available":      account.Available(),
+	})
+}
+
+// UseFxQuote mengunci dan menandai quote sebagai terpakai. Quote harus milik
+// pengirim, untuk penerima dan nominal yang sama, dan belum kedaluwarsa.
+func UseFxQuote(tx *gorm.DB, quoteID string, sender, receiver model.Account, amount int64) (model.FxQuote, error) {
+	var quote model.FxQuote
+	if quoteID == "" {
+		return quote, ErrQuoteRequired
+	}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&quote, "fx_quote_id = ?", quoteID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return quote, ErrQuoteInvalid
+		}
+		return quote, err
+	}
+
+	if quote.UsedAt != nil ||
+		quote.FromAccountID != sender.AccountID ||
+		quote.ToAccountID != receiver.AccountID ||
+		quote.FromCurrency != sender.Currency ||
+		quote.ToCurrency != receiver.Currency ||
+		quote.SourceAmount != amount {
+		return quote, ErrQuoteInvalid
+	}
+	if time.Now().After(quote.ExpiresAt) {
+		return quote, ErrQuoteExpired
+	}
+
+	now := time.Now()
+	quote.UsedAt = &now
+	return quote, tx.Model(&quote).Update("used_at", now).Error
+}
+
+// CategoryTopUp adalah kategori transaksi untuk top-up saldo
+const CategoryTopUp = "Top Up"
+
+// CategoryID mengambil (atau membuat) kategori transaksi berdasarkan nama
+func CategoryID(tx *gorm.DB, name string) (int64, error) {
+	var id int64
+	err := tx.Raw("SELECT transaction_category_id FROM transaction_categories WHERE name = ? ORDER BY transaction_category_id LIMIT 1", name).
+		Scan(&id).Error
+	if err != nil || id != 0 {
+		return id, err
+	}
+	err = tx.Raw("INSERT INTO transaction_categories (name) VALUES (?) RETURNING transaction_category_id", name).
+		Scan(&id).Error
+	return id, err
+}
@@ -0,0 +1,118 @@
+package ledger
+
+import (
+	"errors"
+	"task-golang-db/audit"
+	"task-golang-db/model"
+	"task-golang-db/realtime"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrPocketNotFound     = errors.New("pocket not found")
+	ErrPocketClosed       = errors.New("pocket is closed")
+	ErrPocketInsufficient = errors.New("insufficient pocket balance")
+)
+
+// CategoryPocket adalah kategori transaksi untuk perpindahan internal pocket
+const CategoryPocket = "Pocket"
+
+// Aksi audit untuk pocket
+const (
+	ActionPocketDeposit  = "pocket_deposit"
+	ActionPocketWithdraw = "pocket_withdraw"
+)
+
+// LockPocket mengambil pocket milik accountID dengan SELECT ... FOR UPDATE
+func LockPocket(tx *gorm.DB, accountID, pocketID int64) (model.Pocket, error) {
+	var pocket model.Pocket
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&pocket, "pocket_id = ? AND account_id = ?", pocketID, accountID).Error
+	if err == gorm.ErrRecordNotFound {
+		return pocket, ErrPocketNotFound
+	}
+	return pocket, err
+}
+
+// MovePocket memindahkan amount dari saldo utama ke pocket (amount positif) atau
+// kembali ke saldo utama (amount negatif), dicatat sebagai transaksi internal.
+func MovePocket(tx *gorm.DB, pocket *model.Pocket, amount int64, actor audit.Actor) (model.Transaction, error) {
+	var transaction model.Transaction
+
+	if amount == 0 {
+		return transaction, ErrInvalidAmount
+	}
+	if pocket.Status != model.PocketActive {
+		return transaction, ErrPocketClosed
+	}
+
+	account, err := LockAccount(tx, pocket.AccountID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return transaction, ErrSenderNotFound
+	}
+	if err != nil {
+		return transaction, err
+	}
+	if err := CheckMoneyAllowed(account); err != nil {
+		return transaction, err
+	}
+
+	action := ActionPocketDeposit
+	if amount > 0 && account.Available() < float64(amount) {
+		return transaction, ErrInsufficientBalance
+	}
+	if amount < 0 {
+		action = ActionPocketWithdraw
+		if pocket.Balance < -amount {
+			return transaction, ErrPocketInsufficient
+		}
+	}
+
+	before := *pocket
+	pocket.Balance += amount
+	account.PocketBalance += float64(amount)
+	if err := tx.Model(pocket).Update("balance", pocket.Balance).Error; err != nil {
+		return transaction, err
+	}
+	if err := tx.Model(&account).Update("pocket_balance", account.PocketBalance).Error; err != nil {
+		return transaction, err
+	}
+
+	categoryID, err := CategoryID(tx, CategoryPocket)
+	if err != nil {
+		return transaction, err
+	}
+	transaction = model.Transaction{
+		TransactionCategoryID: &categoryID,
+		AccountID:             account.AccountID,
+		FromAccountId:         account.AccountID,
+		FromAccountNumber:     account.AccountNumber,
+		ToAccountId:           account.AccountID,
+		ToAccountNumber:       account.AccountNumber,
+		Amount:                amount,
+		Currency:              account.Currency,
+		PocketID:              &pocket.PocketID,
+		TransactionDate:       time.Now().Format("2006-01-02 15:04:05"),
+	}
+	if err := tx.Create(&transaction).Error; err != nil {
+		return transaction, err
+	}
+
+	if err := audit.Record(tx, actor, audit.Entry{
+		Action:   action,
+		Entity:   "pocket",
+		EntityID: pocket.PocketID,
+		Before:   before,
+		After:    pocket,
+	}); err != nil {
+		return transaction, err
+	}
+
+	if err := PublishBalance(tx, account); err != nil {
+		return transaction, err
+	}
+	return transaction, realtime.Publish(tx, account.AccountID, model.AccountEventMutationCreated, transaction)
+}
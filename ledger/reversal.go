@@ -0,0 +1,190 @@
+package ledger
+
+import (
+	"errors"
+	"task-golang-db/accountno"
+	"task-golang-db/audit"
+	"task-golang-db/model"
+	"task-golang-db/notification"
+	"task-golang-db/realtime"
+	"task-golang-db/webhook"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrTransactionNotFound      = errors.New("transaction not found")
+	ErrNotReversible            = errors.New("only account-to-account transfers can be reversed")
+	ErrReversalCrossCurrency    = errors.New("cross-currency transfers cannot be reversed")
+	ErrReversalExceedsRemaining = errors.New("amount exceeds the unreversed remainder of the transaction")
+)
+
+// ActionReverse adalah aksi audit untuk refund/reversal
+const ActionReverse = "reverse"
+
+// ReverseRequest adalah input refund (oleh penerima) atau reversal (oleh admin).
+// Amount 0 berarti seluruh sisa yang belum dikembalikan.
+type ReverseRequest struct {
+	TransactionID int64
+	Amount        int64
+	Kind          string
+	Reason        string
+	Actor         audit.Actor
+}
+
+type ReverseResult struct {
+	Original model.Transaction
+	Reversal model.Transaction
+	Record   model.TransactionReversal
+}
+
+// LockTransaction mengambil transaksi dengan SELECT ... FOR UPDATE
+func LockTransaction(tx *gorm.DB, transactionID int64) (model.Transaction, error) {
+	var transaction model.Transaction
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&transaction, transactionID).Error
+	if err == gorm.ErrRecordNotFound {
+		return transaction, ErrTransactionNotFound
+	}
+	return transaction, err
+}
+
+// Reverse membuat transaksi kompensasi dari penerima kembali ke pengirim tanpa
+// mengubah transaksi asal selain menambah reversed_amount.
+func Reverse(tx *gorm.DB, req ReverseRequest) (ReverseResult, error) {
+	var result ReverseResult
+
+	original, err := LockTransaction(tx, req.TransactionID)
+	if err != nil {
+		return result, err
+	}
+	if original.ReversalOfID != nil || original.FromAccountId == 0 || original.ToAccountId == 0 ||
+		original.FromAccountId == original.ToAccountId {
+		return result, ErrNotReversible
+	}
+	if original.FxQuoteID != nil {
+		return result, ErrReversalCrossCurrency
+	}
+
+	remaining := original.Amount - original.ReversedAmount
+	amount := req.Amount
+	if amount == 0 {
+		amount = remaining
+	}
+	if amount < 0 {
+		return result, ErrInvalidAmount
+	}
+	if amount == 0 || amount > remaining {
+		return result, ErrReversalExceedsRemaining
+	}
+
+	// Penerima transaksi asal yang didebit
+	payer, payee, err := LockAccountPair(tx, original.ToAccountId, original.FromAccountId)
+	if err != nil {
+		return result, err
+	}
+	if payer.AccountID == 0 {
+		return result, ErrSenderNotFound
+	}
+	if payee.AccountID == 0 {
+		return result, ErrReceiverNotFound
+	}
+	if err := CheckMoneyAllowed(payer, payee); err != nil {
+		return result, err
+	}
+	if payer.Available() < float64(amount) {
+		return result, ErrInsufficientBalance
+	}
+
+	payer.Balance -= float64(amount)
+	payee.Balance += float64(amount)
+	if err := tx.Model(&payer).Update("balance", payer.Balance).Error; err != nil {
+		return result, err
+	}
+	if err := tx.Model(&payee).Update("balance", payee.Balance).Error; err != nil {
+		return result, err
+	}
+
+	reversal := model.Transaction{
+		TransactionCategoryID: original.TransactionCategoryID,
+		AccountID:             payer.AccountID,
+		FromAccountId:         payer.AccountID,
+		FromAccountNumber:     payer.AccountNumber,
+		ToAccountId:           payee.AccountID,
+		ToAccountNumber:       payee.AccountNumber,
+		Amount:                amount,
+		Currency:              original.Currency,
+		ReversalOfID:          &original.TransactionID,
+		TransactionDate:       time.Now().Format("2006-01-02 15:04:05"),
+	}
+	if err := tx.Create(&reversal).Error; err != nil {
+		return result, err
+	}
+
+	before := original
+	original.ReversedAmount += amount
+	if err := tx.Model(&original).Update("reversed_amount", original.ReversedAmount).Error; err != nil {
+		return result, err
+	}
+
+	record := model.TransactionReversal{
+		TransactionID:         original.TransactionID,
+		ReversalTransactionID: reversal.TransactionID,
+		Kind:                  req.Kind,
+		Amount:                amount,
+		Reason:                req.Reason,
+		CreatedBy:             req.Actor.Username,
+	}
+	if err := tx.Create(&record).Error; err != nil {
+		return result, err
+	}
+
+	if err := audit.Record(tx, req.Actor, audit.Entry{
+		Action:   ActionReverse,
+		Entity:   "transaction",
+		EntityID: original.TransactionID,
+		Before:   before,
+		After:    original,
+	}); err != nil {
+		return result, err
+	}
+
+	for _, account := range []model.Account{payer, payee} {
+		if err := PublishBalance(tx, account); err != nil {
+			return result, err
+		}
+		if err := realtime.Publish(tx, account.AccountID, model.AccountEventMutationCreated, reversal); err != nil {
+			return result, err
+		}
+	}
+
+	if err := notification.Notify(tx, payee.AccountID, notification.EventTransferIncoming, map[string]interface{}{
+		"amount":              amount,
+		"from_account_number": payer.AccountNumber,
+		"balance":             int64(payee.Balance),
+	}); err != nil {
+		return result, err
+	}
+
+	if err := webhook.Enqueue(tx, webhook.EventTransferReversed, map[string]interface{}{
+		"transaction_id":          original.TransactionID,
+		"reversal_transaction_id": reversal.TransactionID,
+		"kind":                    req.Kind,
+		"amount":                  amount,
+		"currency":                original.Currency,
+		"reversed_amount":         original.ReversedAmount,
+		"reversal_state":          original.ReversalState(),
+	}); err != nil {
+		return result, err
+	}
+
+	if err := accountno.Fill(tx, &original); err != nil {
+		return result, err
+	}
+	return ReverseResult{
+		Original: original,
+		Reversal: reversal,
+		Record:   record,
+	}, nil
+}
@@ -0,0 +1,191 @@
+package ledger
+
+import (
+	"task-golang-db/audit"
+	"task-golang-db/fee"
+	"task-golang-db/limit"
+	"task-golang-db/model"
+	"task-golang-db/notification"
+	"task-golang-db/realtime"
+	"task-golang-db/webhook"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TransferRequest adalah input transfer antar akun. Semua jalur transfer
+// (endpoint, standing order, dll.) memakai Transfer supaya aturannya sama.
+type TransferRequest struct {
+	FromAccountID int64
+	ToAccountID   int64
+	Amount        int64
+	QuoteID       string
+	// ExpectedFee (opsional) adalah biaya yang sudah ditunjukkan ke nasabah; transfer
+	// ditolak dengan ErrFeeChanged jika biaya saat eksekusi berbeda
+	ExpectedFee *int64
+	Actor       audit.Actor
+}
+
+type TransferResult struct {
+	Transaction model.Transaction
+	Sender      model.Account
+	Receiver    model.Account
+	// Credit adalah nominal yang diterima dalam mata uang penerima
+	Credit int64
+	// Fee adalah biaya yang didebit dari pengirim, dicatat di FeeTransaction
+	Fee            int64
+	FeeTransaction *model.Transaction
+}
+
+// Transfer memindahkan saldo di dalam tx: lock kedua akun, cek status dan saldo,
+// hitung biaya, catat transaksi, audit, event realtime, notifikasi, dan outbox webhook.
+func Transfer(tx *gorm.DB, req TransferRequest) (TransferResult, error) {
+	var result TransferResult
+
+	if req.Amount <= 0 {
+		return result, ErrInvalidAmount
+	}
+	if req.FromAccountID == req.ToAccountID {
+		return result, ErrSameAccount
+	}
+
+	// Fetch the current and target accounts (locked until commit)
+	senderAccount, receiverAccount, err := LockAccountPair(tx, req.FromAccountID, req.ToAccountID)
+	if err != nil {
+		return result, err
+	}
+	if senderAccount.AccountID == 0 {
+		return result, ErrSenderNotFound
+	}
+	if receiverAccount.AccountID == 0 {
+		return result, ErrReceiverNotFound
+	}
+
+	if err := CheckMoneyAllowed(senderAccount, receiverAccount); err != nil {
+		return result, err
+	}
+
+	// Transfer lintas mata uang memakai kurs yang sudah dikunci lewat /fx/quote
+	credit := req.Amount
+	var quoteID *string
+	if senderAccount.Currency != receiverAccount.Currency {
+		quote, err := UseFxQuote(tx, req.QuoteID, senderAccount, receiverAccount, req.Amount)
+		if err != nil {
+			return result, err
+		}
+		credit = quote.TargetAmount
+		quoteID = &quote.FxQuoteID
+	}
+
+	// Biaya dihitung sebelum limit.Consume supaya jatah gratis bulanan belum terpakai
+	quote, err := fee.Compute(tx, senderAccount, model.OperationTransfer, req.Amount)
+	if err != nil {
+		return result, err
+	}
+	if req.ExpectedFee != nil && *req.ExpectedFee != quote.Fee {
+		return result, ErrFeeChanged
+	}
+
+	// Check balance and update if sufficient (dana yang ditahan hold tidak bisa dipakai)
+	if senderAccount.Available() < float64(quote.Total) {
+		return result, ErrInsufficientBalance
+	}
+
+	// Limit dicek saat akun pengirim ter-lock sehingga request paralel tetap berurutan
+	if err := limit.Consume(tx, senderAccount, model.OperationTransfer, req.Amount); err != nil {
+		return result, err
+	}
+
+	senderAccount.Balance -= float64(req.Amount)
+	receiverAccount.Balance += float64(credit)
+
+	if err := tx.Save(&senderAccount).Error; err != nil {
+		return result, err
+	}
+	if err := tx.Save(&receiverAccount).Error; err != nil {
+		return result, err
+	}
+
+	// Create transaction record
+	transaction := model.Transaction{
+		AccountID:         req.FromAccountID,
+		FromAccountId:     req.FromAccountID,
+		FromAccountNumber: senderAccount.AccountNumber,
+		ToAccountId:       req.ToAccountID,
+		ToAccountNumber:   receiverAccount.AccountNumber,
+		Amount:            req.Amount,
+		Currency:          senderAccount.Currency,
+		FxQuoteID:         quoteID,
+		TransactionDate:   time.Now().Format("2006-01-02 15:04:05"), // format sebagai string
+	}
+	if err := tx.Create(&transaction).Error; err != nil {
+		return result, err
+	}
+
+	feeTransaction, err := ChargeFee(tx, &senderAccount, quote, transaction)
+	if err != nil {
+		return result, err
+	}
+	if feeTransaction != nil && feeTransaction.ToAccountId == receiverAccount.AccountID {
+		receiverAccount.Balance += float64(quote.Fee)
+	}
+
+	if err := audit.Record(tx, req.Actor, audit.Entry{
+		Action:   audit.ActionTransfer,
+		Entity:   "transaction",
+		EntityID: transaction.TransactionID,
+		After:    transaction,
+	}); err != nil {
+		return result, err
+	}
+
+	// Notifikasi realtime untuk kedua pihak, terkirim setelah commit
+	for _, account := range []model.Account{senderAccount, receiverAccount} {
+		if err := PublishBalance(tx, account); err != nil {
+			return result, err
+		}
+		if err := realtime.Publish(tx, account.AccountID, model.AccountEventMutationCreated, transaction); err != nil {
+			return result, err
+		}
+	}
+
+	// Notifikasi transaksional (dikirim worker setelah commit)
+	if err := notification.Notify(tx, receiverAccount.AccountID, notification.EventTransferIncoming, map[string]interface{}{
+		"amount":              credit,
+		"from_account_number": senderAccount.AccountNumber,
+		"balance":             int64(receiverAccount.Balance),
+	}); err != nil {
+		return result, err
+	}
+	if notification.IsLargeDebit(req.Amount) {
+		if err := notification.Notify(tx, req.FromAccountID, notification.EventDebitLarge, map[string]interface{}{
+			"amount":            req.Amount,
+			"to_account_number": receiverAccount.AccountNumber,
+		}); err != nil {
+			return result, err
+		}
+	}
+
+	if err := webhook.Enqueue(tx, webhook.EventTransferCompleted, map[string]interface{}{
+		"transaction_id":    transaction.TransactionID,
+		"from_account_id":   req.FromAccountID,
+		"to_account_id":     req.ToAccountID,
+		"amount":            req.Amount,
+		"currency":          senderAccount.Currency,
+		"credited_amount":   credit,
+		"credited_currency": receiverAccount.Currency,
+		"fx_quote_id":       quoteID,
+		"fee":               quote.Fee,
+	}); err != nil {
+		return result, err
+	}
+
+	return TransferResult{
+		Transaction:    transaction,
+		Sender:         senderAccount,
+		Receiver:       receiverAccount,
+		Credit:         credit,
+		Fee:            quote.Fee,
+		FeeTransaction: feeTransaction,
+	}, nil
+}
@@ -0,0 +1,216 @@
+package limit
+
+import (
+	"fmt"
+	"task-golang-db/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Kode error yang dikembalikan ke client jika limit terlampaui
+const (
+	CodeSingleExceeded     = "LIMIT_SINGLE_EXCEEDED"
+	CodeDailyExceeded      = "LIMIT_DAILY_AMOUNT_EXCEEDED"
+	CodeMonthlyExceeded    = "LIMIT_MONTHLY_AMOUNT_EXCEEDED"
+	CodeDailyCountExceeded = "LIMIT_DAILY_COUNT_EXCEEDED"
+)
+
+// Operations adalah operasi yang bisa diberi limit
+var Operations = []string{model.OperationTransfer, model.OperationTopUp}
+
+// Error dikembalikan jika sebuah operasi melewati limit
+type Error struct {
+	Code      string `json:"code"`
+	Operation string `json:"operation"`
+	Limit     int64  `json:"limit"`
+	Remaining int64  `json:"remaining"`
+}
+
+func (e Error) Error() string {
+	return fmt.Sprintf("%s limit exceeded (%s): limit %d, remaining %d", e.Operation, e.Code, e.Limit, e.Remaining)
+}
+
+// Limits adalah limit efektif sebuah akun untuk satu operasi; nil berarti tidak dibatasi
+type Limits struct {
+	MaxSingle     *int64 `json:"max_single"`
+	MaxDaily      *int64 `json:"max_daily"`
+	MaxMonthly    *int64 `json:"max_monthly"`
+	MaxDailyCount *int64 `json:"max_daily_count"`
+}
+
+// Usage adalah pemakaian akun pada hari dan bulan berjalan
+type Usage struct {
+	DailyAmount   int64 `json:"daily_amount"`
+	MonthlyAmount int64 `json:"monthly_amount"`
+	DailyCount    int64 `json:"daily_count"`
+	MonthlyCount  int64 `json:"monthly_count"`
+}
+
+// Effective menggabungkan limit tier akun dengan override milik akun tersebut
+func Effective(db *gorm.DB, account model.Account, operation string) (Limits, error) {
+	var limits Limits
+
+	var rows []model.TransactionLimit
+	err := db.Where("operation = ? AND ((tier = ? AND account_id IS NULL) OR account_id = ?)", operation, account.Tier, account.AccountID).
+		Order("account_id NULLS FIRST").
+		Find(&rows).Error
+	if err != nil {
+		return limits, err
+	}
+
+	// Baris tier dibaca lebih dulu, lalu override menimpa field yang diisi
+	for _, row := range rows {
+		if row.MaxSingle != nil {
+			limits.MaxSingle = row.MaxSingle
+		}
+		if row.MaxDaily != nil {
+			limits.MaxDaily = row.MaxDaily
+		}
+		if row.MaxMonthly != nil {
+			limits.MaxMonthly = row.MaxMonthly
+		}
+		if row.MaxDailyCount != nil {
+			limits.MaxDailyCount = row.MaxDailyCount
+		}
+	}
+	return limits, nil
+}
+
+// CurrentUsage menghitung pemakaian hari ini dan bulan ini
+func CurrentUsage(db *gorm.DB, accountID int64, operation string, now time.Time) (Usage, error) {
+	var usage Usage
+
+	day := startOfDay(now)
+	month := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, day.Location())
+
+	var today model.LimitUsage
+	err := db.Where("account_id = ? AND operation = ? AND day = ?", accountID, operation, day).Limit(1).Find(&today).Error
+	if err != nil {
+		return usage, err
+	}
+	usage.DailyAmount = today.Amount
+	usage.DailyCount = today.Count
+
+	var monthly struct {
+		Amount int64
+		Count  int64
+	}
+	err = db.Model(&model.LimitUsage{}).
+		Select("COALESCE(SUM(amount), 0) AS amount, COALESCE(SUM(count), 0) AS count").
+		Where("account_id = ? AND operation = ? AND day >= ?", accountID, operation, month).
+		Scan(&monthly).Error
+	usage.MonthlyAmount = monthly.Amount
+	usage.MonthlyCount = monthly.Count
+	return usage, err
+}
+
+// Consume memeriksa limit lalu mencatat pemakaian. Harus dipanggil di dalam tx
+// setelah akun di-lock (FOR UPDATE) supaya request paralel tidak bisa melewati limit.
+func Consume(tx *gorm.DB, account model.Account, operation string, amount int64) error {
+	limits, err := Effective(tx, account, operation)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	usage, err := CurrentUsage(tx, account.AccountID, operation, now)
+	if err != nil {
+		return err
+	}
+
+	if err := Check(limits, usage, operation, amount); err != nil {
+		return err
+	}
+
+	return tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "account_id"}, {Name: "operation"}, {Name: "day"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"amount": gorm.Expr("limit_usages.amount + EXCLUDED.amount"),
+			"count":  gorm.Expr("limit_usages.count + 1"),
+		}),
+	}).Create(&model.LimitUsage{
+		AccountID: account.AccountID,
+		Operation: operation,
+		Day:       startOfDay(now),
+		Amount:    amount,
+		Count:     1,
+	}).Error
+}
+
+// Check mengembalikan Error jika amount melewati salah satu limit
+func Check(limits Limits, usage Usage, operation string, amount int64) error {
+	if limits.MaxSingle != nil && amount > *limits.MaxSingle {
+		return Error{Code: CodeSingleExceeded, Operation: operation, Limit: *limits.MaxSingle, Remaining: *limits.MaxSingle}
+	}
+	if limits.MaxDailyCount != nil && usage.DailyCount+1 > *limits.MaxDailyCount {
+		return Error{Code: CodeDailyCountExceeded, Operation: operation, Limit: *limits.MaxDailyCount, Remaining: remaining(*limits.MaxDailyCount, usage.DailyCount)}
+	}
+	if limits.MaxDaily != nil && usage.DailyAmount+amount > *limits.MaxDaily {
+		return Error{Code: CodeDailyExceeded, Operation: operation, Limit: *limits.MaxDaily, Remaining: remaining(*limits.MaxDaily, usage.DailyAmount)}
+	}
+	if limits.MaxMonthly != nil && usage.MonthlyAmount+amount > *limits.MaxMonthly {
+		return Error{Code: CodeMonthlyExceeded, Operation: operation, Limit: *limits.MaxMonthly, Remaining: remaining(*limits.MaxMonthly, usage.MonthlyAmount)}
+	}
+	return nil
+}
+
+// Remaining menghitung sisa limit; nil berarti tidak dibatasi
+func Remaining(limits Limits, usage Usage) map[string]*int64 {
+	result := map[string]*int64{
+		"single":      limits.MaxSingle,
+		"daily":       nil,
+		"monthly":     nil,
+		"daily_count": nil,
+	}
+	if limits.MaxDaily != nil {
+		v := remaining(*limits.MaxDaily, usage.DailyAmount)
+		result["daily"] = &v
+	}
+	if limits.MaxMonthly != nil {
+		v := remaining(*limits.MaxMonthly, usage.MonthlyAmount)
+		result["monthly"] = &v
+	}
+	if limits.MaxDailyCount != nil {
+		v := remaining(*limits.MaxDailyCount, usage.DailyCount)
+		result["daily_count"] = &v
+	}
+	return result
+}
+
+// ValidOperation memeriksa apakah operation dikenal
+func ValidOperation(operation string) bool {
+	for _, op := range Operations {
+		if op == operation {
+			return true
+		}
+	}
+	return false
+}
+
+func remaining(limit, used int64) int64 {
+	if used >= limit {
+		return 0
+	}
+	return limit - used
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// CheckMany memeriksa beberapa nominal sekaligus seolah dieksekusi berurutan,
+// dipakai untuk validasi batch sebelum dieksekusi
+func CheckMany(limits Limits, usage Usage, operation string, amounts []int64) error {
+	for _, amount := range amounts {
+		if err := Check(limits, usage, operation, amount); err != nil {
+			return err
+		}
+		usage.DailyAmount += amount
+		usage.MonthlyAmount += amount
+		usage.DailyCount++
+		usage.MonthlyCount++
+	}
+	return nil
+}
@@ -1,11 +1,22 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
 	"os"
+	"task-golang-db/batch"
 	"task-golang-db/handler"
+	"task-golang-db/interest"
 	"task-golang-db/middleware"
+	"task-golang-db/model"
+	"task-golang-db/notification"
+	"task-golang-db/realtime"
+	"task-golang-db/reconcile"
+	"task-golang-db/scheduler"
+	"task-golang-db/statement"
+	"task-golang-db/webhook"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -29,10 +40,17 @@ func main() {
 	}
 	defer sqlDB.Close()
 
+	// CLI command (contoh: go run . audit-verify)
+	if len(os.Args) > 1 {
+		runCommand(db, os.Args[1:])
+		return
+	}
+
 	// secret-key
 	signingKey := os.Getenv("SIGNING_KEY")
 
 	r := gin.Default()
+	r.Use(middleware.RequestIDMiddleware())
 
 	// grouping route with /auth
 	authHandler := handler.NewAuth(db, []byte(signingKey))
@@ -45,37 +63,262 @@ func main() {
 	accountRoutes := r.Group("/account")
 	accountRoutes.POST("/create", accountHandler.Create)
 	accountRoutes.GET("/read/:id", accountHandler.Read)
-	accountRoutes.PATCH("/update/:id", accountHandler.Update)
-	accountRoutes.DELETE("/delete/:id", accountHandler.Delete)
+	accountRoutes.PATCH("/update/:id", middleware.AuthMiddleware(signingKey, db), middleware.PermissionMiddleware(model.PermissionManage), accountHandler.Update)
+	accountRoutes.DELETE("/delete/:id", middleware.AuthMiddleware(signingKey, db), middleware.PermissionMiddleware(model.PermissionManage), accountHandler.Delete)
 	accountRoutes.GET("/list", accountHandler.List)
 	accountRoutes.POST("/topup", accountHandler.TopUp)
+	accountRoutes.PATCH("/status/:id", middleware.AuthMiddleware(signingKey, db), middleware.AdminMiddleware(), accountHandler.UpdateStatus)
+	accountRoutes.GET("/status/:id/history", middleware.AuthMiddleware(signingKey, db), middleware.AdminMiddleware(), accountHandler.StatusHistory)
+	accountRoutes.POST("/close/:id", middleware.AuthMiddleware(signingKey, db), middleware.PermissionMiddleware(model.PermissionManage), accountHandler.Close)
 
-	// middleware := middleware.AuthMiddleware(signingKey)
-	accountRoutes.GET("/my", middleware.AuthMiddleware(signingKey), accountHandler.My)
-	accountRoutes.GET("/balance", middleware.AuthMiddleware(signingKey), accountHandler.Balance)
-	accountRoutes.POST("/transfer", middleware.AuthMiddleware(signingKey), accountHandler.Transfer)
-	accountRoutes.GET("/mutation", middleware.AuthMiddleware(signingKey), accountHandler.Mutation)
+	// middleware := middleware.AuthMiddleware(signingKey, db)
+	accountRoutes.GET("/my", middleware.AuthMiddleware(signingKey, db), accountHandler.My)
+	accountRoutes.GET("/balance", middleware.AuthMiddleware(signingKey, db), accountHandler.Balance)
+	accountRoutes.GET("/balance/history", middleware.AuthMiddleware(signingKey, db), accountHandler.BalanceHistory)
+	accountRoutes.GET("/balance/as-of", middleware.AuthMiddleware(signingKey, db), accountHandler.BalanceAsOf)
+	accountRoutes.POST("/transfer", middleware.AuthMiddleware(signingKey, db), middleware.PermissionMiddleware(model.PermissionTransact), accountHandler.Transfer)
+	accountRoutes.POST("/transfer/inquiry", middleware.AuthMiddleware(signingKey, db), middleware.PermissionMiddleware(model.PermissionTransact), accountHandler.TransferInquiry)
+	accountRoutes.POST("/transfer/confirm", middleware.AuthMiddleware(signingKey, db), middleware.PermissionMiddleware(model.PermissionTransact), accountHandler.TransferConfirm)
+	accountRoutes.GET("/mutation", middleware.AuthMiddleware(signingKey, db), accountHandler.Mutation)
 
 	// grouping route with /transaction-category
 	transaction_categoryHandler := handler.NewTransCat(db)
 	transaction_categoryRoutes := r.Group("/transaction-category")
 	transaction_categoryRoutes.POST("/create", transaction_categoryHandler.Create)
 	transaction_categoryRoutes.GET("/read/:id", transaction_categoryHandler.Read)
-	transaction_categoryRoutes.PATCH("/update/:id", transaction_categoryHandler.Update)
-	transaction_categoryRoutes.DELETE("/delete/:id", transaction_categoryHandler.Delete)
+	// Kategori dipakai bersama dan dicari berdasarkan nama oleh ledger, bunga, dan biaya
+	transaction_categoryRoutes.PATCH("/update/:id", middleware.AuthMiddleware(signingKey, db), middleware.AdminMiddleware(), transaction_categoryHandler.Update)
+	transaction_categoryRoutes.DELETE("/delete/:id", middleware.AuthMiddleware(signingKey, db), middleware.AdminMiddleware(), transaction_categoryHandler.Delete)
 	transaction_categoryRoutes.GET("/list", transaction_categoryHandler.List)
 
-	transaction_categoryRoutes.GET("/my", middleware.AuthMiddleware(signingKey), transaction_categoryHandler.My)
+	transaction_categoryRoutes.GET("/my", middleware.AuthMiddleware(signingKey, db), transaction_categoryHandler.My)
 
 	transactionHandler := handler.NewTrans(db)
 	transactionRoutes := r.Group("/transaction")
 	transactionRoutes.POST("/new", transactionHandler.NewTransaction)
 	transactionRoutes.GET("/list", transactionHandler.TransactionList)
+	transactionRoutes.GET("/read/:id", middleware.AuthMiddleware(signingKey, db), transactionHandler.Read)
+	transactionRoutes.POST("/refund/:id", middleware.AuthMiddleware(signingKey, db), middleware.PermissionMiddleware(model.PermissionTransact), transactionHandler.Refund)
+	transactionRoutes.POST("/reverse/:id", middleware.AuthMiddleware(signingKey, db), middleware.AdminMiddleware(), transactionHandler.Reverse)
+
+	// grouping route with /webhook (subscription partner)
+	webhookHandler := handler.NewWebhook(db)
+	webhookRoutes := r.Group("/webhook", middleware.AuthMiddleware(signingKey, db), middleware.AdminMiddleware())
+	webhookRoutes.POST("/subscriptions", webhookHandler.Create)
+	webhookRoutes.GET("/subscriptions", webhookHandler.List)
+	webhookRoutes.DELETE("/subscriptions/:id", webhookHandler.Delete)
+	webhookRoutes.GET("/subscriptions/:id/deliveries", webhookHandler.Deliveries)
+	webhookRoutes.GET("/dead-letters", webhookHandler.DeadLetters)
+	webhookRoutes.POST("/deliveries/:id/redeliver", webhookHandler.Redeliver)
+
+	// grouping route with /notification
+	notificationHandler := handler.NewNotification(db)
+	notificationRoutes := r.Group("/notification", middleware.AuthMiddleware(signingKey, db))
+	notificationRoutes.GET("/preferences", notificationHandler.Preferences)
+	notificationRoutes.PUT("/preferences", notificationHandler.UpdatePreferences)
+	notificationRoutes.GET("/list", notificationHandler.List)
+
+	// grouping route with /fx (kurs & quote lintas mata uang)
+	fxHandler := handler.NewFx(db)
+	fxRoutes := r.Group("/fx")
+	fxRoutes.GET("/rates", fxHandler.Rates)
+	fxRoutes.POST("/rates", middleware.AuthMiddleware(signingKey, db), middleware.AdminMiddleware(), fxHandler.CreateRates)
+	fxRoutes.POST("/quote", middleware.AuthMiddleware(signingKey, db), middleware.PermissionMiddleware(model.PermissionTransact), fxHandler.Quote)
+
+	// grouping route with /standing-order (transfer terjadwal)
+	standingOrderHandler := handler.NewStandingOrder(db)
+	standingOrderRoutes := r.Group("/standing-order", middleware.AuthMiddleware(signingKey, db))
+	standingOrderRoutes.POST("/create", middleware.PermissionMiddleware(model.PermissionTransact), standingOrderHandler.Create)
+	standingOrderRoutes.GET("/list", standingOrderHandler.List)
+	standingOrderRoutes.GET("/read/:id", standingOrderHandler.Read)
+	standingOrderRoutes.GET("/executions/:id", standingOrderHandler.Executions)
+	standingOrderRoutes.POST("/pause/:id", middleware.PermissionMiddleware(model.PermissionTransact), standingOrderHandler.Pause)
+	standingOrderRoutes.POST("/resume/:id", middleware.PermissionMiddleware(model.PermissionTransact), standingOrderHandler.Resume)
+	standingOrderRoutes.POST("/cancel/:id", middleware.PermissionMiddleware(model.PermissionTransact), standingOrderHandler.Cancel)
+
+	// grouping route with /hold (otorisasi dana: hold, capture, void)
+	holdHandler := handler.NewHold(db)
+	holdRoutes := r.Group("/hold", middleware.AuthMiddleware(signingKey, db))
+	holdRoutes.POST("/create", middleware.PermissionMiddleware(model.PermissionTransact), holdHandler.Create)
+	holdRoutes.GET("/list", holdHandler.List)
+	holdRoutes.GET("/read/:id", holdHandler.Read)
+	holdRoutes.POST("/capture/:id", middleware.PermissionMiddleware(model.PermissionTransact), holdHandler.Capture)
+	holdRoutes.POST("/void/:id", middleware.PermissionMiddleware(model.PermissionTransact), holdHandler.Void)
+
+	// grouping route with /limit (limit transaksi per tier dan override per akun)
+	limitHandler := handler.NewLimit(db)
+	limitRoutes := r.Group("/limit", middleware.AuthMiddleware(signingKey, db))
+	limitRoutes.GET("/remaining", limitHandler.Remaining)
+	limitAdminRoutes := limitRoutes.Group("", middleware.AdminMiddleware())
+	limitAdminRoutes.GET("/list", limitHandler.List)
+	limitAdminRoutes.PUT("/tier/:tier/:operation", limitHandler.SetTier)
+	limitAdminRoutes.PUT("/account/:id/:operation", limitHandler.SetOverride)
+	limitAdminRoutes.DELETE("/account/:id/:operation", limitHandler.DeleteOverride)
+	limitAdminRoutes.PATCH("/account/:id/tier", limitHandler.SetAccountTier)
+
+	// grouping route with /referral
+	referralHandler := handler.NewReferral(db)
+	referralRoutes := r.Group("/referral", middleware.AuthMiddleware(signingKey, db))
+	referralRoutes.GET("/my", referralHandler.My)
+
+	// grouping route with /statement (rekening koran bulanan)
+	statementHandler := handler.NewStatement(db, statement.Dir())
+	statementRoutes := r.Group("/statement", middleware.AuthMiddleware(signingKey, db))
+	statementRoutes.GET("/download", statementHandler.Download)
+
+	// grouping route with /batch (transfer massal, mis. payroll)
+	batchHandler := handler.NewBatch(db)
+	batchRoutes := r.Group("/batch", middleware.AuthMiddleware(signingKey, db))
+	batchRoutes.POST("/create", middleware.PermissionMiddleware(model.PermissionTransact), batchHandler.Create)
+	batchRoutes.GET("/list", batchHandler.List)
+	batchRoutes.GET("/read/:id", batchHandler.Read)
+	batchRoutes.GET("/result/:id", batchHandler.Result)
+
+	// grouping route with /payment-request
+	paymentRequestHandler := handler.NewPaymentRequest(db)
+	paymentRequestRoutes := r.Group("/payment-request", middleware.AuthMiddleware(signingKey, db))
+	paymentRequestRoutes.POST("/create", middleware.PermissionMiddleware(model.PermissionTransact), paymentRequestHandler.Create)
+	paymentRequestRoutes.GET("/incoming", paymentRequestHandler.Incoming)
+	paymentRequestRoutes.GET("/outgoing", paymentRequestHandler.Outgoing)
+	paymentRequestRoutes.GET("/read/:id", paymentRequestHandler.Read)
+	paymentRequestRoutes.POST("/accept/:id", middleware.PermissionMiddleware(model.PermissionTransact), paymentRequestHandler.Accept)
+	paymentRequestRoutes.POST("/decline/:id", middleware.PermissionMiddleware(model.PermissionTransact), paymentRequestHandler.Decline)
+	paymentRequestRoutes.POST("/cancel/:id", middleware.PermissionMiddleware(model.PermissionTransact), paymentRequestHandler.Cancel)
+
+	// grouping route with /pocket (tabungan bertujuan di bawah akun)
+	pocketHandler := handler.NewPocket(db)
+	pocketRoutes := r.Group("/pocket", middleware.AuthMiddleware(signingKey, db))
+	pocketRoutes.POST("/create", middleware.PermissionMiddleware(model.PermissionTransact), pocketHandler.Create)
+	pocketRoutes.GET("/list", pocketHandler.List)
+	pocketRoutes.GET("/read/:id", pocketHandler.Read)
+	pocketRoutes.PATCH("/update/:id", middleware.PermissionMiddleware(model.PermissionTransact), pocketHandler.Update)
+	pocketRoutes.POST("/deposit/:id", middleware.PermissionMiddleware(model.PermissionTransact), pocketHandler.Deposit)
+	pocketRoutes.POST("/withdraw/:id", middleware.PermissionMiddleware(model.PermissionTransact), pocketHandler.Withdraw)
+	pocketRoutes.POST("/close/:id", middleware.PermissionMiddleware(model.PermissionTransact), pocketHandler.Close)
+
+	// grouping route with /interest (produk bunga tabungan)
+	interestConfig := interest.ConfigFromEnv()
+	interestHandler := handler.NewInterest(db, interestConfig)
+	interestRoutes := r.Group("/interest", middleware.AuthMiddleware(signingKey, db))
+	interestRoutes.GET("/my", interestHandler.My)
+	interestAdminRoutes := interestRoutes.Group("", middleware.AdminMiddleware())
+	interestAdminRoutes.GET("/products", interestHandler.Products)
+	interestAdminRoutes.POST("/products", interestHandler.CreateProduct)
+	interestAdminRoutes.PUT("/products/:id", interestHandler.UpdateProduct)
+	interestAdminRoutes.PUT("/account/:id/product", interestHandler.SetAccountProduct)
+	interestAdminRoutes.GET("/account/:id/accruals", interestHandler.Accruals)
+	interestAdminRoutes.GET("/account/:id/postings", interestHandler.Postings)
+	interestAdminRoutes.GET("/account/:id/recompute", interestHandler.Recompute)
+	interestAdminRoutes.POST("/account/:id/run", interestHandler.Run)
+
+	// grouping route with /fee (aturan biaya transfer & top-up)
+	feeHandler := handler.NewFee(db)
+	feeRoutes := r.Group("/fee", middleware.AuthMiddleware(signingKey, db))
+	feeRoutes.GET("/quote", feeHandler.Quote)
+	feeAdminRoutes := feeRoutes.Group("", middleware.AdminMiddleware())
+	feeAdminRoutes.GET("/rules", feeHandler.Rules)
+	feeAdminRoutes.POST("/rules", feeHandler.CreateRule)
+	feeAdminRoutes.PUT("/rules/:id", feeHandler.UpdateRule)
+	feeAdminRoutes.DELETE("/rules/:id", feeHandler.DeleteRule)
+
+	// grouping route with /beneficiary (penerima transfer tersimpan)
+	beneficiaryHandler := handler.NewBeneficiary(db)
+	beneficiaryRoutes := r.Group("/beneficiary", middleware.AuthMiddleware(signingKey, db))
+	beneficiaryRoutes.POST("/create", middleware.PermissionMiddleware(model.PermissionTransact), beneficiaryHandler.Create)
+	beneficiaryRoutes.GET("/list", beneficiaryHandler.List)
+	beneficiaryRoutes.GET("/frequent", beneficiaryHandler.Frequent)
+	beneficiaryRoutes.GET("/read/:id", beneficiaryHandler.Read)
+	beneficiaryRoutes.PATCH("/update/:id", middleware.PermissionMiddleware(model.PermissionTransact), beneficiaryHandler.Update)
+	beneficiaryRoutes.DELETE("/delete/:id", middleware.PermissionMiddleware(model.PermissionTransact), beneficiaryHandler.Delete)
+
+	// grouping route with /member (akun bersama: member, undangan, pilihan akun via X-Account-ID)
+	memberHandler := handler.NewMember(db)
+	memberRoutes := r.Group("/member", middleware.AuthMiddleware(signingKey, db))
+	memberRoutes.GET("/accounts", memberHandler.Accounts)
+	memberRoutes.GET("/list", memberHandler.List)
+	memberRoutes.POST("/invite", middleware.PermissionMiddleware(model.PermissionManage), memberHandler.Invite)
+	memberRoutes.GET("/invites", memberHandler.Invites)
+	memberRoutes.POST("/invite/:id/accept", memberHandler.Accept)
+	memberRoutes.POST("/invite/:id/decline", memberHandler.Decline)
+	memberRoutes.POST("/invite/:id/cancel", middleware.PermissionMiddleware(model.PermissionManage), memberHandler.Cancel)
+	memberRoutes.PATCH("/update/:id", middleware.PermissionMiddleware(model.PermissionManage), memberHandler.Update)
+	memberRoutes.DELETE("/remove/:id", memberHandler.Remove)
+
+	// grouping route with /reconciliation (pencocokan saldo dengan riwayat mutasi, admin only)
+	reconciliationHandler := handler.NewReconciliation(db)
+	reconciliationRoutes := r.Group("/reconciliation", middleware.AuthMiddleware(signingKey, db), middleware.AdminMiddleware())
+	reconciliationRoutes.GET("/status", reconciliationHandler.Status)
+	reconciliationRoutes.GET("/metrics", reconciliationHandler.Metrics)
+	reconciliationRoutes.GET("/runs", reconciliationHandler.Runs)
+	reconciliationRoutes.GET("/runs/:id", reconciliationHandler.Read)
+	reconciliationRoutes.POST("/run", reconciliationHandler.Run)
+	reconciliationRoutes.GET("/account/:id", reconciliationHandler.Check)
+	reconciliationRoutes.GET("/tickets", reconciliationHandler.Tickets)
+	reconciliationRoutes.PATCH("/tickets/:id", reconciliationHandler.UpdateTicket)
+
+	// grouping route with /qr (QR untuk menerima uang dan scan untuk membayar)
+	qrHandler := handler.NewQR(db)
+	qrRoutes := r.Group("/qr", middleware.AuthMiddleware(signingKey, db))
+	qrRoutes.GET("/receive", qrHandler.Receive)
+	qrRoutes.POST("/scan", middleware.PermissionMiddleware(model.PermissionTransact), qrHandler.Scan)
+
+	// grouping route with /audit (admin only)
+	auditHandler := handler.NewAudit(db)
+	auditRoutes := r.Group("/audit", middleware.AuthMiddleware(signingKey, db), middleware.AdminMiddleware())
+	auditRoutes.GET("/logs", auditHandler.List)
+	auditRoutes.GET("/verify", auditHandler.Verify)
+
+	// Background worker
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go webhook.NewDispatcher(db, webhook.DefaultConfig).Run(ctx)
+
+	go notification.NewWorker(db, notification.NotifiersFromEnv(), notification.DefaultWorkerConfig).Run(ctx)
+
+	go scheduler.NewWorker(db, scheduler.DefaultConfig).Run(ctx)
+	go scheduler.NewHoldExpirer(db, time.Minute).Run(ctx)
+	go batch.NewWorker(db, batch.DefaultConfig).Run(ctx)
+	go scheduler.NewPaymentRequestExpirer(db, time.Minute).Run(ctx)
+
+	statementSchedule, err := scheduler.StatementScheduleFromEnv()
+	if err != nil {
+		log.Fatal("invalid STATEMENT_CRON: ", err)
+	}
+	go scheduler.NewStatementJob(db, statement.Dir(), statementSchedule).Run(ctx)
+
+	interestSchedule, err := scheduler.InterestScheduleFromEnv()
+	if err != nil {
+		log.Fatal("invalid INTEREST_CRON: ", err)
+	}
+	go scheduler.NewInterestJob(db, interestConfig, interestSchedule).Run(ctx)
+
+	snapshotSchedule, err := scheduler.SnapshotScheduleFromEnv()
+	if err != nil {
+		log.Fatal("invalid BALANCE_SNAPSHOT_CRON: ", err)
+	}
+	go scheduler.NewSnapshotJob(db, snapshotSchedule).Run(ctx)
+
+	reconcileSchedule, err := scheduler.ReconcileScheduleFromEnv()
+	if err != nil {
+		log.Fatal("invalid RECONCILE_CRON: ", err)
+	}
+	go scheduler.NewReconcileJob(db, reconcileSchedule, reconcile.OpenTicketsFromEnv()).Run(ctx)
+
+	hub := realtime.NewHub(db)
+	go hub.Run(ctx, os.Getenv("DATABASE"))
+
+	// grouping route with /stream (realtime saldo & mutasi)
+	streamHandler := handler.NewStream(db, hub)
+	r.POST("/stream/ticket", middleware.AuthMiddleware(signingKey, db), streamHandler.Ticket)
+	streamRoutes := r.Group("/stream", middleware.StreamAuthMiddleware(signingKey, db))
+	streamRoutes.GET("/events", streamHandler.SSE)
+	streamRoutes.GET("/ws", streamHandler.WebSocket)
 
 	c := cors.New(cors.Options{
 		AllowedOrigins:   []string{"http://localhost:54733"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE"},
-		AllowedHeaders:   []string{"Authorization", "Content-Type", "Access-Control-Allow-Origin"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
+		AllowedHeaders:   []string{"Authorization", "Content-Type", "Access-Control-Allow-Origin", "X-Account-ID", "If-Match", "If-None-Match"},
+		ExposedHeaders:   []string{"ETag"},
 		AllowCredentials: true,
 	})
 
@@ -1,13 +1,22 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
+	"task-golang-db/accountno"
+
+	"task-golang-db/model"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
 )
 
-func AuthMiddleware(secretKey string) gin.HandlerFunc {
+// AuthMiddleware memvalidasi JWT lalu menentukan akun yang dipakai request: header
+// X-Account-ID berisi account_id atau nomor rekening, default akun milik login. Login
+// harus menjadi member akun tersebut; "account_id" di context adalah akun terpilih,
+// "permission" dan "transact_limit" adalah hak akses member.
+func AuthMiddleware(secretKey string, db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		tokenString := c.GetHeader("Authorization")
 
@@ -36,12 +45,121 @@ func AuthMiddleware(secretKey string) gin.HandlerFunc {
 			if username, ok := claims["username"].(string); ok {
 				c.Set("username", username)
 			}
+			if role, ok := claims["role"].(string); ok {
+				c.Set("role", role)
+			}
 		} else {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 			c.Abort()
 			return
 		}
 
+		// Pilih akun yang dipakai request dan pastikan login punya akses
+		selected := c.GetHeader("X-Account-ID")
+		accountID := c.GetInt64("account_id")
+		c.Set("home_account_id", accountID)
+		if selected != "" {
+			// Nomor rekening yang tidak terdaftar diperlakukan seperti akun yang bukan milik login
+			id, err := accountno.Ref(selected).Resolve(db)
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid X-Account-ID"})
+				c.Abort()
+				return
+			}
+			accountID = id
+		}
+
+		if !selectAccount(c, db, accountID) {
+			return
+		}
+
 		c.Next() // Authorized, Proceed to the next handler
 	}
 }
+
+// StreamAuthMiddleware mengautentikasi /stream/events dan /stream/ws. EventSource dan
+// WebSocket di browser tidak bisa mengirim header Authorization, jadi browser menukar
+// ?ticket= dari POST /stream/ticket (sekali pakai, berlaku singkat) alih-alih menaruh
+// JWT di URL yang ikut tercatat di access log. Tanpa ticket dipakai AuthMiddleware.
+func StreamAuthMiddleware(secretKey string, db *gorm.DB) gin.HandlerFunc {
+	auth := AuthMiddleware(secretKey, db)
+	return func(c *gin.Context) {
+		value := c.Query("ticket")
+		if value == "" {
+			auth(c)
+			return
+		}
+
+		var ticket model.StreamTicket
+		result := db.Raw("DELETE FROM stream_tickets WHERE stream_ticket_id = ? AND expires_at > now() RETURNING *", value).
+			Scan(&ticket)
+		if result.Error != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+			c.Abort()
+			return
+		}
+		if result.RowsAffected == 0 {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			c.Abort()
+			return
+		}
+
+		c.Set("auth_id", ticket.AuthID)
+		c.Set("home_account_id", ticket.AccountID)
+		if !selectAccount(c, db, ticket.AccountID) {
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// selectAccount memastikan login adalah member accountID lalu menyimpan akun terpilih
+// dan hak aksesnya di context. Mengembalikan false jika request sudah di-abort.
+func selectAccount(c *gin.Context, db *gorm.DB, accountID int64) bool {
+	var member model.AccountMember
+	result := db.Where("account_id = ? AND auth_id = ?", accountID, c.GetInt64("auth_id")).Limit(1).Find(&member)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		c.Abort()
+		return false
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusForbidden, gin.H{"error": "No access to this account"})
+		c.Abort()
+		return false
+	}
+	c.Set("account_id", accountID)
+	c.Set("permission", member.Permission)
+	if member.Permission == model.PermissionTransactLimited && member.TransactLimit != nil {
+		c.Set("transact_limit", *member.TransactLimit)
+	}
+	return true
+}
+
+// PermissionMiddleware hanya meneruskan request jika hak akses member atas akun
+// terpilih mencakup permission. Pasang setelah AuthMiddleware.
+func PermissionMiddleware(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !model.PermissionAllows(c.GetString("permission"), permission) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Your access to this account does not allow this action"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// AdminMiddleware hanya meneruskan request dari role admin. Pasang setelah AuthMiddleware.
+func AdminMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("role") != model.RoleAdmin {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -1,12 +1,98 @@
 package model
 
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
 type Account struct {
-	AccountID int64   `json:"account_id" gorm:"primaryKey;autoIncrement;<-:false"`
-	Name      string  `json:"name"`
-	Balance   float64 `json:"balance"`
+	// AccountID hanya dipakai internal; response publik memakai AccountNumber
+	AccountID int64 `json:"-" gorm:"primaryKey;autoIncrement;<-:false"`
+	// AccountNumber adalah nomor rekening publik (prefix + digit acak + check digit)
+	AccountNumber     string         `json:"account_number"`
+	Name              string         `json:"name"`
+	Balance           float64        `json:"balance"`
+	HeldBalance       float64        `json:"held_balance"`
+	PocketBalance     float64        `json:"pocket_balance"`
+	Currency          string         `json:"currency" gorm:"default:IDR"`
+	Tier              string         `json:"tier" gorm:"default:basic"`
+	ReferralCode      string         `json:"referral_code"`
+	ReferralAccountID *int64         `json:"-"`
+	InterestProductID *int64         `json:"interest_product_id"`
+	Status            string         `json:"status" gorm:"default:pending"`
+	StatusReason      string         `json:"status_reason"`
+	Version           int64          `json:"version" gorm:"default:1"`
+	DeletedAt         gorm.DeletedAt `json:"deleted_at"`
 }
 
 // Jika ingin menggunakan nama tabel khusus
 func (Account) TableName() string {
 	return "accounts"
 }
+
+// AccountNumberRef menunjuk field nomor rekening yang diisi dari account_id
+type AccountNumberRef struct {
+	AccountID int64
+	Number    *string
+}
+
+// AccountNumbers dipasang pada model yang tidak menampilkan account_id di JSON;
+// accountno.Fill mengisi setiap ref dengan nomor rekening akunnya
+type AccountNumbers interface {
+	AccountNumberRefs() []AccountNumberRef
+}
+
+// Status siklus hidup akun
+const (
+	AccountStatusPending = "pending"
+	AccountStatusActive  = "active"
+	AccountStatusFrozen  = "frozen"
+	AccountStatusDormant = "dormant"
+	AccountStatusClosed  = "closed"
+)
+
+// accountTransitions berisi perpindahan status yang diizinkan
+var accountTransitions = map[string][]string{
+	AccountStatusPending: {AccountStatusActive, AccountStatusClosed},
+	AccountStatusActive:  {AccountStatusFrozen, AccountStatusDormant, AccountStatusClosed},
+	AccountStatusFrozen:  {AccountStatusActive, AccountStatusClosed},
+	AccountStatusDormant: {AccountStatusActive, AccountStatusFrozen, AccountStatusClosed},
+	AccountStatusClosed:  {},
+}
+
+// CanTransition mengecek apakah status akun boleh berpindah dari from ke to
+func CanTransition(from, to string) bool {
+	for _, next := range accountTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// CanMoveMoney bernilai false untuk akun frozen atau closed
+func (a Account) CanMoveMoney() bool {
+	return a.Status != AccountStatusFrozen && a.Status != AccountStatusClosed
+}
+
+// Available adalah saldo yang bisa dipakai: saldo dikurangi dana yang ditahan (hold)
+// dan dana yang disisihkan di pocket
+func (a Account) Available() float64 {
+	return a.Balance - a.HeldBalance - a.PocketBalance
+}
+
+// AccountStatusHistory mencatat setiap perpindahan status beserta alasannya
+type AccountStatusHistory struct {
+	AccountStatusHistoryID int64     `json:"account_status_history_id" gorm:"primaryKey;autoIncrement;<-:false"`
+	AccountID              int64     `json:"account_id"`
+	FromStatus             string    `json:"from_status"`
+	ToStatus               string    `json:"to_status"`
+	Reason                 string    `json:"reason"`
+	ActorAuthID            int64     `json:"actor_auth_id"`
+	CreatedAt              time.Time `json:"created_at"`
+}
+
+func (AccountStatusHistory) TableName() string {
+	return "account_status_histories"
+}
@@ -0,0 +1,25 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Tipe event realtime untuk pemilik akun
+const (
+	AccountEventBalanceUpdated  = "balance.updated"
+	AccountEventMutationCreated = "mutation.created"
+)
+
+// AccountEvent disimpan supaya client yang reconnect bisa melanjutkan dari last-event ID
+type AccountEvent struct {
+	AccountEventID int64           `json:"account_event_id" gorm:"primaryKey;autoIncrement;<-:false"`
+	AccountID      int64           `json:"-"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload" gorm:"type:jsonb"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+func (AccountEvent) TableName() string {
+	return "account_events"
+}
@@ -0,0 +1,74 @@
+package model
+
+import "time"
+
+// Hak akses login atas sebuah akun. transact_limited sama dengan transact tetapi
+// setiap transaksi dibatasi TransactLimit.
+const (
+	PermissionView            = "view"
+	PermissionTransactLimited = "transact_limited"
+	PermissionTransact        = "transact"
+	PermissionManage          = "manage"
+)
+
+// permissionRank dipakai untuk membandingkan hak akses; transact_limited lolos
+// pengecekan transact, batas nominalnya dicek terpisah
+var permissionRank = map[string]int{
+	PermissionView:            1,
+	PermissionTransactLimited: 2,
+	PermissionTransact:        2,
+	PermissionManage:          3,
+}
+
+// ValidPermission mengecek apakah permission dikenali
+func ValidPermission(permission string) bool {
+	_, ok := permissionRank[permission]
+	return ok
+}
+
+// PermissionAllows bernilai true jika hak akses has mencakup required
+func PermissionAllows(has, required string) bool {
+	return permissionRank[has] > 0 && permissionRank[has] >= permissionRank[required]
+}
+
+// AccountMember menghubungkan login (auth) dengan akun yang boleh diakses. Pemilik
+// akun (Auth.AccountID) otomatis menjadi member dengan permission manage.
+type AccountMember struct {
+	AccountMemberID int64     `json:"account_member_id" gorm:"primaryKey;autoIncrement;<-:false"`
+	AccountID       int64     `json:"account_id"`
+	AuthID          int64     `json:"auth_id"`
+	Permission      string    `json:"permission"`
+	TransactLimit   *int64    `json:"transact_limit"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+func (AccountMember) TableName() string {
+	return "account_members"
+}
+
+// Status undangan member
+const (
+	InvitePending   = "pending"
+	InviteAccepted  = "accepted"
+	InviteDeclined  = "declined"
+	InviteCancelled = "cancelled"
+)
+
+// AccountInvite adalah undangan untuk menjadi member sebuah akun
+type AccountInvite struct {
+	AccountInviteID int64      `json:"account_invite_id" gorm:"primaryKey;autoIncrement;<-:false"`
+	AccountID       int64      `json:"account_id"`
+	AuthID          int64      `json:"auth_id"`
+	InvitedByAuthID int64      `json:"invited_by_auth_id"`
+	Permission      string     `json:"permission"`
+	TransactLimit   *int64     `json:"transact_limit"`
+	Status          string     `json:"status"`
+	ExpiresAt       time.Time  `json:"expires_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+	RespondedAt     *time.Time `json:"responded_at"`
+}
+
+func (AccountInvite) TableName() string {
+	return "account_invites"
+}
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditLog adalah catatan append-only dari setiap perubahan data.
+// Hash setiap entry mencakup PrevHash sehingga perubahan di tengah rantai terdeteksi.
+type AuditLog struct {
+	AuditLogID    int64           `json:"audit_log_id" gorm:"primaryKey;autoIncrement;<-:false"`
+	ActorAuthID   int64           `json:"actor_auth_id"`
+	ActorUsername string          `json:"actor_username"`
+	Action        string          `json:"action"`
+	Entity        string          `json:"entity"`
+	EntityID      string          `json:"entity_id"`
+	Before        json.RawMessage `json:"before" gorm:"type:json"`
+	After         json.RawMessage `json:"after" gorm:"type:json"`
+	RequestID     string          `json:"request_id"`
+	ClientIP      string          `json:"client_ip"`
+	CreatedAt     time.Time       `json:"created_at" gorm:"autoCreateTime:false"`
+	PrevHash      string          `json:"prev_hash"`
+	Hash          string          `json:"hash"`
+}
+
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
@@ -5,4 +5,11 @@ type Auth struct {
 	AccountID int64  `json:"account_id"`
 	Username  string `json:"username"`
 	Password  string `json:"password"`
+	Role      string `json:"role" gorm:"default:user"`
 }
+
+// Role yang dikenali middleware
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
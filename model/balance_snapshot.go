@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// BalanceSnapshot adalah saldo penutupan akun pada akhir satu hari (termasuk dana pocket
+// dan hold). Hanya hari yang sudah lewat yang dicatat sehingga nilainya tidak berubah lagi.
+type BalanceSnapshot struct {
+	AccountID      int64     `json:"account_id" gorm:"primaryKey"`
+	SnapshotDate   time.Time `json:"snapshot_date" gorm:"primaryKey;type:date"`
+	ClosingBalance int64     `json:"closing_balance"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+func (BalanceSnapshot) TableName() string {
+	return "balance_snapshots"
+}
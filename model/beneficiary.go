@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// Beneficiary adalah penerima transfer yang disimpan oleh sebuah akun
+type Beneficiary struct {
+	BeneficiaryID            int64      `json:"beneficiary_id" gorm:"primaryKey;autoIncrement;<-:false"`
+	AccountID                int64      `json:"-"`
+	BeneficiaryAccountID     int64      `json:"-"`
+	BeneficiaryAccountNumber string     `json:"beneficiary_account_number" gorm:"-"`
+	Nickname                 string     `json:"nickname"`
+	Favorite                 bool       `json:"favorite"`
+	TransferCount            int64      `json:"transfer_count"`
+	LastUsedAt               *time.Time `json:"last_used_at"`
+	CreatedAt                time.Time  `json:"created_at"`
+	UpdatedAt                time.Time  `json:"updated_at"`
+}
+
+func (Beneficiary) TableName() string {
+	return "beneficiaries"
+}
+
+func (b *Beneficiary) AccountNumberRefs() []AccountNumberRef {
+	return []AccountNumberRef{{AccountID: b.BeneficiaryAccountID, Number: &b.BeneficiaryAccountNumber}}
+}
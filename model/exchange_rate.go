@@ -0,0 +1,46 @@
+package model
+
+import "time"
+
+// ExchangeRate berversi: kurs baru ditambahkan dengan effective_at, bukan mengubah yang lama.
+// Rate berarti 1 BaseCurrency = Rate QuoteCurrency.
+type ExchangeRate struct {
+	ExchangeRateID int64     `json:"exchange_rate_id" gorm:"primaryKey;autoIncrement;<-:false"`
+	BaseCurrency   string    `json:"base_currency"`
+	QuoteCurrency  string    `json:"quote_currency"`
+	Rate           float64   `json:"rate"`
+	EffectiveAt    time.Time `json:"effective_at"`
+	Source         string    `json:"source"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+func (ExchangeRate) TableName() string {
+	return "exchange_rates"
+}
+
+// FxQuote adalah kurs yang dikunci untuk satu transfer lintas mata uang
+type FxQuote struct {
+	FxQuoteID     string `json:"fx_quote_id" gorm:"primaryKey"`
+	FromAccountID int64  `json:"-"`
+	ToAccountID   int64  `json:"-"`
+	// ToAccountNumber adalah nomor rekening penerima yang terikat pada quote
+	ToAccountNumber string     `json:"to_account_number" gorm:"-"`
+	FromCurrency    string     `json:"from_currency"`
+	ToCurrency      string     `json:"to_currency"`
+	MidRate         float64    `json:"mid_rate"`
+	SpreadBps       int64      `json:"spread_bps"`
+	AppliedRate     float64    `json:"applied_rate"`
+	SourceAmount    int64      `json:"source_amount"`
+	TargetAmount    int64      `json:"target_amount"`
+	ExpiresAt       time.Time  `json:"expires_at"`
+	UsedAt          *time.Time `json:"used_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+func (FxQuote) TableName() string {
+	return "fx_quotes"
+}
+
+func (q *FxQuote) AccountNumberRefs() []AccountNumberRef {
+	return []AccountNumberRef{{AccountID: q.ToAccountID, Number: &q.ToAccountNumber}}
+}
@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// FeeRule adalah aturan biaya untuk satu operasi. Biaya = FlatAmount + PercentBps dari
+// nominal, dibatasi MinFee dan MaxFee (nil = tanpa batas atas). FreePerMonth operasi
+// pertama setiap bulan gratis. Tier kosong berlaku untuk semua tier; aturan tier
+// yang spesifik didahulukan.
+type FeeRule struct {
+	FeeRuleID    int64     `json:"fee_rule_id" gorm:"primaryKey;autoIncrement;<-:false"`
+	Name         string    `json:"name"`
+	Operation    string    `json:"operation"`
+	Tier         string    `json:"tier"`
+	Currency     string    `json:"currency"`
+	FlatAmount   int64     `json:"flat_amount"`
+	PercentBps   int64     `json:"percent_bps"`
+	MinFee       int64     `json:"min_fee"`
+	MaxFee       *int64    `json:"max_fee"`
+	FreePerMonth int64     `json:"free_per_month"`
+	Active       bool      `json:"active"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func (FeeRule) TableName() string {
+	return "fee_rules"
+}
@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+// Status hold (otorisasi dana)
+const (
+	HoldActive   = "active"
+	HoldCaptured = "captured"
+	HoldVoided   = "voided"
+	HoldExpired  = "expired"
+)
+
+// Hold menahan sebagian saldo akun sampai di-capture, di-void, atau kedaluwarsa
+type Hold struct {
+	HoldID                int64      `json:"hold_id" gorm:"primaryKey;autoIncrement;<-:false"`
+	AccountID             int64      `json:"-"`
+	AccountNumber         string     `json:"account_number" gorm:"-"`
+	MerchantAccountID     int64      `json:"-"`
+	MerchantAccountNumber string     `json:"merchant_account_number" gorm:"-"`
+	Amount                int64      `json:"amount"`
+	CapturedAmount        int64      `json:"captured_amount"`
+	Currency              string     `json:"currency"`
+	Description           string     `json:"description"`
+	Status                string     `json:"status"`
+	ExpiresAt             time.Time  `json:"expires_at"`
+	TransactionID         *int64     `json:"transaction_id"`
+	CreatedAt             time.Time  `json:"created_at"`
+	UpdatedAt             time.Time  `json:"updated_at"`
+	ClosedAt              *time.Time `json:"closed_at"`
+}
+
+func (Hold) TableName() string {
+	return "holds"
+}
+
+func (h *Hold) AccountNumberRefs() []AccountNumberRef {
+	return []AccountNumberRef{
+		{AccountID: h.AccountID, Number: &h.AccountNumber},
+		{AccountID: h.MerchantAccountID, Number: &h.MerchantAccountNumber},
+	}
+}
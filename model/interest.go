@@ -0,0 +1,86 @@
+package model
+
+import "time"
+
+// Konvensi hitungan hari untuk bunga
+const (
+	DayCountAct365 = "ACT/365"
+	DayCountAct360 = "ACT/360"
+	DayCountActAct = "ACT/ACT"
+	DayCount30360  = "30/360"
+)
+
+// Periode kapitalisasi: bunga yang terkumpul dikreditkan ke saldo di akhir periode
+const (
+	CompoundingDaily     = "daily"
+	CompoundingMonthly   = "monthly"
+	CompoundingQuarterly = "quarterly"
+	CompoundingYearly    = "yearly"
+)
+
+// InterestBand adalah satu lapis saldo: bagian saldo di atas MinBalance (sampai band
+// berikutnya) mendapat AnnualRate. Rate dalam persen per tahun, contoh 2.5.
+type InterestBand struct {
+	MinBalance int64   `json:"min_balance"`
+	AnnualRate float64 `json:"annual_rate"`
+}
+
+// InterestProduct adalah produk bunga tabungan. Tanpa Bands, seluruh saldo mendapat AnnualRate.
+type InterestProduct struct {
+	InterestProductID int64          `json:"interest_product_id" gorm:"primaryKey;autoIncrement;<-:false"`
+	Name              string         `json:"name"`
+	AnnualRate        float64        `json:"annual_rate"`
+	Bands             []InterestBand `json:"bands" gorm:"serializer:json"`
+	DayCount          string         `json:"day_count"`
+	Compounding       string         `json:"compounding"`
+	// TaxBps adalah potongan pajak bunga dalam basis poin (2000 = 20%)
+	TaxBps    int64     `json:"tax_bps"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (InterestProduct) TableName() string {
+	return "interest_products"
+}
+
+// InterestAccrual adalah bunga satu hari untuk satu akun. Saldo dan aturan produk
+// disimpan sebagai snapshot supaya perhitungan bisa diulang saat audit.
+type InterestAccrual struct {
+	AccountID         int64          `json:"account_id" gorm:"primaryKey"`
+	AccrualDate       time.Time      `json:"accrual_date" gorm:"primaryKey;type:date"`
+	InterestProductID int64          `json:"interest_product_id"`
+	Balance           int64          `json:"balance"`
+	AnnualRate        float64        `json:"annual_rate"`
+	Bands             []InterestBand `json:"bands" gorm:"serializer:json"`
+	DayCount          string         `json:"day_count"`
+	Amount            float64        `json:"amount"`
+	InterestPostingID *int64         `json:"interest_posting_id"`
+	CreatedAt         time.Time      `json:"created_at"`
+}
+
+func (InterestAccrual) TableName() string {
+	return "interest_accruals"
+}
+
+// InterestPosting adalah pengkreditan bunga satu periode. Unik per akun dan akhir
+// periode sehingga job yang diulang tidak mengkreditkan dua kali.
+type InterestPosting struct {
+	InterestPostingID int64     `json:"interest_posting_id" gorm:"primaryKey;autoIncrement;<-:false"`
+	AccountID         int64     `json:"account_id"`
+	PeriodStart       time.Time `json:"period_start" gorm:"type:date"`
+	PeriodEnd         time.Time `json:"period_end" gorm:"type:date"`
+	Accrued           float64   `json:"accrued"`
+	Gross             int64     `json:"gross"`
+	Tax               int64     `json:"tax"`
+	Net               int64     `json:"net"`
+	// Remainder adalah pecahan di bawah satuan terkecil yang dibawa ke periode berikutnya
+	Remainder        float64   `json:"remainder"`
+	TransactionID    *int64    `json:"transaction_id"`
+	TaxTransactionID *int64    `json:"tax_transaction_id"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+func (InterestPosting) TableName() string {
+	return "interest_postings"
+}
@@ -0,0 +1,47 @@
+package model
+
+import "time"
+
+// Jenis operasi yang dibatasi limit
+const (
+	OperationTransfer = "transfer"
+	OperationTopUp    = "topup"
+)
+
+// Tier akun untuk limit transaksi
+const (
+	AccountTierBasic   = "basic"
+	AccountTierPremium = "premium"
+)
+
+// TransactionLimit menyimpan limit per tier (AccountID nil) atau override per akun
+// (Tier kosong). Field nil berarti tidak dibatasi untuk tier, atau ikut tier untuk override.
+type TransactionLimit struct {
+	TransactionLimitID int64     `json:"transaction_limit_id" gorm:"primaryKey;autoIncrement;<-:false"`
+	Tier               string    `json:"tier"`
+	AccountID          *int64    `json:"account_id"`
+	Operation          string    `json:"operation"`
+	MaxSingle          *int64    `json:"max_single"`
+	MaxDaily           *int64    `json:"max_daily"`
+	MaxMonthly         *int64    `json:"max_monthly"`
+	MaxDailyCount      *int64    `json:"max_daily_count"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+func (TransactionLimit) TableName() string {
+	return "transaction_limits"
+}
+
+// LimitUsage adalah akumulasi pemakaian harian per akun dan operasi
+type LimitUsage struct {
+	AccountID int64     `json:"account_id" gorm:"primaryKey"`
+	Operation string    `json:"operation" gorm:"primaryKey"`
+	Day       time.Time `json:"day" gorm:"primaryKey;type:date"`
+	Amount    int64     `json:"amount"`
+	Count     int64     `json:"count"`
+}
+
+func (LimitUsage) TableName() string {
+	return "limit_usages"
+}
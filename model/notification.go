@@ -0,0 +1,70 @@
+package model
+
+import "time"
+
+// Kanal pengiriman notifikasi
+const (
+	NotificationChannelEmail = "email"
+	NotificationChannelSMS   = "sms"
+	NotificationChannelPush  = "push"
+)
+
+// Status notifikasi di antrian
+const (
+	NotificationPending = "pending"
+	NotificationSent    = "sent"
+	NotificationFailed  = "failed"
+)
+
+// Notification adalah pesan yang sudah dirender dan menunggu dikirim oleh worker
+type Notification struct {
+	NotificationID int64      `json:"notification_id" gorm:"primaryKey;autoIncrement;<-:false"`
+	AccountID      int64      `json:"account_id"`
+	Event          string     `json:"event"`
+	Channel        string     `json:"channel"`
+	Recipient      string     `json:"recipient"`
+	Subject        string     `json:"subject"`
+	Body           string     `json:"body"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at"`
+	LastError      string     `json:"last_error"`
+	SentAt         *time.Time `json:"sent_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+func (Notification) TableName() string {
+	return "notifications"
+}
+
+// NotificationPreference menyimpan bahasa, kontak, dan kanal yang diizinkan per akun
+type NotificationPreference struct {
+	AccountID    int64     `json:"account_id" gorm:"primaryKey;autoIncrement:false"`
+	Language     string    `json:"language"`
+	Email        string    `json:"email"`
+	Phone        string    `json:"phone"`
+	PushToken    string    `json:"push_token"`
+	EmailEnabled bool      `json:"email_enabled"`
+	SMSEnabled   bool      `json:"sms_enabled"`
+	PushEnabled  bool      `json:"push_enabled"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func (NotificationPreference) TableName() string {
+	return "notification_preferences"
+}
+
+// KnownDevice dipakai untuk mendeteksi login dari perangkat baru
+type KnownDevice struct {
+	KnownDeviceID int64     `json:"known_device_id" gorm:"primaryKey;autoIncrement;<-:false"`
+	AccountID     int64     `json:"account_id"`
+	Fingerprint   string    `json:"fingerprint"`
+	UserAgent     string    `json:"user_agent"`
+	IPAddress     string    `json:"ip_address"`
+	FirstSeenAt   time.Time `json:"first_seen_at"`
+	LastSeenAt    time.Time `json:"last_seen_at"`
+}
+
+func (KnownDevice) TableName() string {
+	return "known_devices"
+}
@@ -0,0 +1,40 @@
+package model
+
+import "time"
+
+// Status payment request
+const (
+	PaymentRequestPending   = "pending"
+	PaymentRequestPaid      = "paid"
+	PaymentRequestDeclined  = "declined"
+	PaymentRequestCancelled = "cancelled"
+	PaymentRequestExpired   = "expired"
+)
+
+// PaymentRequest adalah permintaan pembayaran dari requester kepada payer
+type PaymentRequest struct {
+	PaymentRequestID       int64      `json:"payment_request_id" gorm:"primaryKey;autoIncrement;<-:false"`
+	RequesterAccountID     int64      `json:"-"`
+	RequesterAccountNumber string     `json:"requester_account_number" gorm:"-"`
+	PayerAccountID         int64      `json:"-"`
+	PayerAccountNumber     string     `json:"payer_account_number" gorm:"-"`
+	Amount                 int64      `json:"amount"`
+	Currency               string     `json:"currency"`
+	Note                   string     `json:"note"`
+	Status                 string     `json:"status"`
+	ExpiresAt              time.Time  `json:"expires_at"`
+	TransactionID          *int64     `json:"transaction_id"`
+	CreatedAt              time.Time  `json:"created_at"`
+	RespondedAt            *time.Time `json:"responded_at"`
+}
+
+func (PaymentRequest) TableName() string {
+	return "payment_requests"
+}
+
+func (r *PaymentRequest) AccountNumberRefs() []AccountNumberRef {
+	return []AccountNumberRef{
+		{AccountID: r.RequesterAccountID, Number: &r.RequesterAccountNumber},
+		{AccountID: r.PayerAccountID, Number: &r.PayerAccountNumber},
+	}
+}
@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+// Status pocket
+const (
+	PocketActive = "active"
+	PocketClosed = "closed"
+)
+
+// Pocket adalah tabungan bertujuan di bawah sebuah akun. Dana pocket tetap bagian
+// dari Account.Balance tetapi dicatat di Account.PocketBalance sehingga tidak
+// bisa dipakai transfer sampai dipindahkan kembali ke saldo utama.
+type Pocket struct {
+	PocketID     int64      `json:"pocket_id" gorm:"primaryKey;autoIncrement;<-:false"`
+	AccountID    int64      `json:"-"`
+	Name         string     `json:"name"`
+	Balance      int64      `json:"balance"`
+	TargetAmount int64      `json:"target_amount"`
+	Deadline     *time.Time `json:"deadline"`
+	Status       string     `json:"status"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	ClosedAt     *time.Time `json:"closed_at"`
+}
+
+func (Pocket) TableName() string {
+	return "pockets"
+}
+
+// Progress adalah persentase saldo terhadap target (0 jika tanpa target)
+func (p Pocket) Progress() float64 {
+	if p.TargetAmount <= 0 {
+		return 0
+	}
+	return float64(p.Balance) * 100 / float64(p.TargetAmount)
+}
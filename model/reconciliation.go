@@ -0,0 +1,83 @@
+package model
+
+import "time"
+
+// Status run rekonsiliasi
+const (
+	ReconciliationRunning   = "running"
+	ReconciliationCompleted = "completed"
+	ReconciliationFailed    = "failed"
+)
+
+// Kolom saldo akun yang direkonsiliasi
+const (
+	ReconcileFieldBalance       = "balance"
+	ReconcileFieldHeldBalance   = "held_balance"
+	ReconcileFieldPocketBalance = "pocket_balance"
+)
+
+// ReconciliationRun adalah satu kali pengecekan saldo semua akun terhadap riwayat mutasi
+type ReconciliationRun struct {
+	ReconciliationRunID int64      `json:"reconciliation_run_id" gorm:"primaryKey;autoIncrement;<-:false"`
+	Trigger             string     `json:"trigger"`
+	Status              string     `json:"status"`
+	AccountsChecked     int        `json:"accounts_checked"`
+	DiscrepancyCount    int        `json:"discrepancy_count"`
+	TotalDrift          float64    `json:"total_drift"`
+	TicketsOpened       int        `json:"tickets_opened"`
+	Error               string     `json:"error"`
+	StartedAt           time.Time  `json:"started_at"`
+	FinishedAt          *time.Time `json:"finished_at"`
+}
+
+func (ReconciliationRun) TableName() string {
+	return "reconciliation_runs"
+}
+
+// ReconciliationDiscrepancy adalah selisih satu kolom saldo akun pada sebuah run.
+// Difference = Stored - Expected (positif berarti saldo tersimpan lebih besar).
+type ReconciliationDiscrepancy struct {
+	ReconciliationDiscrepancyID int64     `json:"reconciliation_discrepancy_id" gorm:"primaryKey;autoIncrement;<-:false"`
+	ReconciliationRunID         int64     `json:"reconciliation_run_id"`
+	AccountID                   int64     `json:"account_id"`
+	Field                       string    `json:"field"`
+	Stored                      float64   `json:"stored"`
+	Expected                    int64     `json:"expected"`
+	Difference                  float64   `json:"difference"`
+	MovementCount               int       `json:"movement_count"`
+	CorrectionTicketID          *int64    `json:"correction_ticket_id"`
+	CreatedAt                   time.Time `json:"created_at"`
+}
+
+func (ReconciliationDiscrepancy) TableName() string {
+	return "reconciliation_discrepancies"
+}
+
+// Status tiket koreksi
+const (
+	TicketOpen      = "open"
+	TicketResolved  = "resolved"
+	TicketDismissed = "dismissed"
+)
+
+// CorrectionTicket adalah tiket tindak lanjut untuk selisih saldo. Satu akun dan kolom
+// hanya punya satu tiket open; run berikutnya menautkan selisih baru ke tiket yang sama.
+type CorrectionTicket struct {
+	CorrectionTicketID  int64      `json:"correction_ticket_id" gorm:"primaryKey;autoIncrement;<-:false"`
+	AccountID           int64      `json:"account_id"`
+	Field               string     `json:"field"`
+	Stored              float64    `json:"stored"`
+	Expected            int64      `json:"expected"`
+	Difference          float64    `json:"difference"`
+	ReconciliationRunID int64      `json:"reconciliation_run_id"`
+	Status              string     `json:"status"`
+	Note                string     `json:"note"`
+	ResolvedByAuthID    *int64     `json:"resolved_by_auth_id"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+	ResolvedAt          *time.Time `json:"resolved_at"`
+}
+
+func (CorrectionTicket) TableName() string {
+	return "correction_tickets"
+}
@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+// Status referral
+const (
+	ReferralPending  = "pending"
+	ReferralRewarded = "rewarded"
+	ReferralRejected = "rejected"
+)
+
+// Referral mencatat akun yang mendaftar dengan kode referral akun lain
+type Referral struct {
+	ReferralID           int64      `json:"referral_id" gorm:"primaryKey;autoIncrement;<-:false"`
+	ReferrerAccountID    int64      `json:"-"`
+	RefereeAccountID     int64      `json:"-"`
+	RefereeAccountNumber string     `json:"referee_account_number" gorm:"-"`
+	Status               string     `json:"status"`
+	RejectReason         string     `json:"reject_reason"`
+	ReferrerBonus        int64      `json:"referrer_bonus"`
+	RefereeBonus         int64      `json:"referee_bonus"`
+	QualifiedAt          *time.Time `json:"qualified_at"`
+	CreatedAt            time.Time  `json:"created_at"`
+}
+
+func (Referral) TableName() string {
+	return "referrals"
+}
+
+func (r *Referral) AccountNumberRefs() []AccountNumberRef {
+	return []AccountNumberRef{{AccountID: r.RefereeAccountID, Number: &r.RefereeAccountNumber}}
+}
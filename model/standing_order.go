@@ -0,0 +1,68 @@
+package model
+
+import "time"
+
+// Jenis jadwal standing order
+const (
+	ScheduleOnce    = "once"
+	ScheduleDaily   = "daily"
+	ScheduleWeekly  = "weekly"
+	ScheduleMonthly = "monthly"
+	ScheduleCron    = "cron"
+)
+
+// Status standing order
+const (
+	StandingOrderActive    = "active"
+	StandingOrderPaused    = "paused"
+	StandingOrderCancelled = "cancelled"
+	StandingOrderCompleted = "completed"
+)
+
+// StandingOrder adalah transfer terjadwal (sekali di masa depan atau berulang)
+type StandingOrder struct {
+	StandingOrderID int64      `json:"standing_order_id" gorm:"primaryKey;autoIncrement;<-:false"`
+	AccountID       int64      `json:"-"`
+	ToAccountID     int64      `json:"-"`
+	ToAccountNumber string     `json:"to_account_number" gorm:"-"`
+	Amount          int64      `json:"amount"`
+	Description     string     `json:"description"`
+	ScheduleType    string     `json:"schedule_type"`
+	CronExpr        string     `json:"cron_expr"`
+	StartAt         time.Time  `json:"start_at"`
+	EndAt           *time.Time `json:"end_at"`
+	MaxCount        *int       `json:"max_count"`
+	ExecutedCount   int        `json:"executed_count"`
+	NextRunAt       *time.Time `json:"next_run_at"`
+	// ScheduledFor adalah jadwal asli run yang sedang dicoba ulang (retry tidak menggeser jadwal berikutnya)
+	ScheduledFor *time.Time `json:"scheduled_for"`
+	RetryCount   int        `json:"retry_count"`
+	Status       string     `json:"status"`
+	LastError    string     `json:"last_error"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+func (StandingOrder) TableName() string {
+	return "standing_orders"
+}
+
+func (o *StandingOrder) AccountNumberRefs() []AccountNumberRef {
+	return []AccountNumberRef{{AccountID: o.ToAccountID, Number: &o.ToAccountNumber}}
+}
+
+// StandingOrderExecution mencatat setiap percobaan eksekusi standing order
+type StandingOrderExecution struct {
+	StandingOrderExecutionID int64     `json:"standing_order_execution_id" gorm:"primaryKey;autoIncrement;<-:false"`
+	StandingOrderID          int64     `json:"standing_order_id"`
+	ScheduledFor             time.Time `json:"scheduled_for"`
+	Attempt                  int       `json:"attempt"`
+	Success                  bool      `json:"success"`
+	TransactionID            *int64    `json:"transaction_id"`
+	Error                    string    `json:"error"`
+	CreatedAt                time.Time `json:"created_at"`
+}
+
+func (StandingOrderExecution) TableName() string {
+	return "standing_order_executions"
+}
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// StreamTicket adalah tiket sekali pakai untuk membuka SSE/WebSocket dari browser
+// sebagai pengganti JWT di URL. AccountID adalah akun terpilih saat tiket diterbitkan.
+type StreamTicket struct {
+	StreamTicketID string    `json:"ticket" gorm:"primaryKey"`
+	AuthID         int64     `json:"-"`
+	AccountID      int64     `json:"-"`
+	ExpiresAt      time.Time `json:"expires_at"`
+	CreatedAt      time.Time `json:"-"`
+}
+
+func (StreamTicket) TableName() string {
+	return "stream_tickets"
+}
@@ -4,6 +4,7 @@ type TransCat struct {
 	AccountID             int64  `json:"account_id" gorm:"primaryKey;autoIncrement;<-:false"`
 	TransactionCategoryID int64  `json:"transaction_category_id" gorm:"primaryKey;autoIncrement;<-:false"`
 	Name                  string `json:"name"`
+	Version               int64  `json:"version" gorm:"default:1"`
 }
 
 // Untuk memastikan ORM menggunakan nama tabel yang benar
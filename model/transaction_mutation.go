@@ -1,11 +1,78 @@
 package model
 
+import "time"
+
+// Transaction adalah satu mutasi saldo. Transaksi kompensasi (refund/reversal)
+// menunjuk transaksi asal lewat ReversalOfID; ReversedAmount pada transaksi asal
+// adalah total nominal yang sudah dikembalikan. Perpindahan internal ke/dari pocket
+// memiliki PocketID dengan Amount positif (masuk pocket) atau negatif (kembali ke saldo utama).
+// Biaya dicatat sebagai transfer terpisah ke akun pendapatan dengan FeeOfID menunjuk transaksi asal.
 type Transaction struct {
-	TransactionID         int8   `json:"transaction_id" gorm:"primaryKey;autoIncrement;<-:false"`
-	TransactionCategoryID int8   `json:"transaction_category_id"`
-	AccountID             int8   `json:"account_id"`
-	FromAccountId         int8   `json:"from_account_id"`
-	ToAccountId           int8   `json:"to_account_id"`
-	Amount                int8   `json:"amount"`
-	TransactionDate       string `json:"transaction_date"`
+	TransactionID         int64   `json:"transaction_id" gorm:"primaryKey;autoIncrement;<-:false"`
+	TransactionCategoryID *int64  `json:"transaction_category_id"`
+	AccountID             int64   `json:"-"`
+	FromAccountId         int64   `json:"-"`
+	FromAccountNumber     string  `json:"from_account_number" gorm:"-"`
+	ToAccountId           int64   `json:"-"`
+	ToAccountNumber       string  `json:"to_account_number" gorm:"-"`
+	Amount                int64   `json:"amount"`
+	Currency              string  `json:"currency" gorm:"default:IDR"`
+	FxQuoteID             *string `json:"fx_quote_id"`
+	TransactionDate       string  `json:"transaction_date"`
+	ReversalOfID          *int64  `json:"reversal_of_id"`
+	ReversedAmount        int64   `json:"reversed_amount"`
+	PocketID              *int64  `json:"pocket_id"`
+	FeeOfID               *int64  `json:"fee_of_id"`
+}
+
+// Tabel transaksi bernama "transaction" (bukan bentuk jamak)
+func (Transaction) TableName() string {
+	return "transaction"
+}
+
+func (t *Transaction) AccountNumberRefs() []AccountNumberRef {
+	return []AccountNumberRef{
+		{AccountID: t.FromAccountId, Number: &t.FromAccountNumber},
+		{AccountID: t.ToAccountId, Number: &t.ToAccountNumber},
+	}
+}
+
+// Jenis reversal
+const (
+	ReversalRefund  = "refund"
+	ReversalReverse = "reversal"
+)
+
+// Status reversal sebuah transaksi
+const (
+	ReversalStateNone    = "none"
+	ReversalStatePartial = "partial"
+	ReversalStateFull    = "full"
+)
+
+// ReversalState menghitung status reversal dari ReversedAmount
+func (t Transaction) ReversalState() string {
+	switch {
+	case t.ReversedAmount == 0:
+		return ReversalStateNone
+	case t.ReversedAmount < t.Amount:
+		return ReversalStatePartial
+	}
+	return ReversalStateFull
+}
+
+// TransactionReversal mencatat setiap refund/reversal beserta alasan dan pelakunya
+type TransactionReversal struct {
+	TransactionReversalID int64     `json:"transaction_reversal_id" gorm:"primaryKey;autoIncrement;<-:false"`
+	TransactionID         int64     `json:"transaction_id"`
+	ReversalTransactionID int64     `json:"reversal_transaction_id"`
+	Kind                  string    `json:"kind"`
+	Amount                int64     `json:"amount"`
+	Reason                string    `json:"reason"`
+	CreatedBy             string    `json:"created_by"`
+	CreatedAt             time.Time `json:"created_at"`
+}
+
+func (TransactionReversal) TableName() string {
+	return "transaction_reversals"
 }
@@ -0,0 +1,72 @@
+package model
+
+import "time"
+
+// Mode eksekusi batch transfer
+const (
+	BatchModePartial = "partial"
+	BatchModeAtomic  = "atomic"
+)
+
+// Status batch transfer
+const (
+	BatchPending         = "pending"
+	BatchProcessing      = "processing"
+	BatchCompleted       = "completed"
+	BatchPartiallyFailed = "partially_failed"
+	BatchFailed          = "failed"
+)
+
+// Status baris batch transfer
+const (
+	BatchItemPending = "pending"
+	BatchItemSuccess = "success"
+	BatchItemFailed  = "failed"
+)
+
+// TransferBatch adalah kumpulan transfer (mis. payroll) yang dieksekusi worker secara asinkron
+type TransferBatch struct {
+	TransferBatchID int64      `json:"transfer_batch_id" gorm:"primaryKey;autoIncrement;<-:false"`
+	AccountID       int64      `json:"account_id"`
+	Mode            string     `json:"mode"`
+	Status          string     `json:"status"`
+	Currency        string     `json:"currency"`
+	TotalAmount     int64      `json:"total_amount"`
+	ItemCount       int        `json:"item_count"`
+	SuccessCount    int        `json:"success_count"`
+	FailedCount     int        `json:"failed_count"`
+	Error           string     `json:"error"`
+	CreatedByAuthID int64      `json:"-"`
+	CreatedBy       string     `json:"created_by"`
+	RequestID       string     `json:"-"`
+	LockedUntil     *time.Time `json:"-"`
+	CreatedAt       time.Time  `json:"created_at"`
+	StartedAt       *time.Time `json:"started_at"`
+	CompletedAt     *time.Time `json:"completed_at"`
+}
+
+func (TransferBatch) TableName() string {
+	return "transfer_batches"
+}
+
+// TransferBatchItem adalah satu baris penerima dalam batch beserta hasilnya
+type TransferBatchItem struct {
+	TransferBatchItemID int64  `json:"transfer_batch_item_id" gorm:"primaryKey;autoIncrement;<-:false"`
+	TransferBatchID     int64  `json:"transfer_batch_id"`
+	RowNumber           int    `json:"row_number"`
+	ToAccountID         int64  `json:"-"`
+	ToAccountNumber     string `json:"to_account_number" gorm:"-"`
+	Amount              int64  `json:"amount"`
+	Description         string `json:"description"`
+	Status              string `json:"status"`
+	TransactionID       *int64 `json:"transaction_id"`
+	Error               string `json:"error"`
+}
+
+func (TransferBatchItem) TableName() string {
+	return "transfer_batch_items"
+}
+
+func (i *TransferBatchItem) AccountNumberRefs() []AccountNumberRef {
+	return []AccountNumberRef{{AccountID: i.ToAccountID, Number: &i.ToAccountNumber}}
+}
@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// TransferInquiry adalah ringkasan transfer yang sudah divalidasi (penerima, nominal,
+// biaya). Konfirmasi hanya bisa mengeksekusi isi inquiry ini, satu kali, sebelum ExpiresAt.
+type TransferInquiry struct {
+	TransferInquiryID string     `json:"inquiry_token" gorm:"primaryKey"`
+	FromAccountID     int64      `json:"-"`
+	ToAccountID       int64      `json:"-"`
+	Amount            int64      `json:"amount"`
+	Fee               int64      `json:"fee"`
+	Currency          string     `json:"currency"`
+	Credit            int64      `json:"credit"`
+	CreditCurrency    string     `json:"credit_currency"`
+	FxQuoteID         *string    `json:"fx_quote_id"`
+	ExpiresAt         time.Time  `json:"expires_at"`
+	UsedAt            *time.Time `json:"used_at"`
+	TransactionID     *int64     `json:"transaction_id"`
+	CreatedAt         time.Time  `json:"created_at"`
+}
+
+func (TransferInquiry) TableName() string {
+	return "transfer_inquiries"
+}
@@ -0,0 +1,67 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Status pengiriman webhook
+const (
+	WebhookDeliveryPending    = "pending"
+	WebhookDeliveryDelivered  = "delivered"
+	WebhookDeliveryDeadLetter = "dead_letter"
+)
+
+type WebhookSubscription struct {
+	WebhookSubscriptionID int64     `json:"webhook_subscription_id" gorm:"primaryKey;autoIncrement;<-:false"`
+	Partner               string    `json:"partner"`
+	URL                   string    `json:"url"`
+	Secret                string    `json:"-" audit:"-"`
+	EventTypes            []string  `json:"event_types" gorm:"serializer:json"`
+	Active                bool      `json:"active"`
+	CreatedAt             time.Time `json:"created_at"`
+}
+
+func (WebhookSubscription) TableName() string {
+	return "webhook_subscriptions"
+}
+
+// Subscribed mengecek apakah subscription menerima event dengan tipe tertentu
+func (s WebhookSubscription) Subscribed(eventType string) bool {
+	for _, t := range s.EventTypes {
+		if t == eventType || t == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// OutboxEvent ditulis dalam transaksi DB yang sama dengan perpindahan saldo
+type OutboxEvent struct {
+	OutboxEventID int64           `json:"outbox_event_id" gorm:"primaryKey;autoIncrement;<-:false"`
+	EventType     string          `json:"event_type"`
+	Payload       json.RawMessage `json:"payload" gorm:"type:jsonb"`
+	CreatedAt     time.Time       `json:"created_at"`
+	ProcessedAt   *time.Time      `json:"processed_at"`
+}
+
+func (OutboxEvent) TableName() string {
+	return "outbox_events"
+}
+
+type WebhookDelivery struct {
+	WebhookDeliveryID     int64      `json:"webhook_delivery_id" gorm:"primaryKey;autoIncrement;<-:false"`
+	WebhookSubscriptionID int64      `json:"webhook_subscription_id"`
+	OutboxEventID         int64      `json:"outbox_event_id"`
+	Status                string     `json:"status"`
+	Attempts              int        `json:"attempts"`
+	NextAttemptAt         time.Time  `json:"next_attempt_at"`
+	LastStatusCode        int        `json:"last_status_code"`
+	LastError             string     `json:"last_error"`
+	DeliveredAt           *time.Time `json:"delivered_at"`
+	CreatedAt             time.Time  `json:"created_at"`
+}
+
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
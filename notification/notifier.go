@@ -0,0 +1,211 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/smtp"
+	"os"
+	"strings"
+	"sync"
+	"task-golang-db/model"
+	"time"
+)
+
+// Message adalah pesan yang sudah dirender dan siap dikirim lewat satu kanal
+type Message struct {
+	Recipient string
+	Subject   string
+	Body      string
+}
+
+// Notifier mengirim pesan lewat satu kanal (email, sms, push)
+type Notifier interface {
+	Send(ctx context.Context, message Message) error
+}
+
+// SMTPNotifier mengirim email lewat server SMTP
+type SMTPNotifier struct {
+	Addr     string
+	From     string
+	Username string
+	Password string
+	// Timeout membatasi seluruh percakapan SMTP jika ctx tidak punya deadline (default 30 detik)
+	Timeout time.Duration
+}
+
+// Send mengikuti alur smtp.SendMail, tetapi koneksi diberi deadline dan ditutup saat
+// ctx dibatalkan supaya server SMTP yang menggantung tidak menahan worker
+func (n *SMTPNotifier) Send(ctx context.Context, message Message) error {
+	host := n.Addr
+	if i := strings.LastIndex(host, ":"); i >= 0 {
+		host = host[:i]
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		timeout := n.Timeout
+		if timeout <= 0 {
+			timeout = 30 * time.Second
+		}
+		deadline = time.Now().Add(timeout)
+	}
+
+	dialer := net.Dialer{Deadline: deadline}
+	conn, err := dialer.DialContext(ctx, "tcp", n.Addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if n.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", n.Username, n.Password, host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(n.From); err != nil {
+		return err
+	}
+	if err := client.Rcpt(message.Recipient); err != nil {
+		return err
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	body := "From: " + n.From + "\r\n" +
+		"To: " + message.Recipient + "\r\n" +
+		"Subject: " + message.Subject + "\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: text/plain; charset=UTF-8\r\n" +
+		"\r\n" + message.Body
+	if _, err := io.WriteString(w, body); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// HTTPNotifier mengirim pesan ke gateway SMS atau push berbasis HTTP (JSON)
+type HTTPNotifier struct {
+	URL    string
+	APIKey string
+	Client *http.Client
+}
+
+func (n *HTTPNotifier) Send(ctx context.Context, message Message) error {
+	payload, err := json.Marshal(map[string]string{
+		"to":      message.Recipient,
+		"title":   message.Subject,
+		"message": message.Body,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+n.APIKey)
+
+	client := n.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("gateway responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// LogNotifier menulis pesan ke file atau log, untuk development dan testing
+type LogNotifier struct {
+	Channel string
+	Path    string
+
+	mu sync.Mutex
+}
+
+func (n *LogNotifier) Send(ctx context.Context, message Message) error {
+	line := fmt.Sprintf("%s [%s] to=%s subject=%q body=%q\n",
+		time.Now().Format(time.RFC3339), n.Channel, message.Recipient, message.Subject, message.Body)
+
+	if n.Path == "" {
+		log.Print("notification: ", line)
+		return nil
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	f, err := os.OpenFile(n.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.WriteString(line)
+	return err
+}
+
+var errChannelNotConfigured = errors.New("notification channel not configured")
+
+// NotifiersFromEnv menyusun notifier per kanal dari environment variable.
+// Kanal yang tidak dikonfigurasi memakai LogNotifier (NOTIFICATION_LOG_FILE, kosong = log standar).
+func NotifiersFromEnv() map[string]Notifier {
+	logFile := os.Getenv("NOTIFICATION_LOG_FILE")
+	notifiers := map[string]Notifier{
+		model.NotificationChannelEmail: &LogNotifier{Channel: model.NotificationChannelEmail, Path: logFile},
+		model.NotificationChannelSMS:   &LogNotifier{Channel: model.NotificationChannelSMS, Path: logFile},
+		model.NotificationChannelPush:  &LogNotifier{Channel: model.NotificationChannelPush, Path: logFile},
+	}
+
+	if addr := os.Getenv("SMTP_ADDR"); addr != "" {
+		notifiers[model.NotificationChannelEmail] = &SMTPNotifier{
+			Addr:     addr,
+			From:     os.Getenv("SMTP_FROM"),
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+		}
+	}
+	if url := os.Getenv("SMS_GATEWAY_URL"); url != "" {
+		notifiers[model.NotificationChannelSMS] = &HTTPNotifier{URL: url, APIKey: os.Getenv("SMS_GATEWAY_KEY")}
+	}
+	if url := os.Getenv("PUSH_GATEWAY_URL"); url != "" {
+		notifiers[model.NotificationChannelPush] = &HTTPNotifier{URL: url, APIKey: os.Getenv("PUSH_GATEWAY_KEY")}
+	}
+	return notifiers
+}
@@ -0,0 +1,68 @@
+package notification
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// hungServer menerima koneksi TCP tetapi tidak pernah mengirim greeting SMTP
+func hungServer(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mu sync.Mutex
+	var conns []net.Conn
+	t.Cleanup(func() {
+		listener.Close()
+		mu.Lock()
+		defer mu.Unlock()
+		for _, conn := range conns {
+			conn.Close()
+		}
+	})
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			conns = append(conns, conn)
+			mu.Unlock()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func TestSMTPSendTimesOut(t *testing.T) {
+	notifier := &SMTPNotifier{Addr: hungServer(t), From: "noreply@example.com", Timeout: 100 * time.Millisecond}
+
+	start := time.Now()
+	err := notifier.Send(context.Background(), Message{Recipient: "budi@example.com", Subject: "Test", Body: "Test"})
+	if err == nil {
+		t.Fatal("Send to a hung server succeeded")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Send returned after %v, want about the 100ms timeout", elapsed)
+	}
+}
+
+func TestSMTPSendStopsOnCancel(t *testing.T) {
+	notifier := &SMTPNotifier{Addr: hungServer(t), From: "noreply@example.com", Timeout: time.Minute}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	start := time.Now()
+	if err := notifier.Send(ctx, Message{Recipient: "budi@example.com"}); err == nil {
+		t.Fatal("Send to a hung server succeeded")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Send returned after %v, want shortly after cancel", elapsed)
+	}
+}
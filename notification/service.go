@@ -0,0 +1,271 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"log"
+	"os"
+	"strconv"
+	"task-golang-db/model"
+	"text/template"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Event notifikasi transaksional
+const (
+	EventTransferIncoming    = "transfer.incoming"
+	EventDebitLarge          = "debit.large"
+	EventPasswordChanged     = "password.changed"
+	EventLoginNewDevice      = "login.new_device"
+	EventStandingOrderFailed = "standing_order.failed"
+	EventReferralRewarded    = "referral.rewarded"
+
+	EventPaymentRequestReceived = "payment_request.received"
+	EventPaymentRequestPaid     = "payment_request.paid"
+	EventPaymentRequestDeclined = "payment_request.declined"
+	EventPaymentRequestExpired  = "payment_request.expired"
+
+	EventInterestPosted = "interest.posted"
+
+	EventMemberInvited = "member.invited"
+)
+
+// Bahasa yang didukung; DefaultLanguage dipakai jika preferensi belum diisi
+const (
+	LanguageIndonesian = "id"
+	LanguageEnglish    = "en"
+	DefaultLanguage    = LanguageIndonesian
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+var templates = map[string]*template.Template{
+	LanguageIndonesian: template.Must(template.ParseFS(templateFS, "templates/id.tmpl")),
+	LanguageEnglish:    template.Must(template.ParseFS(templateFS, "templates/en.tmpl")),
+}
+
+// SupportedLanguage mengecek apakah template tersedia untuk bahasa tersebut
+func SupportedLanguage(language string) bool {
+	_, ok := templates[language]
+	return ok
+}
+
+// Render menghasilkan subject dan body untuk event dalam bahasa yang diminta
+func Render(language, event string, data map[string]interface{}) (string, string, error) {
+	tmpl, ok := templates[language]
+	if !ok {
+		tmpl = templates[DefaultLanguage]
+	}
+
+	var subject, body bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&subject, event+".subject", data); err != nil {
+		return "", "", err
+	}
+	if err := tmpl.ExecuteTemplate(&body, event+".body", data); err != nil {
+		return "", "", err
+	}
+	return subject.String(), body.String(), nil
+}
+
+// Notify merender pesan sesuai preferensi akun lalu memasukkannya ke antrian.
+// Panggil dengan tx yang sama dengan perubahan data supaya notifikasi ikut di-rollback.
+func Notify(tx *gorm.DB, accountID int64, event string, data map[string]interface{}) error {
+	var pref model.NotificationPreference
+	if err := tx.First(&pref, accountID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			// Akun belum mengatur preferensi, tidak ada kanal yang aktif
+			return nil
+		}
+		return err
+	}
+
+	var account model.Account
+	if err := tx.First(&account, accountID).Error; err != nil {
+		return err
+	}
+	if _, ok := data["name"]; !ok {
+		data["name"] = account.Name
+	}
+
+	subject, body, err := Render(pref.Language, event, data)
+	if err != nil {
+		return err
+	}
+
+	recipients := map[string]string{}
+	if pref.EmailEnabled && pref.Email != "" {
+		recipients[model.NotificationChannelEmail] = pref.Email
+	}
+	if pref.SMSEnabled && pref.Phone != "" {
+		recipients[model.NotificationChannelSMS] = pref.Phone
+	}
+	if pref.PushEnabled && pref.PushToken != "" {
+		recipients[model.NotificationChannelPush] = pref.PushToken
+	}
+
+	for channel, recipient := range recipients {
+		notification := model.Notification{
+			AccountID:     accountID,
+			Event:         event,
+			Channel:       channel,
+			Recipient:     recipient,
+			Subject:       subject,
+			Body:          body,
+			Status:        model.NotificationPending,
+			NextAttemptAt: time.Now(),
+		}
+		if err := tx.Create(&notification).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type WorkerConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+	MaxAttempts  int
+	BaseBackoff  time.Duration
+}
+
+// DefaultWorkerConfig mengisi field WorkerConfig yang nol di NewWorker
+var DefaultWorkerConfig = WorkerConfig{
+	PollInterval: 2 * time.Second,
+	BatchSize:    50,
+	MaxAttempts:  5,
+	BaseBackoff:  30 * time.Second,
+}
+
+// Worker mengirim notifikasi dari antrian secara asinkron dengan retry
+type Worker struct {
+	db        *gorm.DB
+	notifiers map[string]Notifier
+	config    WorkerConfig
+}
+
+// Constructor untuk Worker. notifiers dipetakan berdasarkan kanal (model.NotificationChannel*).
+func NewWorker(db *gorm.DB, notifiers map[string]Notifier, config WorkerConfig) *Worker {
+	if config.PollInterval <= 0 {
+		config.PollInterval = DefaultWorkerConfig.PollInterval
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultWorkerConfig.BatchSize
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = DefaultWorkerConfig.MaxAttempts
+	}
+	if config.BaseBackoff <= 0 {
+		config.BaseBackoff = DefaultWorkerConfig.BaseBackoff
+	}
+	return &Worker{
+		db:        db,
+		notifiers: notifiers,
+		config:    config,
+	}
+}
+
+// Run memproses antrian sampai ctx dibatalkan
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.process(ctx); err != nil {
+			log.Println("notification: process queue failed:", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Worker) process(ctx context.Context) error {
+	var notifications []model.Notification
+
+	// Klaim notifikasi yang jatuh tempo supaya tidak dikirim dua kali oleh instance lain
+	err := w.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", model.NotificationPending, time.Now()).
+			Order("next_attempt_at").
+			Limit(w.config.BatchSize).
+			Find(&notifications).Error; err != nil {
+			return err
+		}
+
+		lease := time.Now().Add(time.Minute)
+		for _, n := range notifications {
+			if err := tx.Model(&model.Notification{}).
+				Where("notification_id = ?", n.NotificationID).
+				Update("next_attempt_at", lease).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, n := range notifications {
+		if ctx.Err() != nil {
+			return nil
+		}
+		w.send(ctx, n)
+	}
+	return nil
+}
+
+func (w *Worker) send(ctx context.Context, n model.Notification) {
+	updates := map[string]interface{}{
+		"attempts":   n.Attempts + 1,
+		"last_error": "",
+	}
+
+	var sendErr error
+	notifier, ok := w.notifiers[n.Channel]
+	if ok {
+		sendErr = notifier.Send(ctx, Message{
+			Recipient: n.Recipient,
+			Subject:   n.Subject,
+			Body:      n.Body,
+		})
+	} else {
+		sendErr = errChannelNotConfigured
+	}
+
+	switch {
+	case sendErr == nil:
+		updates["status"] = model.NotificationSent
+		updates["sent_at"] = time.Now()
+	case n.Attempts+1 >= w.config.MaxAttempts:
+		updates["status"] = model.NotificationFailed
+		updates["last_error"] = sendErr.Error()
+	default:
+		// Backoff eksponensial: base * 2^attempts
+		updates["next_attempt_at"] = time.Now().Add(w.config.BaseBackoff << n.Attempts)
+		updates["last_error"] = sendErr.Error()
+	}
+
+	if err := w.db.Model(&model.Notification{}).
+		Where("notification_id = ?", n.NotificationID).
+		Updates(updates).Error; err != nil {
+		log.Printf("notification: update %d failed: %v", n.NotificationID, err)
+	}
+}
+
+// IsLargeDebit mengecek apakah nominal debit perlu dinotifikasi (LARGE_DEBIT_THRESHOLD, default 1.000.000)
+func IsLargeDebit(amount int64) bool {
+	threshold, err := strconv.ParseInt(os.Getenv("LARGE_DEBIT_THRESHOLD"), 10, 64)
+	if err != nil || threshold <= 0 {
+		threshold = 1000000
+	}
+	return amount >= threshold
+}
@@ -0,0 +1,15 @@
+package notification
+
+import "testing"
+
+func TestNewWorkerDefaults(t *testing.T) {
+	w := NewWorker(nil, nil, WorkerConfig{})
+	if w.config != DefaultWorkerConfig {
+		t.Errorf("config = %+v, want DefaultWorkerConfig", w.config)
+	}
+
+	w = NewWorker(nil, nil, WorkerConfig{BatchSize: 10})
+	if w.config.BatchSize != 10 || w.config.PollInterval != DefaultWorkerConfig.PollInterval {
+		t.Errorf("config = %+v, want BatchSize 10 and default PollInterval", w.config)
+	}
+}
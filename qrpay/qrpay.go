@@ -0,0 +1,340 @@
+package qrpay
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"task-golang-db/currency"
+	"unicode/utf8"
+)
+
+var (
+	ErrInvalidPayload = errors.New("invalid QR payload")
+	ErrInvalidCRC     = errors.New("QR payload checksum does not match")
+	ErrNotSupported   = errors.New("QR payload is not a payment to an account of this bank")
+	ErrValueTooLong   = errors.New("QR field value is longer than 99 characters")
+)
+
+// Tag EMVCo merchant-presented mode (MPM) yang dipakai
+const (
+	TagFormat          = "00"
+	TagInitiation      = "01"
+	TagMerchantAccount = "26"
+	TagCategory        = "52"
+	TagCurrency        = "53"
+	TagAmount          = "54"
+	TagCountry         = "58"
+	TagName            = "59"
+	TagCity            = "60"
+	TagCRC             = "63"
+)
+
+// Point of initiation: static dipakai berulang tanpa nominal, dynamic untuk satu nominal tertentu
+const (
+	InitiationStatic  = "11"
+	InitiationDynamic = "12"
+)
+
+// Sub-tag di dalam merchant account information (tag 26)
+const (
+	subTagGUI           = "00"
+	subTagAccountNumber = "01"
+)
+
+// categoryTransfer adalah merchant category code untuk transfer antar nasabah
+const categoryTransfer = "4829"
+
+// Batas panjang tag 59 dan 60 menurut EMVCo
+const (
+	maxNameLength = 25
+	maxCityLength = 15
+)
+
+// Field adalah satu elemen TLV: tag dua digit dan nilainya. Panjang dihitung dalam
+// karakter, bukan byte.
+type Field struct {
+	Tag   string
+	Value string
+}
+
+// Config mengatur identitas bank di payload QR
+type Config struct {
+	// GUI adalah globally unique identifier bank (reverse domain) di tag 26 sub-tag 00
+	GUI     string
+	Country string
+	City    string
+}
+
+// ConfigFromEnv membaca QR_MERCHANT_GUI (default "ID.CO.DIGIRIZKYDHARMA"),
+// QR_COUNTRY (default "ID"), dan QR_CITY (default "JAKARTA")
+func ConfigFromEnv() Config {
+	config := Config{
+		GUI:     os.Getenv("QR_MERCHANT_GUI"),
+		Country: os.Getenv("QR_COUNTRY"),
+		City:    os.Getenv("QR_CITY"),
+	}
+	if config.GUI == "" {
+		config.GUI = "ID.CO.DIGIRIZKYDHARMA"
+	}
+	if config.Country == "" {
+		config.Country = "ID"
+	}
+	if config.City == "" {
+		config.City = "JAKARTA"
+	}
+	return config
+}
+
+// Payment adalah isi payload QR untuk menerima uang. Amount dalam satuan terkecil
+// Currency; 0 berarti QR static dan pembayar mengisi nominal sendiri.
+type Payment struct {
+	AccountNumber string `json:"account_number"`
+	Name          string `json:"name"`
+	City          string `json:"city"`
+	Currency      string `json:"currency"`
+	Amount        int64  `json:"amount"`
+}
+
+// Dynamic bernilai true jika QR berisi nominal tetap
+func (p Payment) Dynamic() bool {
+	return p.Amount > 0
+}
+
+// CRC16 menghitung CRC-16/CCITT-FALSE (poly 0x1021, init 0xFFFF) seperti yang
+// diwajibkan EMVCo untuk tag 63
+func CRC16(data []byte) uint16 {
+	crc := uint16(0xFFFF)
+	for _, b := range data {
+		crc ^= uint16(b) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// EncodeTLV menyusun fields menjadi string TLV tanpa CRC
+func EncodeTLV(fields []Field) (string, error) {
+	var sb strings.Builder
+	for _, field := range fields {
+		length := utf8.RuneCountInString(field.Value)
+		if length > 99 {
+			return "", fmt.Errorf("tag %s: %w", field.Tag, ErrValueTooLong)
+		}
+		if len(field.Tag) != 2 || !digitsOnly(field.Tag) {
+			return "", ErrInvalidPayload
+		}
+		sb.WriteString(field.Tag)
+		sb.WriteString(fmt.Sprintf("%02d", length))
+		sb.WriteString(field.Value)
+	}
+	return sb.String(), nil
+}
+
+// Encode menyusun payload lengkap dan menutupnya dengan tag 63 (CRC dihitung atas
+// seluruh payload termasuk "6304")
+func Encode(fields []Field) (string, error) {
+	data, err := EncodeTLV(fields)
+	if err != nil {
+		return "", err
+	}
+	data += TagCRC + "04"
+	return data + fmt.Sprintf("%04X", CRC16([]byte(data))), nil
+}
+
+// DecodeTLV memecah string TLV menjadi fields sesuai urutan di payload
+func DecodeTLV(data string) ([]Field, error) {
+	runes := []rune(data)
+	var fields []Field
+	for i := 0; i < len(runes); {
+		if i+4 > len(runes) {
+			return nil, ErrInvalidPayload
+		}
+		tag := string(runes[i : i+2])
+		lengthText := string(runes[i+2 : i+4])
+		length, err := strconv.Atoi(lengthText)
+		if err != nil || !digitsOnly(tag) || !digitsOnly(lengthText) || i+4+length > len(runes) {
+			return nil, ErrInvalidPayload
+		}
+		fields = append(fields, Field{Tag: tag, Value: string(runes[i+4 : i+4+length])})
+		i += 4 + length
+	}
+	return fields, nil
+}
+
+// Decode memvalidasi CRC lalu memecah payload; tag 63 tidak ikut dikembalikan
+func Decode(payload string) ([]Field, error) {
+	payload = strings.TrimSpace(payload)
+	if len(payload) < 8 || payload[len(payload)-8:len(payload)-4] != TagCRC+"04" {
+		return nil, ErrInvalidPayload
+	}
+	data, checksum := payload[:len(payload)-4], payload[len(payload)-4:]
+	expected, err := strconv.ParseUint(checksum, 16, 16)
+	if err != nil {
+		return nil, ErrInvalidPayload
+	}
+	if uint16(expected) != CRC16([]byte(data)) {
+		return nil, ErrInvalidCRC
+	}
+
+	fields, err := DecodeTLV(data[:len(data)-4])
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 || fields[0].Tag != TagFormat || fields[0].Value != "01" {
+		return nil, ErrInvalidPayload
+	}
+	return fields, nil
+}
+
+// Build membuat payload QR untuk menerima uang ke nomor rekening payment.AccountNumber
+func Build(config Config, payment Payment) (string, error) {
+	numeric := currency.Numeric(payment.Currency)
+	if numeric == "" || payment.Amount < 0 {
+		return "", ErrInvalidPayload
+	}
+	account, err := EncodeTLV([]Field{
+		{subTagGUI, config.GUI},
+		{subTagAccountNumber, payment.AccountNumber},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	initiation := InitiationStatic
+	if payment.Dynamic() {
+		initiation = InitiationDynamic
+	}
+	city := payment.City
+	if city == "" {
+		city = config.City
+	}
+	fields := []Field{
+		{TagFormat, "01"},
+		{TagInitiation, initiation},
+		{TagMerchantAccount, account},
+		{TagCategory, categoryTransfer},
+		{TagCurrency, numeric},
+	}
+	if payment.Dynamic() {
+		fields = append(fields, Field{TagAmount, formatAmount(payment.Currency, payment.Amount)})
+	}
+	fields = append(fields,
+		Field{TagCountry, config.Country},
+		Field{TagName, truncate(strings.ToUpper(payment.Name), maxNameLength)},
+		Field{TagCity, truncate(strings.ToUpper(city), maxCityLength)},
+	)
+	return Encode(fields)
+}
+
+// Parse membaca payload hasil scan. Payload harus lolos CRC dan berisi merchant account
+// information dengan GUI bank ini (tag 26-51); QR bank lain ditolak ErrNotSupported.
+func Parse(config Config, payload string) (Payment, error) {
+	fields, err := Decode(payload)
+	if err != nil {
+		return Payment{}, err
+	}
+
+	var payment Payment
+	var amount, initiation string
+	for _, field := range fields {
+		switch {
+		case field.Tag == TagInitiation:
+			initiation = field.Value
+		case field.Tag >= "26" && field.Tag <= "51" && payment.AccountNumber == "":
+			sub, err := DecodeTLV(field.Value)
+			if err != nil {
+				return Payment{}, err
+			}
+			if value(sub, subTagGUI) == config.GUI {
+				payment.AccountNumber = value(sub, subTagAccountNumber)
+			}
+		case field.Tag == TagCurrency:
+			payment.Currency = currency.FromNumeric(field.Value)
+			if payment.Currency == "" {
+				return Payment{}, ErrNotSupported
+			}
+		case field.Tag == TagAmount:
+			amount = field.Value
+		case field.Tag == TagName:
+			payment.Name = field.Value
+		case field.Tag == TagCity:
+			payment.City = field.Value
+		}
+	}
+	if payment.AccountNumber == "" {
+		return Payment{}, ErrNotSupported
+	}
+	if payment.Currency == "" || (initiation != InitiationStatic && initiation != InitiationDynamic) {
+		return Payment{}, ErrInvalidPayload
+	}
+	if amount != "" {
+		if payment.Amount, err = parseAmount(payment.Currency, amount); err != nil {
+			return Payment{}, err
+		}
+	}
+	if initiation == InitiationDynamic && payment.Amount == 0 {
+		return Payment{}, ErrInvalidPayload
+	}
+	return payment, nil
+}
+
+func value(fields []Field, tag string) string {
+	for _, field := range fields {
+		if field.Tag == tag {
+			return field.Value
+		}
+	}
+	return ""
+}
+
+// formatAmount menulis nominal satuan terkecil sebagai angka desimal tag 54, contoh 1050 USD -> "10.50"
+func formatAmount(code string, amount int64) string {
+	units := currency.MinorUnits(code)
+	text := strconv.FormatInt(amount, 10)
+	if units == 0 {
+		return text
+	}
+	text = strings.Repeat("0", max(0, units+1-len(text))) + text
+	return text[:len(text)-units] + "." + text[len(text)-units:]
+}
+
+// parseAmount kebalikan formatAmount tanpa float supaya tidak ada pembulatan
+func parseAmount(code string, text string) (int64, error) {
+	units := currency.MinorUnits(code)
+	whole, fraction, _ := strings.Cut(text, ".")
+	if !digitsOnly(whole) || (fraction != "" && !digitsOnly(fraction)) || len(fraction) > units || len(text) > 13 {
+		return 0, ErrInvalidPayload
+	}
+	amount, err := strconv.ParseInt(whole+fraction+strings.Repeat("0", units-len(fraction)), 10, 64)
+	if err != nil || amount <= 0 {
+		return 0, ErrInvalidPayload
+	}
+	return amount, nil
+}
+
+func truncate(text string, length int) string {
+	runes := []rune(strings.TrimSpace(text))
+	if len(runes) > length {
+		runes = runes[:length]
+	}
+	return string(runes)
+}
+
+func digitsOnly(value string) bool {
+	if value == "" {
+		return false
+	}
+	for i := 0; i < len(value); i++ {
+		if value[i] < '0' || value[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
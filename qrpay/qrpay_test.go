@@ -0,0 +1,197 @@
+package qrpay
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+var testConfig = Config{GUI: "ID.CO.DIGIRIZKYDHARMA", Country: "ID", City: "JAKARTA"}
+
+func TestCRC16CheckValue(t *testing.T) {
+	// Check value standar CRC-16/CCITT-FALSE
+	if got := CRC16([]byte("123456789")); got != 0x29B1 {
+		t.Errorf("CRC16(123456789) = %04X, want 29B1", got)
+	}
+}
+
+// emvcoSample adalah contoh payload dari spesifikasi EMVCo QRCPS merchant-presented
+// mode, termasuk template bahasa alternatif (tag 64) dengan karakter non-ASCII
+const emvcoSample = "00020101021229300012D156000000000510A93FO3230Q31280012D15600000001030812345678" +
+	"520441115802CN5914BEST TRANSPORT6007BEIJING64200002ZH0104最佳运输0202北京" +
+	"540523.7253031565502016233030412340603***0708A60086670902ME91320016A011223344998877" +
+	"0708123456786304A13A"
+
+func TestDecodeEMVCoSample(t *testing.T) {
+	fields, err := Decode(emvcoSample)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		TagInitiation: InitiationDynamic,
+		TagCategory:   "4111",
+		TagCurrency:   "156",
+		TagAmount:     "23.72",
+		TagCountry:    "CN",
+		TagName:       "BEST TRANSPORT",
+		TagCity:       "BEIJING",
+		"64":          "0002ZH0104最佳运输0202北京",
+	}
+	for tag, text := range want {
+		if got := value(fields, tag); got != text {
+			t.Errorf("tag %s = %q, want %q", tag, got, text)
+		}
+	}
+
+	alternate, err := DecodeTLV(value(fields, "64"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := value(alternate, "01"); got != "最佳运输" {
+		t.Errorf("alternate name = %q", got)
+	}
+
+	// Bank lain: CRC valid tetapi tidak ada GUI bank ini
+	if _, err := Parse(testConfig, emvcoSample); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("Parse(sample) error = %v, want ErrNotSupported", err)
+	}
+}
+
+func TestBuildKnownPayload(t *testing.T) {
+	got, err := Build(Config{GUI: "ID.CO.X", Country: "ID", City: "JAKARTA"}, Payment{
+		AccountNumber: "881234567890",
+		Name:          "Budi Santoso",
+		Currency:      "IDR",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "00020101021126270007ID.CO.X0112881234567890520448295303360" +
+		"5802ID5912BUDI SANTOSO6007JAKARTA6304D46A"
+	if got != want {
+		t.Errorf("Build = %s\nwant    %s", got, want)
+	}
+}
+
+func TestBuildParseRoundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		payment Payment
+		amount  string
+	}{
+		{"static IDR", Payment{AccountNumber: "8812345678903", Name: "Budi Santoso", Currency: "IDR"}, ""},
+		{"dynamic IDR", Payment{AccountNumber: "8812345678903", Name: "Budi Santoso", Currency: "IDR", Amount: 50000}, "50000"},
+		{"static USD", Payment{AccountNumber: "8812345678903", Name: "Siti", Currency: "USD"}, ""},
+		{"dynamic USD", Payment{AccountNumber: "8812345678903", Name: "Siti", Currency: "USD", Amount: 1050}, "10.50"},
+		{"dynamic USD cents", Payment{AccountNumber: "8812345678903", Name: "Siti", Currency: "USD", Amount: 5}, "0.05"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			payload, err := Build(testConfig, tc.payment)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fields, err := Decode(payload)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := value(fields, TagAmount); got != tc.amount {
+				t.Errorf("tag 54 = %q, want %q", got, tc.amount)
+			}
+			initiation := InitiationStatic
+			if tc.payment.Amount > 0 {
+				initiation = InitiationDynamic
+			}
+			if got := value(fields, TagInitiation); got != initiation {
+				t.Errorf("tag 01 = %q, want %q", got, initiation)
+			}
+
+			got, err := Parse(testConfig, payload)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.AccountNumber != tc.payment.AccountNumber || got.Currency != tc.payment.Currency || got.Amount != tc.payment.Amount {
+				t.Errorf("Parse = %+v, want %+v", got, tc.payment)
+			}
+		})
+	}
+}
+
+func TestAmountFormat(t *testing.T) {
+	for _, tc := range []struct {
+		currency string
+		amount   int64
+		text     string
+	}{
+		{"IDR", 50000, "50000"},
+		{"IDR", 1, "1"},
+		{"USD", 1050, "10.50"},
+		{"USD", 5, "0.05"},
+		{"USD", 100, "1.00"},
+	} {
+		if got := formatAmount(tc.currency, tc.amount); got != tc.text {
+			t.Errorf("formatAmount(%s, %d) = %q, want %q", tc.currency, tc.amount, got, tc.text)
+		}
+		if got, err := parseAmount(tc.currency, tc.text); err != nil || got != tc.amount {
+			t.Errorf("parseAmount(%s, %q) = %d, %v, want %d", tc.currency, tc.text, got, err, tc.amount)
+		}
+	}
+
+	// Pecahan yang lebih pendek dari minor unit tetap diterima
+	if got, err := parseAmount("USD", "10.5"); err != nil || got != 1050 {
+		t.Errorf("parseAmount(USD, 10.5) = %d, %v, want 1050", got, err)
+	}
+
+	for _, tc := range []struct{ currency, text string }{
+		{"USD", "10.505"}, // digit pecahan melebihi minor unit
+		{"IDR", "10.5"},   // rupiah tanpa pecahan
+		{"USD", "0.00"},
+		{"USD", "-1.00"},
+		{"USD", "1,00"},
+		{"USD", ".50"},
+		{"IDR", "12345678901234"}, // lebih dari 13 karakter
+	} {
+		if _, err := parseAmount(tc.currency, tc.text); err == nil {
+			t.Errorf("parseAmount(%s, %q) succeeded, want error", tc.currency, tc.text)
+		}
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	payload, err := Build(testConfig, Payment{AccountNumber: "8812345678903", Name: "Budi", Currency: "IDR", Amount: 50000})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// CRC diubah
+	wrongCRC := payload[:len(payload)-4] + "0000"
+	if _, err := Parse(testConfig, wrongCRC); !errors.Is(err, ErrInvalidCRC) {
+		t.Errorf("wrong CRC error = %v, want ErrInvalidCRC", err)
+	}
+
+	// Nominal diubah tanpa menghitung ulang CRC
+	tampered := strings.Replace(payload, "540550000", "540590000", 1)
+	if tampered == payload {
+		t.Fatal("payload has no tag 54 to tamper with")
+	}
+	if _, err := Parse(testConfig, tampered); !errors.Is(err, ErrInvalidCRC) {
+		t.Errorf("tampered payload error = %v, want ErrInvalidCRC", err)
+	}
+
+	// QR bank lain dengan CRC yang benar
+	other := Config{GUI: "ID.CO.OTHERBANK", Country: "ID", City: "JAKARTA"}
+	otherPayload, err := Build(other, Payment{AccountNumber: "123456", Name: "Budi", Currency: "IDR"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Parse(testConfig, otherPayload); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("other bank error = %v, want ErrNotSupported", err)
+	}
+
+	// Tanpa tag 63 atau TLV terpotong
+	for _, bad := range []string{"", "000201", payload[:len(payload)-8]} {
+		if _, err := Parse(testConfig, bad); !errors.Is(err, ErrInvalidPayload) {
+			t.Errorf("Parse(%q) error = %v, want ErrInvalidPayload", bad, err)
+		}
+	}
+}
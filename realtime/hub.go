@@ -0,0 +1,214 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"task-golang-db/accountno"
+	"task-golang-db/model"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"gorm.io/gorm"
+)
+
+// Channel Postgres yang dipakai untuk fanout antar instance server
+const notifyChannel = "account_events"
+
+type notification struct {
+	AccountEventID int64 `json:"account_event_id"`
+	AccountID      int64 `json:"account_id"`
+}
+
+// Publish menyimpan event dan mengirim NOTIFY dalam tx yang sama.
+// Postgres baru meneruskan NOTIFY setelah commit, jadi subscriber tidak pernah
+// menerima event dari transaksi yang di-rollback.
+func Publish(tx *gorm.DB, accountID int64, eventType string, data interface{}) error {
+	// Mutasi dikirim dengan nomor rekening lawan transaksi, bukan account_id
+	if transaction, ok := data.(model.Transaction); ok {
+		if err := accountno.Fill(tx, &transaction); err != nil {
+			return err
+		}
+		data = transaction
+	}
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	event := model.AccountEvent{
+		AccountID: accountID,
+		EventType: eventType,
+		Payload:   payload,
+	}
+	if err := tx.Create(&event).Error; err != nil {
+		return err
+	}
+
+	message, err := json.Marshal(notification{
+		AccountEventID: event.AccountEventID,
+		AccountID:      accountID,
+	})
+	if err != nil {
+		return err
+	}
+	return tx.Exec("SELECT pg_notify(?, ?)", notifyChannel, string(message)).Error
+}
+
+// MaxBacklog adalah jumlah event maksimal yang di-replay saat resume
+const MaxBacklog = 500
+
+// EventResync dikirim ke client jika backlog lebih dari MaxBacklog. Client harus memuat
+// ulang saldo dan mutasi lewat REST, lalu stream berlanjut dari event terbaru.
+const EventResync = "resync"
+
+// Backlog adalah event yang perlu di-replay saat resume. Jika Reset true, Events kosong
+// dan LatestID adalah event terbaru akun; event sampai LatestID tidak dikirim lagi.
+type Backlog struct {
+	Events   []model.AccountEvent
+	Reset    bool
+	LatestID int64
+}
+
+// Since mengambil event milik akun setelah ID tertentu (untuk resume)
+func Since(db *gorm.DB, accountID, afterID int64) (Backlog, error) {
+	var backlog Backlog
+	err := db.Where("account_id = ? AND account_event_id > ?", accountID, afterID).
+		Order("account_event_id").
+		Limit(MaxBacklog + 1).
+		Find(&backlog.Events).Error
+	if err != nil || len(backlog.Events) <= MaxBacklog {
+		return backlog, err
+	}
+
+	backlog.Events = nil
+	backlog.Reset = true
+	backlog.LatestID, err = LatestID(db, accountID)
+	return backlog, err
+}
+
+// LatestID mengembalikan account_event_id terbaru milik akun, 0 jika belum ada event
+func LatestID(db *gorm.DB, accountID int64) (int64, error) {
+	var id int64
+	err := db.Model(&model.AccountEvent{}).
+		Where("account_id = ?", accountID).
+		Select("COALESCE(MAX(account_event_id), 0)").
+		Scan(&id).Error
+	return id, err
+}
+
+// Hub meneruskan event dari LISTEN/NOTIFY ke subscriber lokal (SSE / WebSocket)
+type Hub struct {
+	db          *gorm.DB
+	mu          sync.RWMutex
+	subscribers map[int64]map[chan model.AccountEvent]struct{}
+}
+
+// Constructor untuk Hub
+func NewHub(db *gorm.DB) *Hub {
+	return &Hub{
+		db:          db,
+		subscribers: map[int64]map[chan model.AccountEvent]struct{}{},
+	}
+}
+
+// Subscribe mendaftarkan channel untuk event sebuah akun. Panggil fungsi yang
+// dikembalikan untuk berhenti berlangganan. Channel ditutup hub jika subscriber terlalu
+// lambat; stream harus diakhiri supaya client reconnect dan replay dari last-event ID.
+func (h *Hub) Subscribe(accountID int64) (<-chan model.AccountEvent, func()) {
+	ch := make(chan model.AccountEvent, 32)
+
+	h.mu.Lock()
+	if h.subscribers[accountID] == nil {
+		h.subscribers[accountID] = map[chan model.AccountEvent]struct{}{}
+	}
+	h.subscribers[accountID][ch] = struct{}{}
+	h.mu.Unlock()
+
+	return ch, func() {
+		h.mu.Lock()
+		h.remove(accountID, ch)
+		h.mu.Unlock()
+	}
+}
+
+// remove melepas subscriber; pemanggil memegang h.mu
+func (h *Hub) remove(accountID int64, ch chan model.AccountEvent) {
+	delete(h.subscribers[accountID], ch)
+	if len(h.subscribers[accountID]) == 0 {
+		delete(h.subscribers, accountID)
+	}
+}
+
+func (h *Hub) hasSubscribers(accountID int64) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.subscribers[accountID]) > 0
+}
+
+func (h *Hub) broadcast(event model.AccountEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subscribers[event.AccountID] {
+		// Buffer subscriber penuh: event tidak boleh dilewati diam-diam karena event
+		// berikutnya memajukan last-event ID. Channel ditutup supaya stream berakhir dan
+		// client reconnect lalu replay dari event terakhir yang benar-benar diterima.
+		select {
+		case ch <- event:
+		default:
+			h.remove(event.AccountID, ch)
+			close(ch)
+		}
+	}
+}
+
+// Run menjalankan LISTEN pada Postgres sampai ctx dibatalkan, dengan reconnect otomatis
+func (h *Hub) Run(ctx context.Context, dsn string) {
+	for {
+		if err := h.listen(ctx, dsn); err != nil && ctx.Err() == nil {
+			log.Println("realtime: listen failed:", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(2 * time.Second):
+		}
+	}
+}
+
+func (h *Hub) listen(ctx context.Context, dsn string) error {
+	conn, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+notifyChannel); err != nil {
+		return err
+	}
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		var message notification
+		if err := json.Unmarshal([]byte(n.Payload), &message); err != nil {
+			log.Println("realtime: invalid notification:", err)
+			continue
+		}
+		if !h.hasSubscribers(message.AccountID) {
+			continue
+		}
+
+		var event model.AccountEvent
+		if err := h.db.First(&event, message.AccountEventID).Error; err != nil {
+			log.Printf("realtime: load event %d failed: %v", message.AccountEventID, err)
+			continue
+		}
+		h.broadcast(event)
+	}
+}
@@ -0,0 +1,41 @@
+package realtime
+
+import (
+	"task-golang-db/model"
+	"testing"
+)
+
+func TestBroadcastClosesSlowSubscriber(t *testing.T) {
+	h := NewHub(nil)
+	slow, unsubscribeSlow := h.Subscribe(1)
+	defer unsubscribeSlow()
+	fast, unsubscribeFast := h.Subscribe(1)
+	defer unsubscribeFast()
+
+	// Buffer channel berisi 32 event; event ke-33 tidak muat di subscriber yang lambat
+	for id := int64(1); id <= 33; id++ {
+		if id == 33 {
+			for len(fast) > 0 {
+				<-fast
+			}
+		}
+		h.broadcast(model.AccountEvent{AccountEventID: id, AccountID: 1})
+	}
+
+	received := 0
+	for range slow {
+		received++
+	}
+	if received != 32 {
+		t.Errorf("slow subscriber got %d events before close, want 32", received)
+	}
+	if event, ok := <-fast; !ok || event.AccountEventID != 33 {
+		t.Errorf("fast subscriber got %+v, %v, want event 33", event, ok)
+	}
+	if !h.hasSubscribers(1) {
+		t.Error("fast subscriber was removed")
+	}
+
+	// Unsubscribe setelah ditutup hub tidak boleh panic
+	unsubscribeSlow()
+}
@@ -0,0 +1,71 @@
+package reconcile
+
+import (
+	"fmt"
+	"strings"
+	"task-golang-db/model"
+
+	"gorm.io/gorm"
+)
+
+// Status adalah ringkasan run rekonsiliasi terakhir dan tiket koreksi yang masih open
+type Status struct {
+	LastRun          *model.ReconciliationRun `json:"last_run"`
+	LastCompletedRun *model.ReconciliationRun `json:"last_completed_run"`
+	OpenTickets      int64                    `json:"open_tickets"`
+}
+
+// CurrentStatus membaca status rekonsiliasi terkini
+func CurrentStatus(db *gorm.DB) (Status, error) {
+	var status Status
+	for _, target := range []struct {
+		run   **model.ReconciliationRun
+		query *gorm.DB
+	}{
+		{&status.LastRun, db.Order("started_at DESC")},
+		{&status.LastCompletedRun, db.Where("status = ?", model.ReconciliationCompleted).Order("started_at DESC")},
+	} {
+		var run model.ReconciliationRun
+		result := target.query.Limit(1).Find(&run)
+		if result.Error != nil {
+			return status, result.Error
+		}
+		if result.RowsAffected > 0 {
+			*target.run = &run
+		}
+	}
+	err := db.Model(&model.CorrectionTicket{}).Where("status = ?", model.TicketOpen).Count(&status.OpenTickets).Error
+	return status, err
+}
+
+// Metrics menulis status rekonsiliasi dalam format teks Prometheus
+func Metrics(db *gorm.DB) (string, error) {
+	status, err := CurrentStatus(db)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	gauge := func(name, help string, value float64) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s gauge\n%s %g\n", name, help, name, name, value)
+	}
+	if run := status.LastRun; run != nil {
+		success := 0.0
+		if run.Status == model.ReconciliationCompleted {
+			success = 1
+		}
+		gauge("reconciliation_last_run_timestamp_seconds", "Start time of the last reconciliation run.", float64(run.StartedAt.Unix()))
+		gauge("reconciliation_last_run_success", "Whether the last reconciliation run completed without errors.", success)
+		if run.FinishedAt != nil {
+			gauge("reconciliation_last_run_duration_seconds", "Duration of the last reconciliation run.", run.FinishedAt.Sub(run.StartedAt).Seconds())
+		}
+		gauge("reconciliation_accounts_checked", "Accounts checked by the last reconciliation run.", float64(run.AccountsChecked))
+		gauge("reconciliation_discrepancies", "Discrepancies found by the last reconciliation run.", float64(run.DiscrepancyCount))
+		gauge("reconciliation_drift_total", "Sum of absolute balance differences found by the last run, in minor units.", run.TotalDrift)
+	}
+	if run := status.LastCompletedRun; run != nil {
+		gauge("reconciliation_last_success_timestamp_seconds", "Start time of the last completed reconciliation run.", float64(run.StartedAt.Unix()))
+	}
+	gauge("reconciliation_open_tickets", "Correction tickets that are still open.", float64(status.OpenTickets))
+	return b.String(), nil
+}
@@ -0,0 +1,228 @@
+package reconcile
+
+import (
+	"database/sql"
+	"errors"
+	"math"
+	"os"
+	"task-golang-db/audit"
+	"task-golang-db/model"
+	"task-golang-db/statement"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrRunInProgress = errors.New("a reconciliation run is already in progress")
+
+// Asal pemicu run rekonsiliasi
+const (
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+	TriggerCommand  = "command"
+)
+
+// Kunci advisory lock untuk memulai run, dipegang sampai run tercatat "running"
+const runLockKey = 7290292
+
+// staleRunAfter menganggap run yang masih "running" selama ini sudah mati (mis. proses restart)
+const staleRunAfter = time.Hour
+
+// Options mengatur satu run rekonsiliasi
+type Options struct {
+	Trigger     string
+	OpenTickets bool
+	Actor       audit.Actor
+}
+
+// OpenTicketsFromEnv membaca RECONCILE_OPEN_TICKETS (true/false) untuk job terjadwal
+func OpenTicketsFromEnv() bool {
+	return os.Getenv("RECONCILE_OPEN_TICKETS") == "true"
+}
+
+// Check membandingkan saldo tersimpan satu akun dengan riwayatnya:
+//   - balance dengan jumlah seluruh mutasi di tabel transaction
+//   - held_balance dengan total hold yang masih active
+//   - pocket_balance dengan total saldo pocket yang masih active
+//
+// Semua dibaca dalam satu transaksi REPEATABLE READ supaya transfer yang berjalan
+// bersamaan tidak terlihat sebagai selisih.
+func Check(db *gorm.DB, accountID int64) ([]model.ReconciliationDiscrepancy, error) {
+	var discrepancies []model.ReconciliationDiscrepancy
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var account model.Account
+		if err := tx.First(&account, accountID).Error; err != nil {
+			return err
+		}
+
+		expected, count, err := statement.HistoryBalance(tx, accountID)
+		if err != nil {
+			return err
+		}
+		var held, pockets int64
+		if err := tx.Model(&model.Hold{}).
+			Where("account_id = ? AND status = ?", accountID, model.HoldActive).
+			Select("COALESCE(SUM(amount), 0)").
+			Scan(&held).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.Pocket{}).
+			Where("account_id = ? AND status = ?", accountID, model.PocketActive).
+			Select("COALESCE(SUM(balance), 0)").
+			Scan(&pockets).Error; err != nil {
+			return err
+		}
+
+		for _, field := range []struct {
+			name     string
+			stored   float64
+			expected int64
+		}{
+			{model.ReconcileFieldBalance, account.Balance, expected},
+			{model.ReconcileFieldHeldBalance, account.HeldBalance, held},
+			{model.ReconcileFieldPocketBalance, account.PocketBalance, pockets},
+		} {
+			difference := field.stored - float64(field.expected)
+			if math.Abs(difference) < 0.000001 {
+				continue
+			}
+			discrepancy := model.ReconciliationDiscrepancy{
+				AccountID:  accountID,
+				Field:      field.name,
+				Stored:     field.stored,
+				Expected:   field.expected,
+				Difference: difference,
+			}
+			if field.name == model.ReconcileFieldBalance {
+				discrepancy.MovementCount = count
+			}
+			discrepancies = append(discrepancies, discrepancy)
+		}
+		return nil
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	return discrepancies, err
+}
+
+// Run merekonsiliasi semua akun dan menyimpan hasilnya. Akun yang gagal dicek tidak
+// menghentikan run; error pertama dicatat di run.Error.
+func Run(db *gorm.DB, options Options) (model.ReconciliationRun, error) {
+	run := model.ReconciliationRun{
+		Trigger:   options.Trigger,
+		Status:    model.ReconciliationRunning,
+		StartedAt: time.Now(),
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// Tanpa lock, dua instance yang menjalankan job terjadwal bersamaan sama-sama lolos Count
+		var locked bool
+		if err := tx.Raw("SELECT pg_try_advisory_xact_lock(?)", runLockKey).Scan(&locked).Error; err != nil {
+			return err
+		}
+		if !locked {
+			return ErrRunInProgress
+		}
+
+		var running int64
+		if err := tx.Model(&model.ReconciliationRun{}).
+			Where("status = ? AND started_at > ?", model.ReconciliationRunning, time.Now().Add(-staleRunAfter)).
+			Count(&running).Error; err != nil {
+			return err
+		}
+		if running > 0 {
+			return ErrRunInProgress
+		}
+		return tx.Create(&run).Error
+	})
+	if err != nil {
+		return run, err
+	}
+
+	var accountIDs []int64
+	if err := db.Model(&model.Account{}).Order("account_id").Pluck("account_id", &accountIDs).Error; err != nil {
+		return finish(db, run, err)
+	}
+
+	var firstErr error
+	for _, accountID := range accountIDs {
+		discrepancies, err := Check(db, accountID)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		run.AccountsChecked++
+		for _, discrepancy := range discrepancies {
+			discrepancy.ReconciliationRunID = run.ReconciliationRunID
+			opened, err := record(db, discrepancy, options)
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				continue
+			}
+			run.DiscrepancyCount++
+			run.TotalDrift += math.Abs(discrepancy.Difference)
+			if opened {
+				run.TicketsOpened++
+			}
+		}
+	}
+	return finish(db, run, firstErr)
+}
+
+// record menyimpan selisih dan, jika diminta, menautkannya ke tiket koreksi. Tiket
+// open yang sudah ada untuk akun dan kolom yang sama diperbarui, bukan diduplikasi.
+func record(db *gorm.DB, discrepancy model.ReconciliationDiscrepancy, options Options) (bool, error) {
+	opened := false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if options.OpenTickets {
+			var ticket model.CorrectionTicket
+			result := tx.Where("account_id = ? AND field = ? AND status = ?", discrepancy.AccountID, discrepancy.Field, model.TicketOpen).
+				Limit(1).Find(&ticket)
+			if result.Error != nil {
+				return result.Error
+			}
+
+			ticket.Stored = discrepancy.Stored
+			ticket.Expected = discrepancy.Expected
+			ticket.Difference = discrepancy.Difference
+			ticket.ReconciliationRunID = discrepancy.ReconciliationRunID
+			if result.RowsAffected == 0 {
+				ticket.AccountID = discrepancy.AccountID
+				ticket.Field = discrepancy.Field
+				ticket.Status = model.TicketOpen
+				opened = true
+			}
+			if err := tx.Save(&ticket).Error; err != nil {
+				return err
+			}
+			if opened {
+				if err := audit.Record(tx, options.Actor, audit.Entry{
+					Action:   audit.ActionCreate,
+					Entity:   "correction_ticket",
+					EntityID: ticket.CorrectionTicketID,
+					After:    ticket,
+				}); err != nil {
+					return err
+				}
+			}
+			discrepancy.CorrectionTicketID = &ticket.CorrectionTicketID
+		}
+		return tx.Create(&discrepancy).Error
+	})
+	return opened, err
+}
+
+func finish(db *gorm.DB, run model.ReconciliationRun, runErr error) (model.ReconciliationRun, error) {
+	now := time.Now()
+	run.FinishedAt = &now
+	run.Status = model.ReconciliationCompleted
+	if runErr != nil {
+		run.Status = model.ReconciliationFailed
+		run.Error = runErr.Error()
+	}
+	if err := db.Save(&run).Error; err != nil {
+		return run, err
+	}
+	return run, runErr
+}
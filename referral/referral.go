@@ -0,0 +1,283 @@
+package referral
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"task-golang-db/audit"
+	"task-golang-db/currency"
+	"task-golang-db/ledger"
+	"task-golang-db/model"
+	"task-golang-db/notification"
+	"task-golang-db/realtime"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrCodeInvalid      = errors.New("referral code is invalid")
+	ErrReferrerInactive = errors.New("referrer account is not active")
+	ErrReferrerCapped   = errors.New("referrer has reached the referral limit")
+)
+
+// Alasan referral ditolak saat akan diberi bonus
+const (
+	RejectSharedDevice = "referrer and referee share a device"
+	RejectCurrency     = "referral bonus is only paid in " + currency.Default
+	RejectCap          = "referrer reached the reward cap"
+	RejectInactive     = "referrer account is not active"
+)
+
+// CategoryName adalah kategori transaksi untuk bonus referral
+const CategoryName = "Referral Bonus"
+
+// ActionReward adalah aksi audit untuk pembayaran bonus referral
+const ActionReward = "referral_reward"
+
+// Rule adalah aturan bonus referral. Nominal dalam satuan terkecil mata uang default.
+type Rule struct {
+	// MinTopUp adalah nominal top-up pertama minimal agar referral memenuhi syarat
+	MinTopUp      int64
+	ReferrerBonus int64
+	RefereeBonus  int64
+	// MaxPerReferrer membatasi jumlah referral yang dibayar per referrer
+	MaxPerReferrer int64
+}
+
+// RuleFromEnv membaca aturan dari REFERRAL_MIN_TOPUP, REFERRAL_REFERRER_BONUS,
+// REFERRAL_REFEREE_BONUS, dan REFERRAL_MAX_PER_REFERRER
+func RuleFromEnv() Rule {
+	return Rule{
+		MinTopUp:       envInt("REFERRAL_MIN_TOPUP", 100000),
+		ReferrerBonus:  envInt("REFERRAL_REFERRER_BONUS", 50000),
+		RefereeBonus:   envInt("REFERRAL_REFEREE_BONUS", 25000),
+		MaxPerReferrer: envInt("REFERRAL_MAX_PER_REFERRER", 10),
+	}
+}
+
+const codeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// GenerateCode membuat kode referral 8 karakter tanpa huruf/angka yang mirip (O/0, I/1)
+func GenerateCode() (string, error) {
+	code := make([]byte, 8)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(codeAlphabet))))
+		if err != nil {
+			return "", err
+		}
+		code[i] = codeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
+
+// Register menghubungkan akun baru dengan pemilik kode referral
+func Register(tx *gorm.DB, referee *model.Account, code string) (model.Referral, error) {
+	var referral model.Referral
+
+	var referrer model.Account
+	err := tx.Where("referral_code = ?", strings.ToUpper(strings.TrimSpace(code))).First(&referrer).Error
+	if err == gorm.ErrRecordNotFound {
+		return referral, ErrCodeInvalid
+	}
+	if err != nil {
+		return referral, err
+	}
+	if referrer.AccountID == referee.AccountID {
+		return referral, ErrCodeInvalid
+	}
+	if referrer.Status != model.AccountStatusActive {
+		return referral, ErrReferrerInactive
+	}
+
+	rule := RuleFromEnv()
+	var count int64
+	if err := tx.Model(&model.Referral{}).
+		Where("referrer_account_id = ? AND status = ?", referrer.AccountID, model.ReferralRewarded).
+		Count(&count).Error; err != nil {
+		return referral, err
+	}
+	if rule.MaxPerReferrer > 0 && count >= rule.MaxPerReferrer {
+		return referral, ErrReferrerCapped
+	}
+
+	referee.ReferralAccountID = &referrer.AccountID
+	if err := tx.Model(referee).Update("referral_account_id", referrer.AccountID).Error; err != nil {
+		return referral, err
+	}
+
+	referral = model.Referral{
+		ReferrerAccountID: referrer.AccountID,
+		RefereeAccountID:  referee.AccountID,
+		Status:            model.ReferralPending,
+	}
+	return referral, tx.Create(&referral).Error
+}
+
+// OnTopUp dipanggil di dalam tx top-up. Top-up pertama yang mencapai MinTopUp
+// membuat referral yang masih pending dibayar (atau ditolak oleh fraud guard).
+func OnTopUp(tx *gorm.DB, referee *model.Account, amount int64, actor audit.Actor) error {
+	rule := RuleFromEnv()
+	if amount < rule.MinTopUp {
+		return nil
+	}
+
+	var referral model.Referral
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("referee_account_id = ? AND status = ?", referee.AccountID, model.ReferralPending).
+		First(&referral).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return reward(tx, rule, referral, referee, actor)
+}
+
+func reward(tx *gorm.DB, rule Rule, referral model.Referral, referee *model.Account, actor audit.Actor) error {
+	before := referral
+	now := time.Now()
+	referral.QualifiedAt = &now
+
+	// Lock referrer supaya cap per referrer tidak terlewati oleh top-up paralel
+	var referrer model.Account
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&referrer, referral.ReferrerAccountID).Error; err != nil {
+		return err
+	}
+
+	reason, err := rejectReason(tx, rule, referrer, *referee)
+	if err != nil {
+		return err
+	}
+	if reason != "" {
+		referral.Status = model.ReferralRejected
+		referral.RejectReason = reason
+	} else {
+		categoryID, err := ledger.CategoryID(tx, CategoryName)
+		if err != nil {
+			return err
+		}
+		if err := credit(tx, &referrer, rule.ReferrerBonus, categoryID); err != nil {
+			return err
+		}
+		if err := credit(tx, referee, rule.RefereeBonus, categoryID); err != nil {
+			return err
+		}
+		referral.Status = model.ReferralRewarded
+		referral.ReferrerBonus = rule.ReferrerBonus
+		referral.RefereeBonus = rule.RefereeBonus
+	}
+
+	if err := tx.Save(&referral).Error; err != nil {
+		return err
+	}
+	if err := audit.Record(tx, actor, audit.Entry{
+		Action:   ActionReward,
+		Entity:   "referral",
+		EntityID: referral.ReferralID,
+		Before:   before,
+		After:    referral,
+	}); err != nil {
+		return err
+	}
+	if referral.Status != model.ReferralRewarded {
+		return nil
+	}
+
+	bonuses := []struct {
+		accountID int64
+		amount    int64
+	}{
+		{referrer.AccountID, rule.ReferrerBonus},
+		{referee.AccountID, rule.RefereeBonus},
+	}
+	for _, bonus := range bonuses {
+		if bonus.amount <= 0 {
+			continue
+		}
+		if err := notification.Notify(tx, bonus.accountID, notification.EventReferralRewarded, map[string]interface{}{
+			"amount":                 bonus.amount,
+			"referee_account_number": referee.AccountNumber,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rejectReason menjalankan fraud guard sebelum bonus dibayar
+func rejectReason(tx *gorm.DB, rule Rule, referrer, referee model.Account) (string, error) {
+	if !referrer.CanMoveMoney() || referrer.Status != model.AccountStatusActive {
+		return RejectInactive, nil
+	}
+	if referrer.Currency != currency.Default || referee.Currency != currency.Default {
+		return RejectCurrency, nil
+	}
+
+	var rewarded int64
+	if err := tx.Model(&model.Referral{}).
+		Where("referrer_account_id = ? AND status = ?", referrer.AccountID, model.ReferralRewarded).
+		Count(&rewarded).Error; err != nil {
+		return "", err
+	}
+	if rule.MaxPerReferrer > 0 && rewarded >= rule.MaxPerReferrer {
+		return RejectCap, nil
+	}
+
+	// Referral ke diri sendiri lewat akun kedua biasanya login dari perangkat yang sama
+	var shared int64
+	if err := tx.Model(&model.KnownDevice{}).
+		Where("account_id = ? AND fingerprint IN (?)", referee.AccountID,
+			tx.Model(&model.KnownDevice{}).Select("fingerprint").Where("account_id = ?", referrer.AccountID)).
+		Count(&shared).Error; err != nil {
+		return "", err
+	}
+	if shared > 0 {
+		return RejectSharedDevice, nil
+	}
+	return "", nil
+}
+
+// credit menambah saldo akun dan mencatatnya sebagai transaksi berkategori
+func credit(tx *gorm.DB, account *model.Account, amount int64, categoryID int64) error {
+	if amount <= 0 {
+		return nil
+	}
+
+	account.Balance += float64(amount)
+	if err := tx.Model(account).Update("balance", account.Balance).Error; err != nil {
+		return err
+	}
+
+	transaction := model.Transaction{
+		TransactionCategoryID: &categoryID,
+		AccountID:             account.AccountID,
+		ToAccountId:           account.AccountID,
+		ToAccountNumber:       account.AccountNumber,
+		Amount:                amount,
+		Currency:              account.Currency,
+		TransactionDate:       time.Now().Format("2006-01-02 15:04:05"),
+	}
+	if err := tx.Create(&transaction).Error; err != nil {
+		return err
+	}
+
+	if err := ledger.PublishBalance(tx, *account); err != nil {
+		return err
+	}
+	return realtime.Publish(tx, account.AccountID, model.AccountEventMutationCreated, transaction)
+}
+
+func envInt(key string, fallback int64) int64 {
+	value, err := strconv.ParseInt(os.Getenv(key), 10, 64)
+	if err != nil || value < 0 {
+		return fallback
+	}
+	return value
+}
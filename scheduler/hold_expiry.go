@@ -0,0 +1,56 @@
+package scheduler
+
+import (
+	"context"
+	"task-golang-db/audit"
+	"task-golang-db/ledger"
+	"task-golang-db/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// HoldExpirer melepas hold yang sudah lewat expires_at sehingga dana kembali
+// ke saldo available. Aman dijalankan di beberapa instance (SKIP LOCKED).
+type HoldExpirer struct {
+	db       *gorm.DB
+	interval time.Duration
+}
+
+// Constructor untuk HoldExpirer
+func NewHoldExpirer(db *gorm.DB, interval time.Duration) *HoldExpirer {
+	return &HoldExpirer{
+		db:       db,
+		interval: interval,
+	}
+}
+
+// Run melepas hold kedaluwarsa sampai ctx dibatalkan
+func (e *HoldExpirer) Run(ctx context.Context) {
+	drain(ctx, e.interval, "expire hold", e.expireOne)
+}
+
+// expireOne mengklaim dan melepas satu hold yang kedaluwarsa
+func (e *HoldExpirer) expireOne() (bool, error) {
+	processed := false
+
+	err := e.db.Transaction(func(tx *gorm.DB) error {
+		var hold model.Hold
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND expires_at <= ?", model.HoldActive, time.Now()).
+			Order("expires_at").
+			First(&hold).Error
+		if err == gorm.ErrRecordNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		processed = true
+
+		_, err = ledger.ReleaseHold(tx, hold, model.HoldExpired, audit.Actor{Username: "scheduler"})
+		return err
+	})
+	return processed, err
+}
@@ -0,0 +1,88 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"os"
+	"task-golang-db/interest"
+	"task-golang-db/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// InterestSchedule adalah jadwal default job bunga (setiap jam 00:30)
+const InterestSchedule = "30 0 * * *"
+
+// InterestScheduleFromEnv membaca jadwal dari INTEREST_CRON, default InterestSchedule
+func InterestScheduleFromEnv() (Cron, error) {
+	expr := os.Getenv("INTEREST_CRON")
+	if expr == "" {
+		expr = InterestSchedule
+	}
+	return ParseCron(expr)
+}
+
+// InterestJob mencatat bunga harian sampai kemarin untuk semua akun yang punya produk
+// bunga dan mengkreditkannya di akhir periode. Hari yang sudah tercatat dilewati
+// sehingga job aman diulang dan mengejar hari yang terlewat setelah downtime.
+type InterestJob struct {
+	db       *gorm.DB
+	config   interest.Config
+	schedule Cron
+}
+
+// Constructor untuk InterestJob
+func NewInterestJob(db *gorm.DB, config interest.Config, schedule Cron) *InterestJob {
+	return &InterestJob{
+		db:       db,
+		config:   config,
+		schedule: schedule,
+	}
+}
+
+// Run menjalankan job sekali saat start, lalu sesuai jadwal
+func (j *InterestJob) Run(ctx context.Context) {
+	for {
+		if err := j.accrue(ctx, time.Now()); err != nil {
+			log.Println("scheduler: interest accrual failed:", err)
+		}
+
+		next, ok := j.schedule.Next(time.Now())
+		if !ok {
+			return
+		}
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// accrue memproses setiap akun dalam transaksi sendiri supaya satu akun yang gagal
+// tidak menahan akun lain
+func (j *InterestJob) accrue(ctx context.Context, now time.Time) error {
+	yesterday := now.AddDate(0, 0, -1)
+
+	var accountIDs []int64
+	err := j.db.Model(&model.Account{}).
+		Where("interest_product_id IS NOT NULL AND status <> ?", model.AccountStatusClosed).
+		Order("account_id").
+		Pluck("account_id", &accountIDs).Error
+	if err != nil {
+		return err
+	}
+
+	for _, accountID := range accountIDs {
+		if ctx.Err() != nil {
+			return nil
+		}
+		if _, err := interest.Accrue(j.db, j.config, accountID, yesterday); err != nil {
+			log.Printf("scheduler: interest %d failed: %v", accountID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// drain memanggil step berulang sampai tidak ada pekerjaan, lalu menunggu interval
+// berikutnya. Dipakai job kecil yang memproses satu baris per transaksi.
+func drain(ctx context.Context, interval time.Duration, name string, step func() (bool, error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for ctx.Err() == nil {
+			processed, err := step()
+			if err != nil {
+				log.Printf("scheduler: %s failed: %v", name, err)
+				break
+			}
+			if !processed {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
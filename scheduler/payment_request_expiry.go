@@ -0,0 +1,88 @@
+package scheduler
+
+import (
+	"context"
+	"task-golang-db/accountno"
+	"task-golang-db/audit"
+	"task-golang-db/model"
+	"task-golang-db/notification"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PaymentRequestExpirer menandai payment request yang lewat expires_at sebagai expired
+// dan memberi tahu kedua pihak. Aman dijalankan di beberapa instance (SKIP LOCKED).
+type PaymentRequestExpirer struct {
+	db       *gorm.DB
+	interval time.Duration
+}
+
+// Constructor untuk PaymentRequestExpirer
+func NewPaymentRequestExpirer(db *gorm.DB, interval time.Duration) *PaymentRequestExpirer {
+	return &PaymentRequestExpirer{
+		db:       db,
+		interval: interval,
+	}
+}
+
+// Run meng-expire payment request sampai ctx dibatalkan
+func (e *PaymentRequestExpirer) Run(ctx context.Context) {
+	drain(ctx, e.interval, "expire payment request", e.expireOne)
+}
+
+func (e *PaymentRequestExpirer) expireOne() (bool, error) {
+	processed := false
+
+	err := e.db.Transaction(func(tx *gorm.DB) error {
+		var request model.PaymentRequest
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND expires_at <= ?", model.PaymentRequestPending, time.Now()).
+			Order("expires_at").
+			First(&request).Error
+		if err == gorm.ErrRecordNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		processed = true
+		if err := accountno.Fill(tx, &request); err != nil {
+			return err
+		}
+
+		before := request
+		now := time.Now()
+		request.Status = model.PaymentRequestExpired
+		request.RespondedAt = &now
+		if err := tx.Model(&request).Updates(map[string]interface{}{
+			"status":       request.Status,
+			"responded_at": request.RespondedAt,
+		}).Error; err != nil {
+			return err
+		}
+		if err := audit.Record(tx, audit.Actor{Username: "scheduler"}, audit.Entry{
+			Action:   audit.ActionUpdate,
+			Entity:   "payment_request",
+			EntityID: request.PaymentRequestID,
+			Before:   before,
+			After:    request,
+		}); err != nil {
+			return err
+		}
+
+		for _, accountID := range []int64{request.RequesterAccountID, request.PayerAccountID} {
+			if err := notification.Notify(tx, accountID, notification.EventPaymentRequestExpired, map[string]interface{}{
+				"payment_request_id":       request.PaymentRequestID,
+				"amount":                   request.Amount,
+				"requester_account_number": request.RequesterAccountNumber,
+				"payer_account_number":     request.PayerAccountNumber,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return processed, err
+}
@@ -0,0 +1,72 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"os"
+	"task-golang-db/audit"
+	"task-golang-db/reconcile"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ReconcileSchedule adalah jadwal default job rekonsiliasi (setiap jam 02:00)
+const ReconcileSchedule = "0 2 * * *"
+
+// ReconcileScheduleFromEnv membaca jadwal dari RECONCILE_CRON, default ReconcileSchedule
+func ReconcileScheduleFromEnv() (Cron, error) {
+	expr := os.Getenv("RECONCILE_CRON")
+	if expr == "" {
+		expr = ReconcileSchedule
+	}
+	return ParseCron(expr)
+}
+
+// ReconcileJob mencocokkan saldo semua akun dengan riwayat mutasi sesuai jadwal.
+// Berbeda dengan job lain, job ini tidak jalan saat start supaya restart berulang
+// tidak memicu scan penuh berkali-kali.
+type ReconcileJob struct {
+	db          *gorm.DB
+	schedule    Cron
+	openTickets bool
+}
+
+// Constructor untuk ReconcileJob
+func NewReconcileJob(db *gorm.DB, schedule Cron, openTickets bool) *ReconcileJob {
+	return &ReconcileJob{
+		db:          db,
+		schedule:    schedule,
+		openTickets: openTickets,
+	}
+}
+
+// Run menjalankan rekonsiliasi sesuai jadwal
+func (j *ReconcileJob) Run(ctx context.Context) {
+	for {
+		next, ok := j.schedule.Next(time.Now())
+		if !ok {
+			return
+		}
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		run, err := reconcile.Run(j.db, reconcile.Options{
+			Trigger:     reconcile.TriggerSchedule,
+			OpenTickets: j.openTickets,
+			Actor:       audit.Actor{Username: "scheduler"},
+		})
+		if err != nil {
+			log.Println("scheduler: reconciliation failed:", err)
+			continue
+		}
+		if run.DiscrepancyCount > 0 {
+			log.Printf("scheduler: reconciliation run %d found %d discrepancies", run.ReconciliationRunID, run.DiscrepancyCount)
+		}
+	}
+}
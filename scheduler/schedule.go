@@ -0,0 +1,94 @@
+package scheduler
+
+import (
+	"errors"
+	"task-golang-db/model"
+	"time"
+)
+
+var errUnknownSchedule = errors.New("unknown schedule_type")
+
+// Validate memeriksa jadwal standing order sebelum disimpan
+func Validate(order model.StandingOrder) error {
+	switch order.ScheduleType {
+	case model.ScheduleOnce, model.ScheduleDaily, model.ScheduleWeekly, model.ScheduleMonthly:
+	case model.ScheduleCron:
+		if _, err := ParseCron(order.CronExpr); err != nil {
+			return err
+		}
+	default:
+		return errUnknownSchedule
+	}
+
+	if order.EndAt != nil && !order.EndAt.After(order.StartAt) {
+		return errors.New("end_at must be after start_at")
+	}
+	if order.MaxCount != nil && *order.MaxCount <= 0 {
+		return errors.New("max_count must be greater than 0")
+	}
+	return nil
+}
+
+// FirstRun mengembalikan jadwal eksekusi pertama
+func FirstRun(order model.StandingOrder) (time.Time, error) {
+	if order.ScheduleType == model.ScheduleCron {
+		cron, err := ParseCron(order.CronExpr)
+		if err != nil {
+			return time.Time{}, err
+		}
+		next, ok := cron.Next(order.StartAt.Add(-time.Minute))
+		if !ok {
+			return time.Time{}, errors.New("cron expression never matches")
+		}
+		return next, nil
+	}
+	return order.StartAt, nil
+}
+
+// NextRun menghitung eksekusi setelah jadwal previous. ok bernilai false jika
+// standing order sudah selesai (sekali jalan, lewat end_at, atau mencapai max_count).
+func NextRun(order model.StandingOrder, previous time.Time) (time.Time, bool) {
+	if order.MaxCount != nil && order.ExecutedCount >= *order.MaxCount {
+		return time.Time{}, false
+	}
+
+	var next time.Time
+	switch order.ScheduleType {
+	case model.ScheduleDaily:
+		next = previous.AddDate(0, 0, 1)
+	case model.ScheduleWeekly:
+		next = previous.AddDate(0, 0, 7)
+	case model.ScheduleMonthly:
+		next = addMonthClamped(order.StartAt, previous)
+	case model.ScheduleCron:
+		cron, err := ParseCron(order.CronExpr)
+		if err != nil {
+			return time.Time{}, false
+		}
+		var ok bool
+		if next, ok = cron.Next(previous); !ok {
+			return time.Time{}, false
+		}
+	default:
+		return time.Time{}, false
+	}
+
+	if order.EndAt != nil && next.After(*order.EndAt) {
+		return time.Time{}, false
+	}
+	return next, true
+}
+
+// addMonthClamped maju satu bulan dari previous dengan tanggal mengikuti start.
+// Tanggal 31 menjadi tanggal terakhir bulan yang lebih pendek (misal 28/29 Februari).
+func addMonthClamped(start, previous time.Time) time.Time {
+	year, month, _ := previous.Date()
+	firstOfNext := time.Date(year, month+1, 1, start.Hour(), start.Minute(), start.Second(), 0, start.Location())
+	lastDay := firstOfNext.AddDate(0, 1, -1).Day()
+
+	day := start.Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return time.Date(firstOfNext.Year(), firstOfNext.Month(), day, start.Hour(), start.Minute(), start.Second(), 0, start.Location())
+}
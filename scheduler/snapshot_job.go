@@ -0,0 +1,86 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"os"
+	"task-golang-db/model"
+	"task-golang-db/statement"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SnapshotSchedule adalah jadwal default job snapshot saldo (setiap jam 00:10)
+const SnapshotSchedule = "10 0 * * *"
+
+// SnapshotScheduleFromEnv membaca jadwal dari BALANCE_SNAPSHOT_CRON, default SnapshotSchedule
+func SnapshotScheduleFromEnv() (Cron, error) {
+	expr := os.Getenv("BALANCE_SNAPSHOT_CRON")
+	if expr == "" {
+		expr = SnapshotSchedule
+	}
+	return ParseCron(expr)
+}
+
+// SnapshotJob mencatat saldo penutupan harian sampai kemarin untuk semua akun.
+// Setiap akun dilanjutkan dari snapshot terakhirnya sehingga job aman diulang dan
+// mengejar hari yang terlewat setelah downtime.
+type SnapshotJob struct {
+	db       *gorm.DB
+	schedule Cron
+}
+
+// Constructor untuk SnapshotJob
+func NewSnapshotJob(db *gorm.DB, schedule Cron) *SnapshotJob {
+	return &SnapshotJob{
+		db:       db,
+		schedule: schedule,
+	}
+}
+
+// Run menjalankan job sekali saat start, lalu sesuai jadwal
+func (j *SnapshotJob) Run(ctx context.Context) {
+	for {
+		if err := j.snapshot(ctx, time.Now()); err != nil {
+			log.Println("scheduler: balance snapshot failed:", err)
+		}
+
+		next, ok := j.schedule.Next(time.Now())
+		if !ok {
+			return
+		}
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// snapshot memproses setiap akun dalam transaksi sendiri supaya satu akun yang gagal
+// tidak menahan akun lain. Akun tertutup tidak lagi dicatat karena saldonya tetap.
+func (j *SnapshotJob) snapshot(ctx context.Context, now time.Time) error {
+	yesterday := now.AddDate(0, 0, -1)
+
+	var accountIDs []int64
+	err := j.db.Model(&model.Account{}).
+		Where("status <> ?", model.AccountStatusClosed).
+		Order("account_id").
+		Pluck("account_id", &accountIDs).Error
+	if err != nil {
+		return err
+	}
+
+	for _, accountID := range accountIDs {
+		if ctx.Err() != nil {
+			return nil
+		}
+		if _, err := statement.Snapshot(j.db, accountID, yesterday); err != nil {
+			log.Printf("scheduler: balance snapshot %d failed: %v", accountID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"os"
+	"task-golang-db/model"
+	"task-golang-db/statement"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// StatementSchedule adalah jadwal default job rekening koran (setiap jam 01:00)
+const StatementSchedule = "0 1 * * *"
+
+// StatementScheduleFromEnv membaca jadwal dari STATEMENT_CRON, default StatementSchedule
+func StatementScheduleFromEnv() (Cron, error) {
+	expr := os.Getenv("STATEMENT_CRON")
+	if expr == "" {
+		expr = StatementSchedule
+	}
+	return ParseCron(expr)
+}
+
+// StatementJob membuat file rekening koran bulan sebelumnya untuk semua akun.
+// File yang sudah ada dilewati sehingga job aman diulang setelah restart.
+type StatementJob struct {
+	db       *gorm.DB
+	dir      string
+	schedule Cron
+}
+
+// Constructor untuk StatementJob
+func NewStatementJob(db *gorm.DB, dir string, schedule Cron) *StatementJob {
+	return &StatementJob{
+		db:       db,
+		dir:      dir,
+		schedule: schedule,
+	}
+}
+
+// Run menjalankan job sekali saat start (mengejar jadwal yang terlewat), lalu sesuai jadwal
+func (j *StatementJob) Run(ctx context.Context) {
+	for {
+		if err := j.generate(ctx, time.Now()); err != nil {
+			log.Println("scheduler: generate statements failed:", err)
+		}
+
+		next, ok := j.schedule.Next(time.Now())
+		if !ok {
+			return
+		}
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// generate membuat rekening koran bulan sebelum now untuk akun yang ada pada bulan tersebut
+func (j *StatementJob) generate(ctx context.Context, now time.Time) error {
+	month := time.Date(now.Year(), now.Month()-1, 1, 0, 0, 0, 0, time.Local)
+
+	var accountIDs []int64
+	err := j.db.Unscoped().Model(&model.Account{}).
+		Where("deleted_at IS NULL OR deleted_at >= ?", month).
+		Order("account_id").
+		Pluck("account_id", &accountIDs).Error
+	if err != nil {
+		return err
+	}
+
+	for _, accountID := range accountIDs {
+		if ctx.Err() != nil {
+			return nil
+		}
+		if j.exists(accountID, month) {
+			continue
+		}
+		if err := statement.Store(j.db, j.dir, accountID, month); err != nil {
+			log.Printf("scheduler: statement %d %s failed: %v", accountID, month.Format("2006-01"), err)
+		}
+	}
+
+	return nil
+}
+
+func (j *StatementJob) exists(accountID int64, month time.Time) bool {
+	for _, format := range []string{statement.FormatCSV, statement.FormatPDF} {
+		if _, err := os.Stat(statement.Path(j.dir, accountID, month.Format("2006-01"), format)); err != nil {
+			return false
+		}
+	}
+	return true
+}
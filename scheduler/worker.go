@@ -0,0 +1,186 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"task-golang-db/accountno"
+	"task-golang-db/audit"
+	"task-golang-db/ledger"
+	"task-golang-db/model"
+	"task-golang-db/notification"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Config struct {
+	PollInterval time.Duration
+	// MaxRetries adalah jumlah percobaan ulang jika saldo tidak cukup sebelum run dianggap gagal
+	MaxRetries int
+	RetryDelay time.Duration
+}
+
+// DefaultConfig adalah konfigurasi worker standing order; NewWorker memakainya untuk
+// setiap field Config yang nol.
+var DefaultConfig = Config{
+	PollInterval: 30 * time.Second,
+	MaxRetries:   3,
+	RetryDelay:   1 * time.Hour,
+}
+
+// Worker mengeksekusi standing order yang jatuh tempo. Aman dijalankan di
+// beberapa instance karena setiap order diklaim dengan FOR UPDATE SKIP LOCKED.
+type Worker struct {
+	db     *gorm.DB
+	config Config
+}
+
+// Constructor untuk Worker
+func NewWorker(db *gorm.DB, config Config) *Worker {
+	if config.PollInterval <= 0 {
+		config.PollInterval = DefaultConfig.PollInterval
+	}
+	if config.MaxRetries <= 0 {
+		config.MaxRetries = DefaultConfig.MaxRetries
+	}
+	if config.RetryDelay <= 0 {
+		config.RetryDelay = DefaultConfig.RetryDelay
+	}
+	return &Worker{
+		db:     db,
+		config: config,
+	}
+}
+
+// Run mengeksekusi standing order sampai ctx dibatalkan
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for ctx.Err() == nil {
+			processed, err := w.runOne()
+			if err != nil {
+				log.Println("scheduler: run standing order failed:", err)
+				break
+			}
+			if !processed {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runOne mengklaim dan mengeksekusi satu standing order yang jatuh tempo
+func (w *Worker) runOne() (bool, error) {
+	processed := false
+
+	err := w.db.Transaction(func(tx *gorm.DB) error {
+		var order model.StandingOrder
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_run_at <= ?", model.StandingOrderActive, time.Now()).
+			Order("next_run_at").
+			First(&order).Error
+		if err == gorm.ErrRecordNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		processed = true
+
+		scheduledFor := *order.NextRunAt
+		if order.ScheduledFor != nil {
+			scheduledFor = *order.ScheduledFor
+		}
+
+		// Transfer dijalankan dalam savepoint supaya kegagalan tetap bisa dicatat
+		if err := tx.SavePoint("standing_order").Error; err != nil {
+			return err
+		}
+		result, transferErr := ledger.Transfer(tx, ledger.TransferRequest{
+			FromAccountID: order.AccountID,
+			ToAccountID:   order.ToAccountID,
+			Amount:        order.Amount,
+			Actor:         audit.Actor{Username: "scheduler"},
+		})
+		if transferErr != nil {
+			// Jika rollback gagal, transfer setengah jalan tidak boleh ikut di-commit
+			if err := tx.RollbackTo("standing_order").Error; err != nil {
+				return err
+			}
+		}
+
+		execution := model.StandingOrderExecution{
+			StandingOrderID: order.StandingOrderID,
+			ScheduledFor:    scheduledFor,
+			Attempt:         order.RetryCount + 1,
+			Success:         transferErr == nil,
+		}
+		if transferErr == nil {
+			execution.TransactionID = &result.Transaction.TransactionID
+		} else {
+			execution.Error = transferErr.Error()
+		}
+		if err := tx.Create(&execution).Error; err != nil {
+			return err
+		}
+
+		return w.advance(tx, &order, scheduledFor, transferErr)
+	})
+	return processed, err
+}
+
+// advance menjadwalkan ulang standing order setelah satu percobaan
+func (w *Worker) advance(tx *gorm.DB, order *model.StandingOrder, scheduledFor time.Time, transferErr error) error {
+	updates := map[string]interface{}{}
+
+	// Saldo kurang dicoba ulang; error lain (akun ditutup, dll.) tidak akan berhasil dengan retry
+	retryable := errors.Is(transferErr, ledger.ErrInsufficientBalance)
+	if transferErr != nil && retryable && order.RetryCount < w.config.MaxRetries {
+		retryAt := time.Now().Add(w.config.RetryDelay)
+		updates["retry_count"] = order.RetryCount + 1
+		updates["scheduled_for"] = scheduledFor
+		updates["next_run_at"] = retryAt
+		updates["last_error"] = transferErr.Error()
+		return tx.Model(order).Updates(updates).Error
+	}
+
+	if transferErr != nil {
+		// Run ini gagal permanen: beri tahu pemilik dan lanjut ke jadwal berikutnya
+		updates["last_error"] = transferErr.Error()
+		if err := accountno.Fill(tx, order); err != nil {
+			return err
+		}
+		if err := notification.Notify(tx, order.AccountID, notification.EventStandingOrderFailed, map[string]interface{}{
+			"standing_order_id": order.StandingOrderID,
+			"amount":            order.Amount,
+			"to_account_number": order.ToAccountNumber,
+			"reason":            transferErr.Error(),
+		}); err != nil {
+			return err
+		}
+	} else {
+		order.ExecutedCount++
+		updates["executed_count"] = order.ExecutedCount
+		updates["last_error"] = ""
+	}
+
+	updates["retry_count"] = 0
+	updates["scheduled_for"] = nil
+	if next, ok := NextRun(*order, scheduledFor); ok {
+		updates["next_run_at"] = next
+	} else {
+		updates["next_run_at"] = nil
+		updates["status"] = model.StandingOrderCompleted
+	}
+	return tx.Model(order).Updates(updates).Error
+}
@@ -0,0 +1,55 @@
+package statement
+
+import (
+	"task-golang-db/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DailyBalances menghitung saldo akhir hari (termasuk dana pocket dan hold) untuk
+// setiap tanggal from..to, dengan kunci "2006-01-02". Sama seperti Generate, saldo
+// dihitung mundur dari saldo sekarang sehingga hasil untuk hari yang lewat stabil.
+func DailyBalances(db *gorm.DB, accountID int64, from, to time.Time) (map[string]int64, error) {
+	var account model.Account
+	if err := db.Unscoped().First(&account, accountID).Error; err != nil {
+		return nil, err
+	}
+
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.Local)
+	end := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.Local)
+
+	rows, err := movements(db, accountID, start)
+	if err != nil {
+		return nil, err
+	}
+
+	// Mulai dari saldo sekarang lalu kurangi mutasi yang terjadi setelah akhir hari
+	balance := int64(account.Balance)
+	i := len(rows) - 1
+	balances := map[string]int64{}
+	for day := end; !day.Before(start); day = day.AddDate(0, 0, -1) {
+		dayEnd := day.AddDate(0, 0, 1).Format("2006-01-02 15:04:05")
+		for ; i >= 0 && formatDate(rows[i].TransactionDate) >= dayEnd; i-- {
+			debit, credit, _ := Effect(rows[i].Transaction, rows[i].CreditAmount, accountID)
+			balance -= credit - debit
+		}
+		balances[day.Format("2006-01-02")] = balance
+	}
+	return balances, nil
+}
+
+// HistoryBalance menjumlahkan seluruh mutasi accountID sejak awal beserta jumlah
+// mutasinya. Untuk akun yang sehat hasilnya sama dengan accounts.balance.
+func HistoryBalance(db *gorm.DB, accountID int64) (int64, int, error) {
+	rows, err := movements(db, accountID, time.Time{})
+	if err != nil {
+		return 0, 0, err
+	}
+	var balance int64
+	for _, r := range rows {
+		debit, credit, _ := Effect(r.Transaction, r.CreditAmount, accountID)
+		balance += credit - debit
+	}
+	return balance, len(rows), nil
+}
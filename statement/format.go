@@ -0,0 +1,113 @@
+package statement
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Format output rekening koran
+const (
+	FormatCSV = "csv"
+	FormatPDF = "pdf"
+)
+
+var ErrUnknownFormat = errors.New("format must be csv or pdf")
+
+// ContentType mengembalikan MIME type untuk format
+func ContentType(format string) string {
+	if format == FormatPDF {
+		return "application/pdf"
+	}
+	return "text/csv"
+}
+
+// Write menulis rekening koran dalam format csv atau pdf
+func Write(w io.Writer, st Statement, format string) error {
+	switch format {
+	case FormatCSV:
+		return WriteCSV(w, st)
+	case FormatPDF:
+		return WritePDF(w, st)
+	}
+	return ErrUnknownFormat
+}
+
+// WriteCSV menulis satu baris per mutasi, diapit baris saldo awal, total, dan saldo akhir
+func WriteCSV(w io.Writer, st Statement) error {
+	cw := csv.NewWriter(w)
+	amount := func(v int64) string { return FormatAmount(st.Currency, v) }
+
+	records := [][]string{
+		{"transaction_id", "date", "category", "counterparty_account_number", "counterparty", "debit", "credit", "balance"},
+		{"", st.PeriodStart, "OPENING BALANCE", "", "", "", "", amount(st.OpeningBalance)},
+	}
+	for _, e := range st.Entries {
+		records = append(records, []string{
+			strconv.FormatInt(e.TransactionID, 10),
+			e.Date,
+			e.Category,
+			e.CounterpartyAccountNumber,
+			e.Counterparty,
+			amount(e.Debit),
+			amount(e.Credit),
+			amount(e.Balance),
+		})
+	}
+	records = append(records,
+		[]string{"", st.PeriodEnd, "TOTAL", "", "", amount(st.TotalDebit), amount(st.TotalCredit), ""},
+		[]string{"", st.PeriodEnd, "CLOSING BALANCE", "", "", "", "", amount(st.ClosingBalance)},
+	)
+
+	if err := cw.WriteAll(records); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+// lines menyusun isi PDF sebagai teks monospace
+func lines(st Statement) []string {
+	amount := func(v int64) string { return FormatAmount(st.Currency, v) }
+
+	result := []string{
+		"REKENING KORAN / ACCOUNT STATEMENT",
+		"",
+		fmt.Sprintf("Account  : %s - %s", st.AccountNumber, st.Name),
+		fmt.Sprintf("Currency : %s", st.Currency),
+		fmt.Sprintf("Period   : %s s/d %s", st.PeriodStart, st.PeriodEnd),
+		"",
+		fmt.Sprintf("%-19s %-16s %-24s %14s %14s %16s", "Date", "Category", "Counterparty", "Debit", "Credit", "Balance"),
+		fmt.Sprintf("%-19s %-16s %-24s %14s %14s %16s", "", "OPENING BALANCE", "", "", "", amount(st.OpeningBalance)),
+	}
+	for _, e := range st.Entries {
+		counterparty := e.Counterparty
+		if e.CounterpartyAccountID != 0 {
+			counterparty = e.CounterpartyAccountNumber + " " + e.Counterparty
+		}
+		debit, credit := "", ""
+		if e.Debit != 0 {
+			debit = amount(e.Debit)
+		}
+		if e.Credit != 0 {
+			credit = amount(e.Credit)
+		}
+		result = append(result, fmt.Sprintf("%-19s %-16s %-24s %14s %14s %16s",
+			e.Date, truncate(e.Category, 16), truncate(counterparty, 24), debit, credit, amount(e.Balance)))
+	}
+	result = append(result,
+		"",
+		fmt.Sprintf("%-61s %14s %14s", "TOTAL", amount(st.TotalDebit), amount(st.TotalCredit)),
+		fmt.Sprintf("%-61s %14s %14s %16s", "CLOSING BALANCE", "", "", amount(st.ClosingBalance)),
+	)
+	return result
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
@@ -0,0 +1,177 @@
+package statement
+
+import (
+	"database/sql"
+	"errors"
+	"task-golang-db/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Granularitas riwayat saldo
+const (
+	GranularityDaily   = "daily"
+	GranularityWeekly  = "weekly"
+	GranularityMonthly = "monthly"
+)
+
+var ErrInvalidGranularity = errors.New("granularity must be daily, weekly or monthly")
+
+// Point adalah saldo satu periode pada riwayat saldo. ClosingBalance adalah saldo
+// penutupan hari terakhir periode (Date); Min/MaxBalance dari saldo penutupan harian.
+type Point struct {
+	Period         string `json:"period"`
+	Date           string `json:"date"`
+	ClosingBalance int64  `json:"closing_balance"`
+	MinBalance     int64  `json:"min_balance"`
+	MaxBalance     int64  `json:"max_balance"`
+}
+
+// consistent menjalankan fn dalam transaksi REPEATABLE READ supaya saldo akun dan
+// daftar transaksi dibaca dari snapshot database yang sama
+func consistent(db *gorm.DB, fn func(tx *gorm.DB) error) error {
+	return db.Transaction(fn, &sql.TxOptions{Isolation: sql.LevelRepeatableRead})
+}
+
+// Snapshot melengkapi balance_snapshots akun sampai hari through. Hanya hari setelah
+// snapshot terakhir yang dihitung; hari yang sudah lewat tidak berubah lagi karena
+// transaksi selalu dicatat dengan waktu sekarang (koreksi dibuat sebagai transaksi baru).
+func Snapshot(db *gorm.DB, accountID int64, through time.Time) (int, error) {
+	end := time.Date(through.Year(), through.Month(), through.Day(), 0, 0, 0, 0, time.Local)
+	created := 0
+	err := consistent(db, func(tx *gorm.DB) error {
+		start, err := snapshotStart(tx, accountID, end)
+		if err != nil {
+			return err
+		}
+		if start.After(end) {
+			return nil
+		}
+
+		balances, err := DailyBalances(tx, accountID, start, end)
+		if err != nil {
+			return err
+		}
+		snapshots := make([]model.BalanceSnapshot, 0, len(balances))
+		for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+			snapshots = append(snapshots, model.BalanceSnapshot{
+				AccountID:      accountID,
+				SnapshotDate:   day,
+				ClosingBalance: balances[day.Format("2006-01-02")],
+			})
+		}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&snapshots, 500)
+		created = int(result.RowsAffected)
+		return result.Error
+	})
+	return created, err
+}
+
+// snapshotStart adalah hari setelah snapshot terakhir, atau hari transaksi pertama akun
+// jika belum ada snapshot sama sekali
+func snapshotStart(tx *gorm.DB, accountID int64, end time.Time) (time.Time, error) {
+	var last model.BalanceSnapshot
+	result := tx.Where("account_id = ?", accountID).Order("snapshot_date DESC").Limit(1).Find(&last)
+	if result.Error != nil {
+		return end, result.Error
+	}
+	if result.RowsAffected > 0 {
+		day := last.SnapshotDate
+		return time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.Local).AddDate(0, 0, 1), nil
+	}
+
+	var first sql.NullTime
+	err := tx.Table(`"transaction"`).
+		Where("account_id = ? OR from_account_id = ? OR to_account_id = ?", accountID, accountID, accountID).
+		Select("MIN(transaction_date)").
+		Scan(&first).Error
+	if err != nil || !first.Valid {
+		return end, err
+	}
+	day := first.Time
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.Local), nil
+}
+
+// AsOf menghitung saldo akun tepat pada waktu at (transaksi pada detik at ikut dihitung).
+// Saldo dihitung mundur dari saldo sekarang seperti DailyBalances.
+func AsOf(db *gorm.DB, accountID int64, at time.Time) (int64, error) {
+	var balance int64
+	err := consistent(db, func(tx *gorm.DB) error {
+		var account model.Account
+		if err := tx.Unscoped().First(&account, accountID).Error; err != nil {
+			return err
+		}
+		balance = int64(account.Balance)
+
+		rows, err := movements(tx, accountID, at.Truncate(time.Second).Add(time.Second))
+		if err != nil {
+			return err
+		}
+		for _, r := range rows {
+			debit, credit, _ := Effect(r.Transaction, r.CreditAmount, accountID)
+			balance -= credit - debit
+		}
+		return nil
+	})
+	return balance, err
+}
+
+// Series menyusun riwayat saldo from..to per granularity. Snapshot yang belum ada
+// dilengkapi dulu sampai kemarin; hari ini memakai saldo sekarang.
+func Series(db *gorm.DB, accountID int64, from, to time.Time, granularity string) ([]Point, error) {
+	var bucket func(time.Time) time.Time
+	switch granularity {
+	case GranularityDaily:
+		bucket = func(day time.Time) time.Time { return day }
+	case GranularityWeekly:
+		bucket = func(day time.Time) time.Time { return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7)) }
+	case GranularityMonthly:
+		bucket = func(day time.Time) time.Time {
+			return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, day.Location())
+		}
+	default:
+		return nil, ErrInvalidGranularity
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	through := to
+	if !through.Before(today) {
+		through = today.AddDate(0, 0, -1)
+	}
+	if _, err := Snapshot(db, accountID, through); err != nil {
+		return nil, err
+	}
+
+	var snapshots []model.BalanceSnapshot
+	err := db.Where("account_id = ? AND snapshot_date BETWEEN ? AND ?", accountID, from.Format("2006-01-02"), to.Format("2006-01-02")).
+		Order("snapshot_date").
+		Find(&snapshots).Error
+	if err != nil {
+		return nil, err
+	}
+	if !to.Before(today) && !from.After(today) {
+		var account model.Account
+		if err := db.Unscoped().First(&account, accountID).Error; err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, model.BalanceSnapshot{AccountID: accountID, SnapshotDate: today, ClosingBalance: int64(account.Balance)})
+	}
+
+	points := []Point{}
+	for _, snapshot := range snapshots {
+		day := time.Date(snapshot.SnapshotDate.Year(), snapshot.SnapshotDate.Month(), snapshot.SnapshotDate.Day(), 0, 0, 0, 0, time.Local)
+		period := bucket(day).Format("2006-01-02")
+		if len(points) == 0 || points[len(points)-1].Period != period {
+			points = append(points, Point{Period: period, MinBalance: snapshot.ClosingBalance, MaxBalance: snapshot.ClosingBalance})
+		}
+		point := &points[len(points)-1]
+		point.Date = day.Format("2006-01-02")
+		point.ClosingBalance = snapshot.ClosingBalance
+		point.MinBalance = min(point.MinBalance, snapshot.ClosingBalance)
+		point.MaxBalance = max(point.MaxBalance, snapshot.ClosingBalance)
+	}
+	return points, nil
+}
@@ -0,0 +1,279 @@
+package statement
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"task-golang-db/currency"
+	"task-golang-db/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrInvalidMonth = errors.New("month must be in YYYY-MM format and not in the future")
+
+// Entry adalah satu baris mutasi pada rekening koran
+type Entry struct {
+	TransactionID             int64  `json:"transaction_id"`
+	Date                      string `json:"date"`
+	Category                  string `json:"category"`
+	CounterpartyAccountID     int64  `json:"-"`
+	CounterpartyAccountNumber string `json:"counterparty_account_number"`
+	Counterparty              string `json:"counterparty"`
+	Debit                     int64  `json:"debit"`
+	Credit                    int64  `json:"credit"`
+	Balance                   int64  `json:"balance"`
+}
+
+// Statement adalah rekening koran satu akun untuk satu bulan. Nominal dalam satuan terkecil mata uang.
+type Statement struct {
+	AccountID      int64   `json:"-"`
+	AccountNumber  string  `json:"account_number"`
+	Name           string  `json:"name"`
+	Currency       string  `json:"currency"`
+	Month          string  `json:"month"`
+	PeriodStart    string  `json:"period_start"`
+	PeriodEnd      string  `json:"period_end"`
+	OpeningBalance int64   `json:"opening_balance"`
+	ClosingBalance int64   `json:"closing_balance"`
+	TotalDebit     int64   `json:"total_debit"`
+	TotalCredit    int64   `json:"total_credit"`
+	Entries        []Entry `json:"entries"`
+}
+
+// row adalah transaksi beserta kategori dan nominal kredit hasil konversi FX
+type row struct {
+	model.Transaction
+	CategoryName string
+	CreditAmount *int64
+}
+
+// ParseMonth membaca "YYYY-MM" menjadi awal bulan (waktu lokal). Bulan berjalan diperbolehkan.
+func ParseMonth(value string) (time.Time, error) {
+	month, err := time.ParseInLocation("2006-01", value, time.Local)
+	if err != nil || month.After(time.Now()) {
+		return time.Time{}, ErrInvalidMonth
+	}
+	return month, nil
+}
+
+// Generate menyusun rekening koran dari tabel transaction. Saldo awal dihitung mundur
+// dari saldo sekarang dikurangi semua mutasi sejak awal bulan, sehingga hasil untuk
+// bulan yang sudah lewat selalu sama selama mutasinya tidak berubah.
+func Generate(db *gorm.DB, accountID int64, month time.Time) (Statement, error) {
+	var st Statement
+
+	var account model.Account
+	if err := db.Unscoped().First(&account, accountID).Error; err != nil {
+		return st, err
+	}
+
+	start := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.Local)
+	end := start.AddDate(0, 1, 0)
+
+	rows, err := movements(db, accountID, start)
+	if err != nil {
+		return st, err
+	}
+
+	st = Statement{
+		AccountID:     account.AccountID,
+		AccountNumber: account.AccountNumber,
+		Name:          account.Name,
+		Currency:      account.Currency,
+		Month:         start.Format("2006-01"),
+		PeriodStart:   start.Format("2006-01-02"),
+		PeriodEnd:     end.AddDate(0, 0, -1).Format("2006-01-02"),
+		Entries:       []Entry{},
+	}
+
+	// Semua mutasi sejak awal bulan dipakai untuk menghitung saldo awal
+	var net int64
+	for _, r := range rows {
+		debit, credit, _ := Effect(r.Transaction, r.CreditAmount, accountID)
+		net += credit - debit
+	}
+	st.OpeningBalance = int64(account.Balance) - net
+
+	counterparties, err := counterpartyAccounts(db, rows, accountID)
+	if err != nil {
+		return st, err
+	}
+
+	balance := st.OpeningBalance
+	endDate := end.Format("2006-01-02 15:04:05")
+	for _, r := range rows {
+		if formatDate(r.TransactionDate) >= endDate {
+			break
+		}
+		debit, credit, counterparty := Effect(r.Transaction, r.CreditAmount, accountID)
+		if debit == 0 && credit == 0 {
+			continue
+		}
+		balance += credit - debit
+		st.TotalDebit += debit
+		st.TotalCredit += credit
+		st.Entries = append(st.Entries, Entry{
+			TransactionID:             r.TransactionID,
+			Date:                      formatDate(r.TransactionDate),
+			Category:                  r.CategoryName,
+			CounterpartyAccountID:     counterparty,
+			CounterpartyAccountNumber: counterparties[counterparty].AccountNumber,
+			Counterparty:              counterparties[counterparty].Name,
+			Debit:                     debit,
+			Credit:                    credit,
+			Balance:                   balance,
+		})
+	}
+	st.ClosingBalance = balance
+	return st, nil
+}
+
+// Effect menghitung debit/kredit sebuah transaksi untuk accountID. Transfer adalah
+// baris dengan from dan to berbeda; baris lain (mutasi manual, top-up, bonus)
+// menambah saldo account_id sebesar amount (negatif berarti debit).
+func Effect(t model.Transaction, creditAmount *int64, accountID int64) (debit, credit, counterparty int64) {
+	// Perpindahan ke/dari pocket tidak mengubah total saldo akun
+	if t.PocketID != nil {
+		return 0, 0, 0
+	}
+
+	isTransfer := t.FromAccountId != 0 && t.ToAccountId != 0 && t.FromAccountId != t.ToAccountId
+
+	switch {
+	case isTransfer && t.FromAccountId == accountID:
+		return t.Amount, 0, t.ToAccountId
+	case isTransfer && t.ToAccountId == accountID:
+		// Transfer lintas mata uang dikreditkan sebesar target_amount quote
+		if creditAmount != nil {
+			return 0, *creditAmount, t.FromAccountId
+		}
+		return 0, t.Amount, t.FromAccountId
+	case !isTransfer && t.AccountID == accountID:
+		if t.FromAccountId != accountID {
+			counterparty = t.FromAccountId
+		}
+		if t.Amount < 0 {
+			return -t.Amount, 0, counterparty
+		}
+		return 0, t.Amount, counterparty
+	}
+	return 0, 0, 0
+}
+
+// movements mengambil semua transaksi yang menyentuh accountID sejak since, urut waktu
+func movements(db *gorm.DB, accountID int64, since time.Time) ([]row, error) {
+	var rows []row
+	err := db.Table(`"transaction" t`).
+		Select("t.*, c.name AS category_name, q.target_amount AS credit_amount").
+		Joins("LEFT JOIN transaction_categories c ON c.transaction_category_id = t.transaction_category_id").
+		Joins("LEFT JOIN fx_quotes q ON q.fx_quote_id = t.fx_quote_id").
+		Where("(t.account_id = ? OR t.from_account_id = ? OR t.to_account_id = ?) AND t.transaction_date >= ?",
+			accountID, accountID, accountID, since.Format("2006-01-02 15:04:05")).
+		Order("t.transaction_date, t.transaction_id").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// counterpartyAccounts memuat nama dan nomor rekening lawan transaksi; id 0 (tanpa lawan) bernama "-"
+func counterpartyAccounts(db *gorm.DB, rows []row, accountID int64) (map[int64]model.Account, error) {
+	accounts := map[int64]model.Account{0: {Name: "-"}}
+
+	var ids []int64
+	for _, r := range rows {
+		for _, id := range []int64{r.FromAccountId, r.ToAccountId} {
+			if _, ok := accounts[id]; !ok && id != accountID {
+				accounts[id] = model.Account{}
+				ids = append(ids, id)
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return accounts, nil
+	}
+
+	var found []model.Account
+	if err := db.Unscoped().Select("account_id", "account_number", "name").Find(&found, ids).Error; err != nil {
+		return nil, err
+	}
+	for _, account := range found {
+		accounts[account.AccountID] = account
+	}
+	return accounts, nil
+}
+
+// formatDate merapikan transaction_date yang dibaca sebagai string dari kolom timestamp
+func formatDate(value string) string {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02T15:04:05"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.Format("2006-01-02 15:04:05")
+		}
+	}
+	return value
+}
+
+// FormatAmount menulis nominal satuan terkecil dalam satuan utama, contoh 1050 USD -> "10.50"
+func FormatAmount(code string, amount int64) string {
+	units := currency.MinorUnits(code)
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	if units == 0 {
+		return sign + strconv.FormatInt(amount, 10)
+	}
+	s := fmt.Sprintf("%0*d", units+1, amount)
+	return sign + s[:len(s)-units] + "." + s[len(s)-units:]
+}
+
+// Dir adalah folder penyimpanan rekening koran (STATEMENT_DIR, default storage/statements)
+func Dir() string {
+	if dir := os.Getenv("STATEMENT_DIR"); dir != "" {
+		return dir
+	}
+	return filepath.Join("storage", "statements")
+}
+
+// Path adalah lokasi file rekening koran, contoh storage/statements/12/2026-09.pdf
+func Path(dir string, accountID int64, month string, format string) string {
+	return filepath.Join(dir, strconv.FormatInt(accountID, 10), month+"."+strings.ToLower(format))
+}
+
+// Store membuat file CSV dan PDF rekening koran di dir
+func Store(db *gorm.DB, dir string, accountID int64, month time.Time) error {
+	st, err := Generate(db, accountID, month)
+	if err != nil {
+		return err
+	}
+
+	for _, format := range []string{FormatCSV, FormatPDF} {
+		path := Path(dir, accountID, st.Month, format)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+
+		// Tulis ke file sementara lalu rename supaya pembaca tidak melihat file setengah jadi
+		tmp, err := os.CreateTemp(filepath.Dir(path), ".statement-*")
+		if err != nil {
+			return err
+		}
+		if err := Write(tmp, st, format); err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+			return err
+		}
+		if err := tmp.Close(); err != nil {
+			os.Remove(tmp.Name())
+			return err
+		}
+		if err := os.Rename(tmp.Name(), path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,299 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"task-golang-db/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Header yang dikirim bersama setiap webhook
+const (
+	HeaderSignature  = "X-Webhook-Signature"
+	HeaderTimestamp  = "X-Webhook-Timestamp"
+	HeaderEventType  = "X-Webhook-Event"
+	HeaderDeliveryID = "X-Webhook-Delivery-ID"
+)
+
+type Config struct {
+	PollInterval time.Duration
+	BatchSize    int
+	MaxAttempts  int
+	BaseBackoff  time.Duration
+	MaxBackoff   time.Duration
+	Timeout      time.Duration
+}
+
+// DefaultConfig adalah konfigurasi dispatcher di production. Field Config yang
+// bernilai nol diisi dari sini oleh NewDispatcher.
+var DefaultConfig = Config{
+	PollInterval: 2 * time.Second,
+	BatchSize:    50,
+	MaxAttempts:  8,
+	BaseBackoff:  10 * time.Second,
+	MaxBackoff:   1 * time.Hour,
+	Timeout:      10 * time.Second,
+}
+
+type Dispatcher struct {
+	db     *gorm.DB
+	client *http.Client
+	config Config
+}
+
+// Constructor untuk Dispatcher
+func NewDispatcher(db *gorm.DB, config Config) *Dispatcher {
+	config = config.withDefaults()
+	return &Dispatcher{
+		db:     db,
+		client: &http.Client{Timeout: config.Timeout},
+		config: config,
+	}
+}
+
+// withDefaults mengisi field yang nol (atau negatif) dengan nilai DefaultConfig
+func (c Config) withDefaults() Config {
+	if c.PollInterval <= 0 {
+		c.PollInterval = DefaultConfig.PollInterval
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = DefaultConfig.BatchSize
+	}
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = DefaultConfig.MaxAttempts
+	}
+	if c.BaseBackoff <= 0 {
+		c.BaseBackoff = DefaultConfig.BaseBackoff
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = DefaultConfig.MaxBackoff
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = DefaultConfig.Timeout
+	}
+	return c
+}
+
+// Run memproses outbox dan mengirim webhook sampai ctx dibatalkan
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := d.fanOut(); err != nil {
+			log.Println("webhook: fan out outbox failed:", err)
+		}
+		if err := d.deliverDue(ctx); err != nil {
+			log.Println("webhook: deliver failed:", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// fanOut membuat satu delivery untuk setiap subscription yang cocok dengan event outbox
+func (d *Dispatcher) fanOut() error {
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		var events []model.OutboxEvent
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("processed_at IS NULL").
+			Order("outbox_event_id").
+			Limit(d.config.BatchSize).
+			Find(&events).Error; err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+
+		var subscriptions []model.WebhookSubscription
+		if err := tx.Where("active = ?", true).Find(&subscriptions).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, event := range events {
+			for _, sub := range subscriptions {
+				if !sub.Subscribed(event.EventType) {
+					continue
+				}
+				delivery := model.WebhookDelivery{
+					WebhookSubscriptionID: sub.WebhookSubscriptionID,
+					OutboxEventID:         event.OutboxEventID,
+					Status:                model.WebhookDeliveryPending,
+					NextAttemptAt:         now,
+				}
+				if err := tx.Create(&delivery).Error; err != nil {
+					return err
+				}
+			}
+
+			if err := tx.Model(&model.OutboxEvent{}).
+				Where("outbox_event_id = ?", event.OutboxEventID).
+				Update("processed_at", now).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// deliverDue mengklaim delivery yang sudah jatuh tempo lalu mengirimnya
+func (d *Dispatcher) deliverDue(ctx context.Context) error {
+	var deliveries []model.WebhookDelivery
+
+	// Klaim delivery dengan memundurkan next_attempt_at, supaya instance lain tidak mengirim ulang
+	err := d.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", model.WebhookDeliveryPending, time.Now()).
+			Order("next_attempt_at").
+			Limit(d.config.BatchSize).
+			Find(&deliveries).Error; err != nil {
+			return err
+		}
+
+		lease := time.Now().Add(d.config.Timeout * 2)
+		for _, delivery := range deliveries {
+			if err := tx.Model(&model.WebhookDelivery{}).
+				Where("webhook_delivery_id = ?", delivery.WebhookDeliveryID).
+				Update("next_attempt_at", lease).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, delivery := range deliveries {
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err := d.attempt(ctx, delivery); err != nil {
+			log.Printf("webhook: delivery %d failed: %v", delivery.WebhookDeliveryID, err)
+		}
+	}
+	return nil
+}
+
+// attempt mengirim satu delivery dan mencatat hasilnya
+func (d *Dispatcher) attempt(ctx context.Context, delivery model.WebhookDelivery) error {
+	var sub model.WebhookSubscription
+	if err := d.db.First(&sub, delivery.WebhookSubscriptionID).Error; err != nil {
+		return err
+	}
+	var event model.OutboxEvent
+	if err := d.db.First(&event, delivery.OutboxEventID).Error; err != nil {
+		return err
+	}
+
+	statusCode, sendErr := d.send(ctx, sub, event, delivery.WebhookDeliveryID)
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"attempts":         delivery.Attempts + 1,
+		"last_status_code": statusCode,
+		"last_error":       "",
+	}
+	switch {
+	case sendErr == nil:
+		updates["status"] = model.WebhookDeliveryDelivered
+		updates["delivered_at"] = now
+	case delivery.Attempts+1 >= d.config.MaxAttempts:
+		updates["status"] = model.WebhookDeliveryDeadLetter
+		updates["last_error"] = sendErr.Error()
+	default:
+		updates["next_attempt_at"] = now.Add(d.backoff(delivery.Attempts + 1))
+		updates["last_error"] = sendErr.Error()
+	}
+
+	if err := d.db.Model(&model.WebhookDelivery{}).
+		Where("webhook_delivery_id = ?", delivery.WebhookDeliveryID).
+		Updates(updates).Error; err != nil {
+		return err
+	}
+	return sendErr
+}
+
+func (d *Dispatcher) send(ctx context.Context, sub model.WebhookSubscription, event model.OutboxEvent, deliveryID int64) (int, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(event.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEventType, event.EventType)
+	req.Header.Set(HeaderDeliveryID, strconv.FormatInt(deliveryID, 10))
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, "sha256="+Sign(sub.Secret, timestamp, event.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("receiver responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff menghitung jeda retry secara eksponensial: base * 2^(attempts-1), dibatasi MaxBackoff
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	wait := d.config.BaseBackoff
+	for i := 1; i < attempts; i++ {
+		wait *= 2
+		if wait >= d.config.MaxBackoff {
+			return d.config.MaxBackoff
+		}
+	}
+	return wait
+}
+
+// Sign menghasilkan HMAC-SHA256 (hex) dari "<timestamp>.<body>" dengan secret subscription.
+// Penerima memverifikasi dengan menghitung ulang nilai yang sama.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Redeliver mengembalikan delivery (termasuk yang sudah di dead letter) ke antrian
+func Redeliver(db *gorm.DB, deliveryID int64) error {
+	result := db.Model(&model.WebhookDelivery{}).
+		Where("webhook_delivery_id = ?", deliveryID).
+		Updates(map[string]interface{}{
+			"status":          model.WebhookDeliveryPending,
+			"attempts":        0,
+			"next_attempt_at": time.Now(),
+			"last_error":      "",
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,266 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"task-golang-db/model"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// testDB membuat database SQLite in-memory dengan skema tabel webhook. SQLite
+// mengabaikan FOR UPDATE SKIP LOCKED, jadi query dispatcher berjalan apa adanya.
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	for _, ddl := range []string{
+		`CREATE TABLE webhook_subscriptions (
+			webhook_subscription_id INTEGER PRIMARY KEY AUTOINCREMENT,
+			partner TEXT, url TEXT, secret TEXT, event_types TEXT,
+			active BOOLEAN, created_at DATETIME)`,
+		`CREATE TABLE outbox_events (
+			outbox_event_id INTEGER PRIMARY KEY AUTOINCREMENT,
+			event_type TEXT, payload TEXT, created_at DATETIME, processed_at DATETIME)`,
+		`CREATE TABLE webhook_deliveries (
+			webhook_delivery_id INTEGER PRIMARY KEY AUTOINCREMENT,
+			webhook_subscription_id INTEGER, outbox_event_id INTEGER, status TEXT,
+			attempts INTEGER DEFAULT 0, next_attempt_at DATETIME, last_status_code INTEGER,
+			last_error TEXT, delivered_at DATETIME, created_at DATETIME)`,
+	} {
+		if err := db.Exec(ddl).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db
+}
+
+// receiver adalah endpoint partner palsu yang gagal (500) untuk failures request pertama
+type receiver struct {
+	mu       sync.Mutex
+	failures int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, body)
+	if len(r.requests) <= r.failures {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (r *receiver) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.requests)
+}
+
+const testSecret = "whsec_test"
+
+// setup menyiapkan satu subscription ke server dan satu event outbox
+func setup(t *testing.T, db *gorm.DB, url string) {
+	t.Helper()
+	if err := db.Create(&model.WebhookSubscription{
+		Partner:    "partner",
+		URL:        url,
+		Secret:     testSecret,
+		EventTypes: []string{"transfer.completed"},
+		Active:     true,
+	}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&model.OutboxEvent{
+		EventType: "transfer.completed",
+		Payload:   []byte(`{"transaction_id":1,"amount":5000}`),
+	}).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func delivery(t *testing.T, db *gorm.DB) model.WebhookDelivery {
+	t.Helper()
+	var deliveries []model.WebhookDelivery
+	if err := db.Find(&deliveries).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(deliveries) != 1 {
+		t.Fatalf("got %d deliveries, want 1", len(deliveries))
+	}
+	return deliveries[0]
+}
+
+// tick menjalankan satu putaran Run tanpa menunggu ticker
+func tick(t *testing.T, d *Dispatcher) {
+	t.Helper()
+	if err := d.fanOut(); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.deliverDue(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// makeDue memajukan retry supaya putaran berikutnya langsung mengirim ulang
+func makeDue(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	if err := db.Model(&model.WebhookDelivery{}).Where("1 = 1").
+		Update("next_attempt_at", time.Now().Add(-time.Hour)).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDispatcherSignsDelivery(t *testing.T) {
+	db := testDB(t)
+	recv := &receiver{}
+	server := httptest.NewServer(recv)
+	defer server.Close()
+	setup(t, db, server.URL)
+
+	tick(t, NewDispatcher(db, Config{}))
+
+	if recv.count() != 1 {
+		t.Fatalf("receiver got %d requests, want 1", recv.count())
+	}
+	req, body := recv.requests[0], recv.bodies[0]
+	if got := req.Header.Get(HeaderEventType); got != "transfer.completed" {
+		t.Errorf("%s = %q", HeaderEventType, got)
+	}
+	timestamp := req.Header.Get(HeaderTimestamp)
+	mac := hmac.New(sha256.New, []byte(testSecret))
+	mac.Write([]byte(timestamp + "." + string(body)))
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if got := req.Header.Get(HeaderSignature); got != want {
+		t.Errorf("%s = %q, want %q", HeaderSignature, got, want)
+	}
+
+	got := delivery(t, db)
+	if got.Status != model.WebhookDeliveryDelivered || got.Attempts != 1 || got.DeliveredAt == nil {
+		t.Errorf("delivery = %+v, want delivered after 1 attempt", got)
+	}
+}
+
+func TestDispatcherRetriesWithBackoff(t *testing.T) {
+	db := testDB(t)
+	recv := &receiver{failures: 2}
+	server := httptest.NewServer(recv)
+	defer server.Close()
+	setup(t, db, server.URL)
+
+	d := NewDispatcher(db, Config{MaxAttempts: 5, BaseBackoff: time.Minute, MaxBackoff: time.Hour})
+	for attempt := 1; attempt <= 2; attempt++ {
+		before := time.Now()
+		tick(t, d)
+
+		got := delivery(t, db)
+		if got.Status != model.WebhookDeliveryPending || got.Attempts != attempt || got.LastStatusCode != http.StatusInternalServerError {
+			t.Fatalf("attempt %d: delivery = %+v, want pending with status 500", attempt, got)
+		}
+		// backoff eksponensial: 1m lalu 2m
+		wait := time.Minute << (attempt - 1)
+		if got.NextAttemptAt.Before(before.Add(wait)) || got.NextAttemptAt.After(time.Now().Add(wait)) {
+			t.Fatalf("attempt %d: next_attempt_at = %v, want about now+%v", attempt, got.NextAttemptAt, wait)
+		}
+
+		// Belum jatuh tempo: putaran berikutnya tidak mengirim apa pun
+		tick(t, d)
+		if recv.count() != attempt {
+			t.Fatalf("attempt %d: receiver got %d requests before backoff elapsed", attempt, recv.count())
+		}
+		makeDue(t, db)
+	}
+
+	tick(t, d)
+	got := delivery(t, db)
+	if got.Status != model.WebhookDeliveryDelivered || got.Attempts != 3 {
+		t.Errorf("delivery = %+v, want delivered after 3 attempts", got)
+	}
+}
+
+func TestDispatcherDeadLettersAfterMaxAttempts(t *testing.T) {
+	db := testDB(t)
+	recv := &receiver{failures: 100}
+	server := httptest.NewServer(recv)
+	defer server.Close()
+	setup(t, db, server.URL)
+
+	d := NewDispatcher(db, Config{MaxAttempts: 3, BaseBackoff: time.Minute})
+	for i := 0; i < 5; i++ {
+		tick(t, d)
+		makeDue(t, db)
+	}
+
+	if recv.count() != 3 {
+		t.Errorf("receiver got %d requests, want 3", recv.count())
+	}
+	got := delivery(t, db)
+	if got.Status != model.WebhookDeliveryDeadLetter || got.Attempts != 3 {
+		t.Errorf("delivery = %+v, want dead_letter after 3 attempts", got)
+	}
+	if !strings.Contains(got.LastError, "500") {
+		t.Errorf("last_error = %q, want receiver status", got.LastError)
+	}
+
+	// Redeliver mengembalikan delivery dead letter ke antrian
+	recv.failures = 0
+	if err := Redeliver(db, got.WebhookDeliveryID); err != nil {
+		t.Fatal(err)
+	}
+	tick(t, d)
+	if got := delivery(t, db); got.Status != model.WebhookDeliveryDelivered {
+		t.Errorf("after redeliver status = %q, want delivered", got.Status)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	d := NewDispatcher(nil, Config{BaseBackoff: 10 * time.Second, MaxBackoff: time.Minute})
+	for attempts, want := range map[int]time.Duration{
+		1: 10 * time.Second,
+		2: 20 * time.Second,
+		3: 40 * time.Second,
+		4: time.Minute,
+		9: time.Minute,
+	} {
+		if got := d.backoff(attempts); got != want {
+			t.Errorf("backoff(%d) = %v, want %v", attempts, got, want)
+		}
+	}
+}
+
+func TestNewDispatcherDefaults(t *testing.T) {
+	d := NewDispatcher(nil, Config{})
+	if d.config != DefaultConfig {
+		t.Errorf("config = %+v, want DefaultConfig", d.config)
+	}
+	if d.client.Timeout != DefaultConfig.Timeout {
+		t.Errorf("client timeout = %v", d.client.Timeout)
+	}
+}
@@ -0,0 +1,43 @@
+package webhook
+
+import (
+	"encoding/json"
+	"task-golang-db/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Tipe event yang dikirim ke partner
+const (
+	EventTransferCompleted = "transfer.completed"
+	EventTopUpCompleted    = "topup.completed"
+	EventTransferReversed  = "transfer.reversed"
+	EventBatchCompleted    = "batch.completed"
+)
+
+// EventTypes berisi semua tipe event yang bisa di-subscribe
+var EventTypes = []string{
+	EventTransferCompleted,
+	EventTopUpCompleted,
+	EventTransferReversed,
+	EventBatchCompleted,
+}
+
+// Enqueue menulis event ke tabel outbox. Panggil dengan tx yang sama dengan
+// perpindahan saldo supaya event hanya tercatat jika transaksi berhasil commit.
+func Enqueue(tx *gorm.DB, eventType string, data interface{}) error {
+	payload, err := json.Marshal(map[string]interface{}{
+		"type":        eventType,
+		"occurred_at": time.Now().UTC().Format(time.RFC3339),
+		"data":        data,
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Create(&model.OutboxEvent{
+		EventType: eventType,
+		Payload:   payload,
+	}).Error
+}